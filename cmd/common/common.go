@@ -20,11 +20,14 @@ package common
 
 import (
 	"fmt"
+	"github.com/ontio/ontology-crypto/keypair"
+	s "github.com/ontio/ontology-crypto/signature"
 	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/cmd/utils"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/password"
+	"github.com/ontio/ontology/core/types"
 	"github.com/urfave/cli"
 	"strconv"
 )
@@ -173,3 +176,70 @@ func ClearPasswd(passwd []byte) {
 		passwd[i] = 0
 	}
 }
+
+//GetIdentity return the identity of wallet by ONT ID or label, the only identity of wallet if id is empty
+func GetIdentity(wallet account.Client, id string) (*account.Identity, error) {
+	identities := wallet.GetWalletData().Identities
+	if id == "" {
+		if len(identities) != 1 {
+			return nil, fmt.Errorf("wallet has %d identities, select one by ONT ID or label", len(identities))
+		}
+		return &identities[0], nil
+	}
+	for i := range identities {
+		if identities[i].ID == id || identities[i].Label == id {
+			return &identities[i], nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find identity: %s", id)
+}
+
+//GetIdentityController return the identity selected by ctx and its controller, decrypted as an account to sign with
+func GetIdentityController(ctx *cli.Context) (*account.Identity, *account.Account, error) {
+	wallet, err := OpenWallet(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	identity, err := GetIdentity(wallet, ctx.String(utils.GetFlagName(utils.OntIdFlag)))
+	if err != nil {
+		return nil, nil, err
+	}
+	controllerId := ctx.String(utils.GetFlagName(utils.OntIdControllerFlag))
+	var controller *account.Controller
+	for i := range identity.Control {
+		if identity.Control[i].ID == controllerId {
+			controller = &identity.Control[i]
+			break
+		}
+	}
+	if controller == nil {
+		return nil, nil, fmt.Errorf("cannot find controller %s of %s", controllerId, identity.ID)
+	}
+	passwd, err := GetPasswd(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer ClearPasswd(passwd)
+	privateKey, err := keypair.DecryptWithCustomScrypt(&controller.ProtectedKey, passwd, wallet.GetWalletData().Scrypt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt controller %s of %s error: %s", controllerId, identity.ID, err)
+	}
+	publicKey := privateKey.Public()
+	return identity, &account.Account{
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+		Address:    types.AddressFromPubKey(publicKey),
+		SigScheme:  defaultSigScheme(keypair.GetKeyType(publicKey)),
+	}, nil
+}
+
+func defaultSigScheme(keyType keypair.KeyType) s.SignatureScheme {
+	switch keyType {
+	case keypair.PK_SM2:
+		return s.SM3withSM2
+	case keypair.PK_EDDSA:
+		return s.SHA512withEDDSA
+	default:
+		return s.SHA256withECDSA
+	}
+}
@@ -128,6 +128,7 @@ func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.GasLimit = ctx.Uint64(utils.GetFlagName(utils.GasLimitFlag))
 	cfg.GasPrice = ctx.Uint64(utils.GetFlagName(utils.GasPriceFlag))
 	cfg.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+	cfg.OntFsHistoryBlocks = uint32(ctx.Uint(utils.GetFlagName(utils.OntFsHistoryBlocksFlag)))
 }
 
 func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
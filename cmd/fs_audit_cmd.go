@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/ledger"
+	httpcom "github.com/ontio/ontology/http/base/common"
+	"github.com/ontio/ontology/smartcontract/states"
+	"github.com/urfave/cli"
+)
+
+var FsAuditCommand = cli.Command{
+	Name:      "fsaudit",
+	Usage:     "Audit the books of the ontfs contract in DB",
+	ArgsUsage: "",
+	Action:    fsAudit,
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+		utils.ConfigFlag,
+		utils.NetworkIdFlag,
+		utils.OntFsHistoryBlocksFlag,
+		utils.FsAuditHeightFlag,
+	},
+	Description: "Sum every liability held by the ontfs contract, compare it with the contract's ONG balance, " +
+		"check node and space volumes against the live records, and report each mismatch. " +
+		"The ledger DB is opened directly, so stop the node first. " +
+		"A past height can only be audited within the --ontfs-history-blocks window the node kept.",
+}
+
+func fsAudit(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+
+	cfg, err := SetOntologyConfig(ctx)
+	if err != nil {
+		PrintErrorMsg("SetOntologyConfig error:%s", err)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
+
+	stateHashHeight := config.GetStateHashCheckHeight(cfg.P2PNode.NetworkId)
+	ledger.DefLedger, err = ledger.NewLedger(dbDir, stateHashHeight)
+	if err != nil {
+		return fmt.Errorf("NewLedger error:%s", err)
+	}
+	defer ledger.DefLedger.Close()
+	bookKeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, config.DefConfig.Genesis)
+	if err != nil {
+		return fmt.Errorf("BuildGenesisBlock error %s", err)
+	}
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		return fmt.Errorf("init ledger error:%s", err)
+	}
+
+	tx, err := httpcom.NewFsAuditTransaction()
+	if err != nil {
+		return fmt.Errorf("NewFsAuditTransaction error:%s", err)
+	}
+	height := ledger.DefLedger.GetCurrentBlockHeight()
+	var result *states.PreExecResult
+	if ctx.IsSet(utils.GetFlagName(utils.FsAuditHeightFlag)) {
+		height = uint32(ctx.Uint(utils.GetFlagName(utils.FsAuditHeightFlag)))
+		result, err = ledger.DefLedger.PreExecuteContractAtHeight(tx, height)
+	} else {
+		result, err = ledger.DefLedger.PreExecuteContract(tx)
+	}
+	if err != nil {
+		return fmt.Errorf("PreExecuteContract error:%s", err)
+	}
+	report, err := httpcom.ParseFsAuditResult(result)
+	if err != nil {
+		return fmt.Errorf("ParseFsAuditResult error:%s", err)
+	}
+
+	PrintInfoMsg("Audit at block height:%d", height)
+	PrintJsonObject(report)
+	if len(report.Mismatches) != 0 {
+		return fmt.Errorf("ontfs audit found %d mismatches", len(report.Mismatches))
+	}
+	PrintInfoMsg("Ontfs books balance.")
+	return nil
+}
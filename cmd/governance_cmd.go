@@ -0,0 +1,625 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/ontio/ontology/account"
+	cmdcom "github.com/ontio/ontology/cmd/common"
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/service/native/governance"
+	"github.com/urfave/cli"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var GovernanceCommand = cli.Command{
+	Name:  "governance",
+	Usage: "Handle staking, candidacy and rewards of the governance contract",
+	Description: "Governance commands register and manage consensus candidates, authorize ONT to peers and withdraw it, " +
+		"withdraw ONG rewards, and show the peer pool, stakes and split fees of the governance contract.",
+	Subcommands: []cli.Command{
+		{
+			Action:      registerCandidate,
+			Name:        "register",
+			Usage:       "Register the account as a consensus candidate",
+			ArgsUsage:   " ",
+			Description: "Register a candidate peer with --pos ONT of init pos. The registration also costs the candidate fee in ONG.",
+			Flags: governanceTxFlags(
+				utils.GovernancePeerPubkeyFlag,
+				utils.GovernancePosFlag,
+				utils.GovernanceOntIdFlag,
+				utils.GovernanceKeyNoFlag,
+			),
+		},
+		{
+			Action:    unRegisterCandidate,
+			Name:      "unregister",
+			Usage:     "Cancel the registration of a candidate not approved yet",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(utils.GovernancePeerPubkeyFlag),
+		},
+		{
+			Action:    quitNode,
+			Name:      "quitnode",
+			Usage:     "Quit a consensus or candidate node",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(utils.GovernancePeerPubkeyFlag),
+		},
+		{
+			Action:      authorizeForPeer,
+			Name:        "authorize",
+			Usage:       "Authorize ONT to peers",
+			ArgsUsage:   " ",
+			Description: "Authorize --pos ONT to each peer of --peer-pubkey, the stake is counted from the next view.",
+			Flags:       governanceTxFlags(utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag),
+		},
+		{
+			Action:      unAuthorizeForPeer,
+			Name:        "unauthorize",
+			Usage:       "Cancel ONT authorized to peers",
+			ArgsUsage:   " ",
+			Description: "Cancel --pos ONT authorized to each peer of --peer-pubkey, the ONT can be withdrawn once unfrozen.",
+			Flags:       governanceTxFlags(utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag),
+		},
+		{
+			Action:    withdraw,
+			Name:      "withdraw",
+			Usage:     "Withdraw unfrozen ONT from peers",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag),
+		},
+		{
+			Action:    withdrawGovernanceOng,
+			Name:      "withdrawong",
+			Usage:     "Withdraw the ONG unbound by the ONT staked in the governance contract",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(),
+		},
+		{
+			Action:    withdrawFee,
+			Name:      "withdrawfee",
+			Usage:     "Withdraw the ONG rewards split to the account",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(),
+		},
+		{
+			Action:    setPeerCost,
+			Name:      "setpeercost",
+			Usage:     "Set the percent of the peer rewards the node keeps",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(utils.GovernancePeerPubkeyFlag, utils.GovernancePeerCostFlag),
+		},
+		{
+			Action:    changeMaxAuthorization,
+			Name:      "changemaxauth",
+			Usage:     "Change the max ONT a peer accepts from stakers",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(utils.GovernancePeerPubkeyFlag, utils.GovernanceMaxAuthorizeFlag),
+		},
+		{
+			Action:    addInitPos,
+			Name:      "addinitpos",
+			Usage:     "Add ONT to the init pos of a peer",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag),
+		},
+		{
+			Action:    reduceInitPos,
+			Name:      "reduceinitpos",
+			Usage:     "Reduce the init pos of a peer",
+			ArgsUsage: " ",
+			Flags:     governanceTxFlags(utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag),
+		},
+		{
+			Action:    showPeerPool,
+			Name:      "peerpool",
+			Usage:     "Show the peers of the current view",
+			ArgsUsage: " ",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
+		{
+			Action:    showGovernanceView,
+			Name:      "view",
+			Usage:     "Show the current governance view",
+			ArgsUsage: " ",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
+		{
+			Action:    showTotalStake,
+			Name:      "stake",
+			Usage:     "Show the total ONT an account staked in the governance contract",
+			ArgsUsage: "<address|label|index>",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.WalletFileFlag,
+			},
+		},
+		{
+			Action:      showSplitFee,
+			Name:        "splitfee",
+			Usage:       "Show the ONG fee to split, and the rewards split to an account",
+			ArgsUsage:   "[<address|label|index>]",
+			Description: "Show the ONG fee the governance contract splits to peers and stakers, with the rewards not withdrawn by the account if given.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.WalletFileFlag,
+			},
+		},
+		{
+			Action:      showAuthorization,
+			Name:        "authorization",
+			Usage:       "Show the ONT an account authorized to peers",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Show the authorization of the account to each peer of --peer-pubkey, or to every peer of the current view.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.WalletFileFlag,
+				utils.GovernancePeerPubkeyFlag,
+			},
+		},
+	},
+}
+
+// governanceTxFlags returns the flags to sign and send a governance transaction, followed by flags
+func governanceTxFlags(flags ...cli.Flag) []cli.Flag {
+	return append([]cli.Flag{
+		utils.RPCPortFlag,
+		utils.TransactionGasPriceFlag,
+		utils.TransactionGasLimitFlag,
+		utils.AccountAddressFlag,
+		utils.WalletFileFlag,
+	}, flags...)
+}
+
+func registerCandidate(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	peerPubkey, err := getPeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	posList, err := getPosList(ctx, 1)
+	if err != nil {
+		return err
+	}
+	ontId := ctx.String(utils.GetFlagName(utils.GovernanceOntIdFlag))
+	if ontId == "" {
+		PrintErrorMsg("Missing --%s flag.", utils.GetFlagName(utils.GovernanceOntIdFlag))
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.REGISTER_CANDIDATE, &governance.RegisterCandidateParam{
+		PeerPubkey: peerPubkey,
+		Address:    signer.Address,
+		InitPos:    posList[0],
+		Caller:     []byte(ontId),
+		KeyNo:      uint32(ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag))),
+	})
+}
+
+func unRegisterCandidate(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	peerPubkey, err := getPeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.UNREGISTER_CANDIDATE, &governance.UnRegisterCandidateParam{
+		PeerPubkey: peerPubkey,
+		Address:    signer.Address,
+	})
+}
+
+func quitNode(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	peerPubkey, err := getPeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.QUIT_NODE, &governance.QuitNodeParam{
+		PeerPubkey: peerPubkey,
+		Address:    signer.Address,
+	})
+}
+
+func authorizeForPeer(ctx *cli.Context) error {
+	return changeAuthorization(ctx, governance.AUTHORIZE_FOR_PEER)
+}
+
+func unAuthorizeForPeer(ctx *cli.Context) error {
+	return changeAuthorization(ctx, governance.UNAUTHORIZE_FOR_PEER)
+}
+
+func changeAuthorization(ctx *cli.Context, method string) error {
+	SetRpcPort(ctx)
+	peerPubkeys, err := getPeerPubkeys(ctx)
+	if err != nil {
+		return err
+	}
+	posList, err := getPosList(ctx, len(peerPubkeys))
+	if err != nil {
+		return err
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, method, &governance.AuthorizeForPeerParam{
+		Address:        signer.Address,
+		PeerPubkeyList: peerPubkeys,
+		PosList:        posList,
+	})
+}
+
+func withdraw(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	peerPubkeys, err := getPeerPubkeys(ctx)
+	if err != nil {
+		return err
+	}
+	posList, err := getPosList(ctx, len(peerPubkeys))
+	if err != nil {
+		return err
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.WITHDRAW, &governance.WithdrawParam{
+		Address:        signer.Address,
+		PeerPubkeyList: peerPubkeys,
+		WithdrawList:   posList,
+	})
+}
+
+func withdrawGovernanceOng(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.WITHDRAW_ONG, &governance.WithdrawOngParam{
+		Address: signer.Address,
+	})
+}
+
+func withdrawFee(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.WITHDRAW_FEE, &governance.WithdrawFeeParam{
+		Address: signer.Address,
+	})
+}
+
+func setPeerCost(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	peerPubkey, err := getPeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	peerCost := ctx.Uint(utils.GetFlagName(utils.GovernancePeerCostFlag))
+	if peerCost > 100 {
+		return fmt.Errorf("peer cost should be between 0 and 100")
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.SET_PEER_COST, &governance.SetPeerCostParam{
+		PeerPubkey: peerPubkey,
+		Address:    signer.Address,
+		PeerCost:   uint32(peerCost),
+	})
+}
+
+func changeMaxAuthorization(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	peerPubkey, err := getPeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	maxAuthorize := ctx.Uint(utils.GetFlagName(utils.GovernanceMaxAuthorizeFlag))
+	if maxAuthorize > math.MaxUint32 {
+		return fmt.Errorf("max authorize should be less than %d", uint32(math.MaxUint32))
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, governance.CHANGE_MAX_AUTHORIZATION, &governance.ChangeMaxAuthorizationParam{
+		PeerPubkey:   peerPubkey,
+		Address:      signer.Address,
+		MaxAuthorize: uint32(maxAuthorize),
+	})
+}
+
+func addInitPos(ctx *cli.Context) error {
+	return changeInitPos(ctx, governance.ADD_INIT_POS)
+}
+
+func reduceInitPos(ctx *cli.Context) error {
+	return changeInitPos(ctx, governance.REDUCE_INIT_POS)
+}
+
+func changeInitPos(ctx *cli.Context, method string) error {
+	SetRpcPort(ctx)
+	peerPubkey, err := getPeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	posList, err := getPosList(ctx, 1)
+	if err != nil {
+		return err
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, signer, method, &governance.ChangeInitPosParam{
+		PeerPubkey: peerPubkey,
+		Address:    signer.Address,
+		Pos:        posList[0],
+	})
+}
+
+func invokeGovernance(ctx *cli.Context, signer *account.Account, method string, param interface{}) error {
+	gasPrice := ctx.Uint64(utils.TransactionGasPriceFlag.Name)
+	gasLimit := ctx.Uint64(utils.TransactionGasLimitFlag.Name)
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+
+	txHash, err := utils.InvokeGovernance(gasPrice, gasLimit, signer, method, param)
+	if err != nil {
+		return fmt.Errorf("invoke governance %s error:%s", method, err)
+	}
+	PrintInfoMsg("Governance %s:", method)
+	PrintInfoMsg("  Account:%s", signer.Address.ToBase58())
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './ontology info status %s' to query transaction status.", txHash)
+	return nil
+}
+
+func getPeerPubkeys(ctx *cli.Context) ([]string, error) {
+	flag := utils.GetFlagName(utils.GovernancePeerPubkeyFlag)
+	value := strings.TrimSpace(ctx.String(flag))
+	if value == "" {
+		return nil, fmt.Errorf("missing --%s flag", flag)
+	}
+	peerPubkeys := strings.Split(value, ",")
+	for i, peerPubkey := range peerPubkeys {
+		peerPubkeys[i] = strings.TrimSpace(peerPubkey)
+		if peerPubkeys[i] == "" {
+			return nil, fmt.Errorf("empty peer pubkey in --%s", flag)
+		}
+	}
+	return peerPubkeys, nil
+}
+
+func getPeerPubkey(ctx *cli.Context) (string, error) {
+	peerPubkeys, err := getPeerPubkeys(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(peerPubkeys) != 1 {
+		return "", fmt.Errorf("only one peer pubkey is allowed")
+	}
+	return peerPubkeys[0], nil
+}
+
+// getPosList returns the ONT amounts of --pos, one for each of count peers
+func getPosList(ctx *cli.Context, count int) ([]uint32, error) {
+	flag := utils.GetFlagName(utils.GovernancePosFlag)
+	value := strings.TrimSpace(ctx.String(flag))
+	if value == "" {
+		return nil, fmt.Errorf("missing --%s flag", flag)
+	}
+	items := strings.Split(value, ",")
+	if len(items) != count {
+		return nil, fmt.Errorf("--%s should have %d amounts, one for each peer", flag, count)
+	}
+	posList := make([]uint32, 0, count)
+	for _, item := range items {
+		pos, err := strconv.ParseUint(strings.TrimSpace(item), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pos %s:%s", item, err)
+		}
+		if pos == 0 {
+			return nil, fmt.Errorf("pos should be greater than 0")
+		}
+		posList = append(posList, uint32(pos))
+	}
+	return posList, nil
+}
+
+func peerStatusName(status governance.Status) string {
+	switch status {
+	case governance.RegisterCandidateStatus:
+		return "registered"
+	case governance.CandidateStatus:
+		return "candidate"
+	case governance.ConsensusStatus:
+		return "consensus"
+	case governance.QuitConsensusStatus:
+		return "quit consensus"
+	case governance.QuitingStatus:
+		return "quitting"
+	case governance.BlackStatus:
+		return "blacklisted"
+	default:
+		return fmt.Sprintf("unknown(%d)", status)
+	}
+}
+
+func showPeerPool(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	governanceView, err := utils.GetGovernanceView()
+	if err != nil {
+		return err
+	}
+	peers, err := utils.GetPeerPool(governanceView.View)
+	if err != nil {
+		return err
+	}
+	PrintInfoMsg("Peer pool of view %d:", governanceView.View)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tPEER PUBKEY\tOWNER\tSTATUS\tINIT POS\tTOTAL POS")
+	for _, peer := range peers {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\n", peer.Index, peer.PeerPubkey, peer.Address.ToBase58(),
+			peerStatusName(peer.Status), peer.InitPos, peer.TotalPos)
+	}
+	return w.Flush()
+}
+
+func showGovernanceView(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	governanceView, err := utils.GetGovernanceView()
+	if err != nil {
+		return err
+	}
+	PrintInfoMsg("Governance view:")
+	PrintInfoMsg("  View:%d", governanceView.View)
+	PrintInfoMsg("  Height:%d", governanceView.Height)
+	PrintInfoMsg("  TxHash:%s", governanceView.TxHash.ToHexString())
+	return nil
+}
+
+func showTotalStake(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	address, err := getGovernanceAddressArg(ctx)
+	if err != nil {
+		return err
+	}
+	totalStake, err := utils.GetTotalStake(address)
+	if err != nil {
+		return err
+	}
+	PrintInfoMsg("Total stake:")
+	PrintInfoMsg("  Account:%s", address.ToBase58())
+	PrintInfoMsg("  ONT:%d", totalStake.Stake)
+	PrintInfoMsg("  TimeOffset:%d", totalStake.TimeOffset)
+	return nil
+}
+
+func showSplitFee(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	splitFee, err := utils.GetSplitFee()
+	if err != nil {
+		return err
+	}
+	PrintInfoMsg("Split fee:")
+	PrintInfoMsg("  ONG:%s", utils.FormatOng(splitFee))
+	if ctx.NArg() < 1 {
+		return nil
+	}
+	address, err := getGovernanceAddressArg(ctx)
+	if err != nil {
+		return err
+	}
+	splitFeeAddress, err := utils.GetSplitFeeAddress(address)
+	if err != nil {
+		return err
+	}
+	PrintInfoMsg("  Account:%s", address.ToBase58())
+	PrintInfoMsg("  Account ONG:%s", utils.FormatOng(splitFeeAddress.Amount))
+	return nil
+}
+
+func showAuthorization(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	address, err := getGovernanceAddressArg(ctx)
+	if err != nil {
+		return err
+	}
+	var peerPubkeys []string
+	if ctx.IsSet(utils.GetFlagName(utils.GovernancePeerPubkeyFlag)) {
+		peerPubkeys, err = getPeerPubkeys(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		governanceView, err := utils.GetGovernanceView()
+		if err != nil {
+			return err
+		}
+		peers, err := utils.GetPeerPool(governanceView.View)
+		if err != nil {
+			return err
+		}
+		for _, peer := range peers {
+			peerPubkeys = append(peerPubkeys, peer.PeerPubkey)
+		}
+	}
+
+	PrintInfoMsg("Authorization of %s:", address.ToBase58())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PEER PUBKEY\tCONSENSUS POS\tCANDIDATE POS\tNEW POS\tWITHDRAW CONSENSUS\tWITHDRAW CANDIDATE\tWITHDRAW UNFREEZE")
+	for _, peerPubkey := range peerPubkeys {
+		authorizeInfo, err := utils.GetAuthorizeInfo(peerPubkey, address)
+		if err != nil {
+			return err
+		}
+		if authorizeInfo == nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", peerPubkey, authorizeInfo.ConsensusPos,
+			authorizeInfo.CandidatePos, authorizeInfo.NewPos, authorizeInfo.WithdrawConsensusPos,
+			authorizeInfo.WithdrawCandidatePos, authorizeInfo.WithdrawUnfreezePos)
+	}
+	return w.Flush()
+}
+
+func getGovernanceAddressArg(ctx *cli.Context) (common.Address, error) {
+	if ctx.NArg() < 1 {
+		return common.ADDRESS_EMPTY, fmt.Errorf("missing account argument")
+	}
+	accAddr, err := cmdcom.ParseAddress(ctx.Args().First(), ctx)
+	if err != nil {
+		return common.ADDRESS_EMPTY, err
+	}
+	return common.AddressFromBase58(accAddr)
+}
@@ -0,0 +1,350 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	cmdcom "github.com/ontio/ontology/cmd/common"
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/urfave/cli"
+)
+
+var OntIdCommand = cli.Command{
+	Name:  "ontid",
+	Usage: "Register and manage ONT ID on chain",
+	Description: "ONT ID commands register the identities of wallet on chain, manage their public keys, recovery and attributes, " +
+		"signing with an identity controller of wallet, and show the description object of an ONT ID.",
+	Subcommands: []cli.Command{
+		{
+			Action:      ontIdRegister,
+			Name:        "register",
+			Usage:       "Register an ONT ID of wallet with the public key of its controller",
+			ArgsUsage:   " ",
+			Description: "Register the ONT ID with the public key of --controller, and the attributes of --attr if any.",
+			Flags:       ontIdTxFlags(utils.OntIdAttributeFlag),
+		},
+		{
+			Action:    ontIdAddKey,
+			Name:      "addkey",
+			Usage:     "Add a public key to an ONT ID",
+			ArgsUsage: " ",
+			Flags:     ontIdTxFlags(utils.OntIdPubkeyFlag),
+		},
+		{
+			Action:    ontIdRemoveKey,
+			Name:      "removekey",
+			Usage:     "Revoke a public key of an ONT ID",
+			ArgsUsage: " ",
+			Flags:     ontIdTxFlags(utils.OntIdPubkeyFlag),
+		},
+		{
+			Action:    ontIdAddRecovery,
+			Name:      "addrecovery",
+			Usage:     "Set the recovery address of an ONT ID",
+			ArgsUsage: " ",
+			Flags:     ontIdTxFlags(utils.OntIdRecoveryFlag),
+		},
+		{
+			Action:      ontIdChangeRecovery,
+			Name:        "changerecovery",
+			Usage:       "Change the recovery address of an ONT ID",
+			ArgsUsage:   " ",
+			Description: "Change the recovery of the ONT ID to --recovery, signing with the current recovery account of wallet.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.TransactionGasPriceFlag,
+				utils.TransactionGasLimitFlag,
+				utils.WalletFileFlag,
+				utils.AccountAddressFlag,
+				utils.OntIdFlag,
+				utils.OntIdRecoveryFlag,
+			},
+		},
+		{
+			Action:    ontIdAddAttributes,
+			Name:      "addattr",
+			Usage:     "Add or update attributes of an ONT ID",
+			ArgsUsage: " ",
+			Flags:     ontIdTxFlags(utils.OntIdAttributeFlag),
+		},
+		{
+			Action:    ontIdRemoveAttribute,
+			Name:      "removeattr",
+			Usage:     "Remove an attribute of an ONT ID",
+			ArgsUsage: " ",
+			Flags:     ontIdTxFlags(utils.OntIdAttributePathFlag),
+		},
+		{
+			Action:      ontIdVerifySignature,
+			Name:        "verify",
+			Usage:       "Verify the controller signs for a public key of an ONT ID",
+			ArgsUsage:   " ",
+			Description: "Pre-execute verifySignature signed by --controller, to check it holds the key --keyno of the ONT ID.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.WalletFileFlag,
+				utils.OntIdFlag,
+				utils.OntIdControllerFlag,
+				utils.OntIdKeyNoFlag,
+			},
+		},
+		{
+			Action:    ontIdShowDDO,
+			Name:      "ddo",
+			Usage:     "Show the public keys, attributes and recovery of an ONT ID",
+			ArgsUsage: "<ontid>",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
+	},
+}
+
+// ontIdTxFlags returns the flags to sign an ONT ID transaction with a controller, followed by flags
+func ontIdTxFlags(flags ...cli.Flag) []cli.Flag {
+	return append([]cli.Flag{
+		utils.RPCPortFlag,
+		utils.TransactionGasPriceFlag,
+		utils.TransactionGasLimitFlag,
+		utils.WalletFileFlag,
+		utils.OntIdFlag,
+		utils.OntIdControllerFlag,
+	}, flags...)
+}
+
+func ontIdRegister(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	identity, controller, err := cmdcom.GetIdentityController(ctx)
+	if err != nil {
+		return err
+	}
+	attributes, err := getOntIdAttributes(ctx, false)
+	if err != nil {
+		return err
+	}
+	pubKey := keypair.SerializePublicKey(controller.PublicKey)
+	if len(attributes) == 0 {
+		return invokeOntId(ctx, controller, identity.ID, utils.ONTID_REG_ID_WITH_PUBLIC_KEY,
+			[]interface{}{[]byte(identity.ID), pubKey})
+	}
+	return invokeOntId(ctx, controller, identity.ID, utils.ONTID_REG_ID_WITH_ATTRIBUTES,
+		[]interface{}{[]byte(identity.ID), pubKey, attributes})
+}
+
+func ontIdAddKey(ctx *cli.Context) error {
+	return ontIdChangeKey(ctx, utils.ONTID_ADD_KEY)
+}
+
+func ontIdRemoveKey(ctx *cli.Context) error {
+	return ontIdChangeKey(ctx, utils.ONTID_REMOVE_KEY)
+}
+
+func ontIdChangeKey(ctx *cli.Context, method string) error {
+	SetRpcPort(ctx)
+	flag := utils.GetFlagName(utils.OntIdPubkeyFlag)
+	pubKey, err := hex.DecodeString(ctx.String(flag))
+	if err != nil || len(pubKey) == 0 {
+		return fmt.Errorf("invalid --%s flag", flag)
+	}
+	if _, err = keypair.DeserializePublicKey(pubKey); err != nil {
+		return fmt.Errorf("invalid public key: %s", err)
+	}
+	identity, controller, err := cmdcom.GetIdentityController(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeOntId(ctx, controller, identity.ID, method,
+		[]interface{}{[]byte(identity.ID), pubKey, keypair.SerializePublicKey(controller.PublicKey)})
+}
+
+func ontIdAddRecovery(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	recovery, err := getOntIdRecovery(ctx)
+	if err != nil {
+		return err
+	}
+	identity, controller, err := cmdcom.GetIdentityController(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeOntId(ctx, controller, identity.ID, utils.ONTID_ADD_RECOVERY,
+		[]interface{}{[]byte(identity.ID), recovery, keypair.SerializePublicKey(controller.PublicKey)})
+}
+
+func ontIdChangeRecovery(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	newRecovery, err := getOntIdRecovery(ctx)
+	if err != nil {
+		return err
+	}
+	id := ctx.String(utils.GetFlagName(utils.OntIdFlag))
+	wallet, err := cmdcom.OpenWallet(ctx)
+	if err != nil {
+		return err
+	}
+	//the recovery account may not hold the identity in its wallet
+	if identity, err := cmdcom.GetIdentity(wallet, id); err == nil {
+		id = identity.ID
+	} else if !account.VerifyID(id) {
+		return err
+	}
+	oldRecovery, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeOntId(ctx, oldRecovery, id, utils.ONTID_CHANGE_RECOVERY,
+		[]interface{}{[]byte(id), newRecovery, oldRecovery.Address})
+}
+
+func ontIdAddAttributes(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	attributes, err := getOntIdAttributes(ctx, true)
+	if err != nil {
+		return err
+	}
+	identity, controller, err := cmdcom.GetIdentityController(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeOntId(ctx, controller, identity.ID, utils.ONTID_ADD_ATTRIBUTES,
+		[]interface{}{[]byte(identity.ID), attributes, keypair.SerializePublicKey(controller.PublicKey)})
+}
+
+func ontIdRemoveAttribute(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	flag := utils.GetFlagName(utils.OntIdAttributePathFlag)
+	path := ctx.String(flag)
+	if path == "" {
+		return fmt.Errorf("missing --%s flag", flag)
+	}
+	identity, controller, err := cmdcom.GetIdentityController(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeOntId(ctx, controller, identity.ID, utils.ONTID_REMOVE_ATTRIBUTE,
+		[]interface{}{[]byte(identity.ID), []byte(path), keypair.SerializePublicKey(controller.PublicKey)})
+}
+
+func ontIdVerifySignature(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	identity, controller, err := cmdcom.GetIdentityController(ctx)
+	if err != nil {
+		return err
+	}
+	keyNo := ctx.Uint64(utils.GetFlagName(utils.OntIdKeyNoFlag))
+	preResult, err := utils.PrepareInvokeOntId(controller, utils.ONTID_VERIFY_SIGNATURE,
+		[]interface{}{[]byte(identity.ID), keyNo})
+	if err != nil {
+		return fmt.Errorf("verify signature error:%s", err)
+	}
+	if preResult.State == 0 {
+		PrintInfoMsg("Controller %s is NOT the key #%d of %s.",
+			ctx.String(utils.GetFlagName(utils.OntIdControllerFlag)), keyNo, identity.ID)
+		return nil
+	}
+	PrintInfoMsg("Controller %s is the key #%d of %s.",
+		ctx.String(utils.GetFlagName(utils.OntIdControllerFlag)), keyNo, identity.ID)
+	return nil
+}
+
+func ontIdShowDDO(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing ontid argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	id := ctx.Args().First()
+	if !account.VerifyID(id) {
+		return fmt.Errorf("invalid ONT ID: %s", id)
+	}
+	ddo, err := utils.GetDDO(id)
+	if err != nil {
+		return fmt.Errorf("get DDO error:%s", err)
+	}
+	if ddo == nil {
+		return fmt.Errorf("%s is not registered", id)
+	}
+	data, err := json.MarshalIndent(ddo, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent error:%s", err)
+	}
+	PrintInfoMsg("%s", data)
+	return nil
+}
+
+func invokeOntId(ctx *cli.Context, signer *account.Account, id string, method string, params []interface{}) error {
+	gasPrice := ctx.Uint64(utils.TransactionGasPriceFlag.Name)
+	gasLimit := ctx.Uint64(utils.TransactionGasLimitFlag.Name)
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+
+	txHash, err := utils.InvokeOntId(gasPrice, gasLimit, signer, method, params)
+	if err != nil {
+		return fmt.Errorf("invoke ONT ID %s error:%s", method, err)
+	}
+	PrintInfoMsg("ONT ID %s:", method)
+	PrintInfoMsg("  OntId:%s", id)
+	PrintInfoMsg("  Signer:%s", signer.Address.ToBase58())
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './ontology info status %s' to query transaction status.", txHash)
+	return nil
+}
+
+func getOntIdRecovery(ctx *cli.Context) (common.Address, error) {
+	flag := utils.GetFlagName(utils.OntIdRecoveryFlag)
+	value := ctx.String(flag)
+	if value == "" {
+		return common.ADDRESS_EMPTY, fmt.Errorf("missing --%s flag", flag)
+	}
+	recovery, err := common.AddressFromBase58(value)
+	if err != nil {
+		return common.ADDRESS_EMPTY, fmt.Errorf("invalid recovery address %s:%s", value, err)
+	}
+	return recovery, nil
+}
+
+func getOntIdAttributes(ctx *cli.Context, required bool) ([]*utils.OntIdAttribute, error) {
+	flag := utils.GetFlagName(utils.OntIdAttributeFlag)
+	values := ctx.StringSlice(flag)
+	if required && len(values) == 0 {
+		return nil, fmt.Errorf("missing --%s flag", flag)
+	}
+	attributes := make([]*utils.OntIdAttribute, 0, len(values))
+	for _, value := range values {
+		attribute, err := utils.ParseOntIdAttribute(value)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes, nil
+}
@@ -30,4 +30,7 @@ func init() {
 	DefCliRpcSvr.RegHandler("signeovminvoketx", handlers.SigNeoVMInvokeTx)
 	DefCliRpcSvr.RegHandler("signeovminvokeabitx", handlers.SigNeoVMInvokeAbiTx)
 	DefCliRpcSvr.RegHandler("signativeinvoketx", handlers.SigNativeInvokeTx)
+	DefCliRpcSvr.RegHandler("sigontfstx", handlers.SigOntFsTx)
+	DefCliRpcSvr.RegHandler("sigontfspassport", handlers.SigOntFsPassport)
+	DefCliRpcSvr.RegHandler("sigontfssettleslice", handlers.SigOntFsSettleSlice)
 }
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ontio/ontology-crypto/keypair"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	cliutil "github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+)
+
+// SigOntFsPassportReq is the passport authorizing the query of Method with Param. The signer queries
+// its own data, or the data of WalletAddr as a key of the ONT ID Delegate
+type SigOntFsPassportReq struct {
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"` //hex
+	Method      string `json:"method"`
+	Param       string `json:"param"` //hex
+	WalletAddr  string `json:"wallet_addr"`
+	Delegate    string `json:"delegate"`
+}
+
+type SigOntFsPassportRsp struct {
+	Passport string `json:"passport"`
+}
+
+func SigOntFsPassport(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigOntFsPassportReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsPassport json.Unmarshal SigOntFsPassportReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	blockHash, err := hex.DecodeString(rawReq.BlockHash)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsPassport hex.DecodeString error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	param, err := hex.DecodeString(rawReq.Param)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsPassport hex.DecodeString error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsPassport GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	walletAddr := signer.Address
+	if rawReq.WalletAddr != "" {
+		walletAddr, err = common.AddressFromBase58(rawReq.WalletAddr)
+		if err != nil {
+			log.Infof("Cli Qid:%s SigOntFsPassport AddressFromBase58 error:%s", req.Qid, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+	}
+	if walletAddr != signer.Address && rawReq.Delegate == "" {
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		resp.ErrorInfo = "delegate is required for the passport of another wallet"
+		return
+	}
+	passport := &ontfs.Passport{
+		BlockHeight: rawReq.BlockHeight,
+		BlockHash:   blockHash,
+		WalletAddr:  walletAddr,
+		Method:      []byte(rawReq.Method),
+		ParamDigest: ontfs.PassportParamDigest(param),
+		Delegate:    []byte(rawReq.Delegate),
+		PublicKey:   keypair.SerializePublicKey(signer.PublicKey),
+	}
+	passport.Signature, err = cliutil.Sign(passport.SignData(), signer)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsPassport Sign error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	passport.Serialization(sink)
+	resp.Result = &SigOntFsPassportRsp{
+		Passport: hex.EncodeToString(sink.Bytes()),
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ontio/ontology-crypto/signature"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+	"testing"
+)
+
+func TestSigOntFsPassport(t *testing.T) {
+	defAcc, err := testWallet.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Errorf("GetDefaultAccount error:%s", err)
+		return
+	}
+	sigReq := &SigOntFsPassportReq{
+		BlockHeight: 100,
+		BlockHash:   hex.EncodeToString(make([]byte, 32)),
+		Method:      ontfs.FS_GET_KEY_ENVELOPE,
+		Param:       hex.EncodeToString([]byte("QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o")),
+	}
+	data, err := json.Marshal(sigReq)
+	if err != nil {
+		t.Errorf("json.Marshal SigOntFsPassportReq error:%s", err)
+		return
+	}
+	req := &clisvrcom.CliRpcRequest{
+		Qid:     "t",
+		Method:  "sigontfspassport",
+		Params:  data,
+		Account: defAcc.Address.ToBase58(),
+		Pwd:     string(pwd),
+	}
+	rsp := &clisvrcom.CliRpcResponse{}
+	SigOntFsPassport(req, rsp)
+	if rsp.ErrorCode != 0 {
+		t.Errorf("SigOntFsPassport failed. ErrorCode:%d", rsp.ErrorCode)
+		return
+	}
+	raw, err := hex.DecodeString(rsp.Result.(*SigOntFsPassportRsp).Passport)
+	if err != nil {
+		t.Errorf("hex.DecodeString error:%s", err)
+		return
+	}
+	var passport ontfs.Passport
+	if err = passport.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		t.Errorf("Passport Deserialization error:%s", err)
+		return
+	}
+	if passport.WalletAddr != defAcc.Address || string(passport.Method) != ontfs.FS_GET_KEY_ENVELOPE {
+		t.Errorf("SigOntFsPassport WalletAddr or Method mismatch")
+		return
+	}
+	sig, err := signature.Deserialize(passport.Signature)
+	if err != nil {
+		t.Errorf("signature.Deserialize error:%s", err)
+		return
+	}
+	if !signature.Verify(defAcc.PublicKey, passport.SignData(), sig) {
+		t.Errorf("SigOntFsPassport signature verify failed")
+	}
+}
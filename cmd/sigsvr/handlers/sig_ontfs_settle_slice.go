@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ontio/ontology-crypto/keypair"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	cliutil "github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+)
+
+// SigOntFsSettleSliceReq is the read settle slice the signer, as downloader, pays to node PayTo
+type SigOntFsSettleSliceReq struct {
+	FileHash     string `json:"file_hash"`
+	PayTo        string `json:"pay_to"`
+	SliceId      uint64 `json:"slice_id"`
+	PledgeHeight uint64 `json:"pledge_height"`
+}
+
+type SigOntFsSettleSliceRsp struct {
+	SettleSlice string `json:"settle_slice"`
+}
+
+func SigOntFsSettleSlice(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigOntFsSettleSliceReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsSettleSlice json.Unmarshal SigOntFsSettleSliceReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	payTo, err := common.AddressFromBase58(rawReq.PayTo)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsSettleSlice AddressFromBase58 error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsSettleSlice GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	settleSlice := &ontfs.FileReadSettleSlice{
+		FileHash:     []byte(rawReq.FileHash),
+		PayFrom:      signer.Address,
+		PayTo:        payTo,
+		SliceId:      rawReq.SliceId,
+		PledgeHeight: rawReq.PledgeHeight,
+		PubKey:       keypair.SerializePublicKey(signer.PublicKey),
+	}
+	settleSlice.Sig, err = cliutil.Sign(settleSlice.SignData(), signer)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsSettleSlice Sign error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	settleSlice.Serialization(sink)
+	resp.Result = &SigOntFsSettleSliceRsp{
+		SettleSlice: hex.EncodeToString(sink.Bytes()),
+	}
+}
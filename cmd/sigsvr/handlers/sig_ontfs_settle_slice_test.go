@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ontio/ontology-crypto/signature"
+	"github.com/ontio/ontology/account"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+	"testing"
+)
+
+func TestSigOntFsSettleSlice(t *testing.T) {
+	node := account.NewAccount("")
+	defAcc, err := testWallet.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Errorf("GetDefaultAccount error:%s", err)
+		return
+	}
+	sigReq := &SigOntFsSettleSliceReq{
+		FileHash:     "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o",
+		PayTo:        node.Address.ToBase58(),
+		SliceId:      2,
+		PledgeHeight: 100,
+	}
+	data, err := json.Marshal(sigReq)
+	if err != nil {
+		t.Errorf("json.Marshal SigOntFsSettleSliceReq error:%s", err)
+		return
+	}
+	req := &clisvrcom.CliRpcRequest{
+		Qid:     "t",
+		Method:  "sigontfssettleslice",
+		Params:  data,
+		Account: defAcc.Address.ToBase58(),
+		Pwd:     string(pwd),
+	}
+	rsp := &clisvrcom.CliRpcResponse{}
+	SigOntFsSettleSlice(req, rsp)
+	if rsp.ErrorCode != 0 {
+		t.Errorf("SigOntFsSettleSlice failed. ErrorCode:%d", rsp.ErrorCode)
+		return
+	}
+	raw, err := hex.DecodeString(rsp.Result.(*SigOntFsSettleSliceRsp).SettleSlice)
+	if err != nil {
+		t.Errorf("hex.DecodeString error:%s", err)
+		return
+	}
+	var settleSlice ontfs.FileReadSettleSlice
+	if err = settleSlice.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		t.Errorf("FileReadSettleSlice Deserialization error:%s", err)
+		return
+	}
+	if settleSlice.PayFrom != defAcc.Address || settleSlice.PayTo != node.Address {
+		t.Errorf("SigOntFsSettleSlice PayFrom or PayTo mismatch")
+		return
+	}
+	sig, err := signature.Deserialize(settleSlice.Sig)
+	if err != nil {
+		t.Errorf("signature.Deserialize error:%s", err)
+		return
+	}
+	if !signature.Verify(defAcc.PublicKey, settleSlice.SignData(), sig) {
+		t.Errorf("SigOntFsSettleSlice signature verify failed")
+	}
+}
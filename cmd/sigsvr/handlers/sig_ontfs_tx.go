@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	cliutil "github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	httpcom "github.com/ontio/ontology/http/base/common"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+type SigOntFsFile struct {
+	FileHash       string `json:"file_hash"`
+	FileDesc       string `json:"file_desc"`
+	FileBlockCount uint64 `json:"file_block_count"`
+	RealFileSize   uint64 `json:"real_file_size"`
+	CopyNumber     uint64 `json:"copy_number"`
+	FirstPdp       bool   `json:"first_pdp"`
+	PdpInterval    uint64 `json:"pdp_interval"`
+	TimeExpired    uint64 `json:"time_expired"`
+	PdpParam       string `json:"pdp_param"` //hex
+	StorageType    uint64 `json:"storage_type"`
+	BlockSize      uint64 `json:"block_size"`
+}
+
+type SigOntFsRenew struct {
+	FileHash       string `json:"file_hash"`
+	FileOwner      string `json:"file_owner"` //the signer when empty
+	NewTimeExpired uint64 `json:"new_time_expired"`
+}
+
+type SigOntFsTransfer struct {
+	FileHash string `json:"file_hash"`
+	NewOwner string `json:"new_owner"`
+}
+
+type SigOntFsReadPlan struct {
+	NodeAddr        string `json:"node_addr"`
+	MaxReadBlockNum uint64 `json:"max_read_block_num"`
+}
+
+type SigOntFsReadPledge struct {
+	FileHash  string              `json:"file_hash"`
+	ReadPlans []*SigOntFsReadPlan `json:"read_plans"`
+}
+
+// SigOntFsTxReq builds the ontfs transaction of Method, the signer is the file owner, payer or downloader.
+// Only the field of Method is used: Files for FsStoreFiles, Renews for FsRenewFiles,
+// FileHashes for FsDeleteFiles, Transfers for FsTransferFiles and ReadPledge for FsReadFilePledge
+type SigOntFsTxReq struct {
+	GasPrice   uint64              `json:"gas_price"`
+	GasLimit   uint64              `json:"gas_limit"`
+	Payer      string              `json:"payer"`
+	Method     string              `json:"method"`
+	Files      []*SigOntFsFile     `json:"files"`
+	Renews     []*SigOntFsRenew    `json:"renews"`
+	FileHashes []string            `json:"file_hashes"`
+	Transfers  []*SigOntFsTransfer `json:"transfers"`
+	ReadPledge *SigOntFsReadPledge `json:"read_pledge"`
+}
+
+type SigOntFsTxRsp struct {
+	SignedTx string `json:"signed_tx"`
+}
+
+func SigOntFsTx(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigOntFsTxReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsTx json.Unmarshal SigOntFsTxReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsTx GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	if signer == nil {
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	param, err := buildOntFsParam(rawReq, signer.Address)
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	mutable, err := httpcom.NewNativeInvokeTransaction(rawReq.GasPrice, rawReq.GasLimit, nutils.OntFSContractAddress, 0,
+		rawReq.Method, []interface{}{param})
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	if rawReq.Payer != "" {
+		payerAddress, err := common.AddressFromBase58(rawReq.Payer)
+		if err != nil {
+			log.Infof("Cli Qid:%s SigOntFsTx AddressFromBase58 error:%s", req.Qid, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+		mutable.Payer = payerAddress
+	}
+
+	err = cliutil.SignTransaction(signer, mutable)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsTx SignTransaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntFsTx tx IntoInmmutable error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	sink := common.ZeroCopySink{}
+	tx.Serialization(&sink)
+	resp.Result = &SigOntFsTxRsp{
+		SignedTx: hex.EncodeToString(sink.Bytes()),
+	}
+}
+
+// buildOntFsParam returns the serialized input of the ontfs method, signer acts for the files
+func buildOntFsParam(rawReq *SigOntFsTxReq, signer common.Address) ([]byte, error) {
+	sink := common.NewZeroCopySink(nil)
+	switch rawReq.Method {
+	case ontfs.FS_STORE_FILES:
+		if len(rawReq.Files) == 0 {
+			return nil, fmt.Errorf("files is empty")
+		}
+		var fileInfoList ontfs.FileInfoList
+		for _, file := range rawReq.Files {
+			pdpParam, err := hex.DecodeString(file.PdpParam)
+			if err != nil {
+				return nil, fmt.Errorf("file %s pdp_param should be hex", file.FileHash)
+			}
+			fileInfoList.FilesI = append(fileInfoList.FilesI, ontfs.FileInfo{
+				FileHash:       []byte(file.FileHash),
+				FileOwner:      signer,
+				FileDesc:       []byte(file.FileDesc),
+				FileBlockCount: file.FileBlockCount,
+				RealFileSize:   file.RealFileSize,
+				CopyNumber:     file.CopyNumber,
+				FirstPdp:       file.FirstPdp,
+				PdpInterval:    file.PdpInterval,
+				TimeExpired:    file.TimeExpired,
+				PdpParam:       pdpParam,
+				StorageType:    file.StorageType,
+				BlockSize:      file.BlockSize,
+			})
+		}
+		fileInfoList.Serialization(sink)
+	case ontfs.FS_RENEW_FILES:
+		if len(rawReq.Renews) == 0 {
+			return nil, fmt.Errorf("renews is empty")
+		}
+		var fileReNewList ontfs.FileReNewList
+		for _, renew := range rawReq.Renews {
+			fileOwner := signer
+			if renew.FileOwner != "" {
+				var err error
+				if fileOwner, err = common.AddressFromBase58(renew.FileOwner); err != nil {
+					return nil, fmt.Errorf("file %s file_owner error:%s", renew.FileHash, err)
+				}
+			}
+			fileReNewList.FilesReNew = append(fileReNewList.FilesReNew, ontfs.FileReNew{
+				FileHash:       []byte(renew.FileHash),
+				FileOwner:      fileOwner,
+				Payer:          signer,
+				NewTimeExpired: renew.NewTimeExpired,
+			})
+		}
+		fileReNewList.Serialization(sink)
+	case ontfs.FS_DELETE_FILES:
+		if len(rawReq.FileHashes) == 0 {
+			return nil, fmt.Errorf("file_hashes is empty")
+		}
+		var fileDelList ontfs.FileDelList
+		for _, fileHash := range rawReq.FileHashes {
+			fileDelList.FilesDel = append(fileDelList.FilesDel, ontfs.FileDel{FileHash: []byte(fileHash)})
+		}
+		fileDelList.Serialization(sink)
+	case ontfs.FS_TRANSFER_FILES:
+		if len(rawReq.Transfers) == 0 {
+			return nil, fmt.Errorf("transfers is empty")
+		}
+		var fileTransferList ontfs.FileTransferList
+		for _, transfer := range rawReq.Transfers {
+			newOwner, err := common.AddressFromBase58(transfer.NewOwner)
+			if err != nil {
+				return nil, fmt.Errorf("file %s new_owner error:%s", transfer.FileHash, err)
+			}
+			fileTransferList.FilesTransfer = append(fileTransferList.FilesTransfer, ontfs.FileTransfer{
+				FileHash: []byte(transfer.FileHash),
+				OriOwner: signer,
+				NewOwner: newOwner,
+			})
+		}
+		fileTransferList.Serialization(sink)
+	case ontfs.FS_READ_FILE_PLEDGE:
+		if rawReq.ReadPledge == nil || len(rawReq.ReadPledge.ReadPlans) == 0 {
+			return nil, fmt.Errorf("read_pledge has no read plan")
+		}
+		readPledge := ontfs.ReadPledge{
+			FileHash:   []byte(rawReq.ReadPledge.FileHash),
+			Downloader: signer,
+		}
+		for _, readPlan := range rawReq.ReadPledge.ReadPlans {
+			nodeAddr, err := common.AddressFromBase58(readPlan.NodeAddr)
+			if err != nil {
+				return nil, fmt.Errorf("read plan node_addr error:%s", err)
+			}
+			readPledge.ReadPlans = append(readPledge.ReadPlans, ontfs.ReadPlan{
+				NodeAddr:        nodeAddr,
+				MaxReadBlockNum: readPlan.MaxReadBlockNum,
+			})
+		}
+		readPledge.Serialization(sink)
+	default:
+		return nil, fmt.Errorf("unsupported ontfs method %s", rawReq.Method)
+	}
+	return sink.Bytes(), nil
+}
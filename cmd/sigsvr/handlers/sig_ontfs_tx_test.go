@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+	"testing"
+)
+
+func TestSigOntFsTx(t *testing.T) {
+	defAcc, err := testWallet.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Errorf("GetDefaultAccount error:%s", err)
+		return
+	}
+	sigReq := &SigOntFsTxReq{
+		GasPrice: 0,
+		GasLimit: 20000,
+		Method:   ontfs.FS_RENEW_FILES,
+		Renews: []*SigOntFsRenew{
+			{FileHash: "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o", NewTimeExpired: 1000},
+		},
+	}
+	data, err := json.Marshal(sigReq)
+	if err != nil {
+		t.Errorf("json.Marshal SigOntFsTxReq error:%s", err)
+		return
+	}
+	req := &clisvrcom.CliRpcRequest{
+		Qid:     "t",
+		Method:  "sigontfstx",
+		Params:  data,
+		Account: defAcc.Address.ToBase58(),
+		Pwd:     string(pwd),
+	}
+	rsp := &clisvrcom.CliRpcResponse{}
+	SigOntFsTx(req, rsp)
+	if rsp.ErrorCode != 0 {
+		t.Errorf("SigOntFsTx failed. ErrorCode:%d ErrorInfo:%s", rsp.ErrorCode, rsp.ErrorInfo)
+		return
+	}
+	rawTx, err := hex.DecodeString(rsp.Result.(*SigOntFsTxRsp).SignedTx)
+	if err != nil {
+		t.Errorf("hex.DecodeString error:%s", err)
+		return
+	}
+	tx, err := types.TransactionFromRawBytes(rawTx)
+	if err != nil {
+		t.Errorf("TransactionFromRawBytes error:%s", err)
+		return
+	}
+	if tx.Payer != defAcc.Address {
+		t.Errorf("SigOntFsTx payer:%s != %s", tx.Payer.ToBase58(), defAcc.Address.ToBase58())
+		return
+	}
+
+	sigReq.Method = ontfs.FS_GET_FILE_INFO
+	data, _ = json.Marshal(sigReq)
+	req.Params = data
+	rsp = &clisvrcom.CliRpcResponse{}
+	SigOntFsTx(req, rsp)
+	if rsp.ErrorCode != clisvrcom.CLIERR_INVALID_PARAMS {
+		t.Errorf("SigOntFsTx of unsupported method ErrorCode:%d", rsp.ErrorCode)
+	}
+}
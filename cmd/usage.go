@@ -100,6 +100,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ConfigFlag,
 			utils.LogLevelFlag,
 			utils.DisableEventLogFlag,
+			utils.OntFsHistoryBlocksFlag,
 			utils.DataDirFlag,
 		},
 	},
@@ -251,6 +252,29 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ImportEndHeightFlag,
 		},
 	},
+	{
+		Name: "GOVERNANCE",
+		Flags: []cli.Flag{
+			utils.GovernancePeerPubkeyFlag,
+			utils.GovernancePosFlag,
+			utils.GovernanceOntIdFlag,
+			utils.GovernanceKeyNoFlag,
+			utils.GovernancePeerCostFlag,
+			utils.GovernanceMaxAuthorizeFlag,
+		},
+	},
+	{
+		Name: "ONT ID",
+		Flags: []cli.Flag{
+			utils.OntIdFlag,
+			utils.OntIdControllerFlag,
+			utils.OntIdPubkeyFlag,
+			utils.OntIdRecoveryFlag,
+			utils.OntIdAttributeFlag,
+			utils.OntIdAttributePathFlag,
+			utils.OntIdKeyNoFlag,
+		},
+	},
 	{
 		Name: "MISC",
 	},
@@ -48,6 +48,11 @@ var (
 		Name:  "disable-event-log",
 		Usage: "Discard event log output by smart contract execution",
 	}
+	OntFsHistoryBlocksFlag = cli.UintFlag{
+		Name:  "ontfs-history-blocks",
+		Usage: "Keep ontfs and ONT ID contract state of the latest `<number>` blocks for historical queries. 0 disables it",
+		Value: 0,
+	}
 	WalletFileFlag = cli.StringFlag{
 		Name:  "wallet,w",
 		Value: config.DEFAULT_WALLET_FILE_NAME,
@@ -464,6 +469,71 @@ var (
 		Value: "m",
 	}
 
+	//Ontfs audit setting
+	FsAuditHeightFlag = cli.UintFlag{
+		Name:  "height",
+		Usage: "Audit at block `<height>` kept in the ontfs state history, instead of the current block",
+	}
+
+	//Governance setting
+	GovernancePeerPubkeyFlag = cli.StringFlag{
+		Name:  "peer-pubkey",
+		Usage: "Peer `<public keys>` in hex, separated by ','",
+	}
+	GovernancePosFlag = cli.StringFlag{
+		Name:  "pos",
+		Usage: "ONT `<amounts>` for each peer of --peer-pubkey, separated by ','",
+	}
+	GovernanceOntIdFlag = cli.StringFlag{
+		Name:  "ontid",
+		Usage: "ONT `<ID>` of the candidate, its key --keyno must belong to the account",
+	}
+	GovernanceKeyNoFlag = cli.UintFlag{
+		Name:  "keyno",
+		Usage: "Index `<number>` of the ONT ID key",
+		Value: 1,
+	}
+	GovernancePeerCostFlag = cli.UintFlag{
+		Name:  "peer-cost",
+		Usage: "Percent `<cost>` of the peer rewards the node keeps, the rest goes to its stakers",
+	}
+	GovernanceMaxAuthorizeFlag = cli.UintFlag{
+		Name:  "max-authorize",
+		Usage: "Max ONT `<amount>` the peer accepts from stakers",
+	}
+
+	//ONT ID setting
+	OntIdFlag = cli.StringFlag{
+		Name:  "ontid",
+		Usage: "ONT `<ID>` or label of the identity in wallet, may be omitted if wallet has only one identity",
+	}
+	OntIdControllerFlag = cli.StringFlag{
+		Name:  "controller",
+		Usage: "`<ID>` of the identity controller to sign with",
+		Value: "1",
+	}
+	OntIdPubkeyFlag = cli.StringFlag{
+		Name:  "pubkey",
+		Usage: "Public `<key>` in hex to add to or remove from the ONT ID",
+	}
+	OntIdRecoveryFlag = cli.StringFlag{
+		Name:  "recovery",
+		Usage: "Recovery `<address>` of the ONT ID",
+	}
+	OntIdAttributeFlag = cli.StringSliceFlag{
+		Name:  "attr",
+		Usage: "Attribute of the ONT ID as `<key:type:value>`, may be repeated",
+	}
+	OntIdAttributePathFlag = cli.StringFlag{
+		Name:  "path",
+		Usage: "`<key>` of the ONT ID attribute",
+	}
+	OntIdKeyNoFlag = cli.UintFlag{
+		Name:  "keyno",
+		Usage: "Index `<number>` of the ONT ID public key",
+		Value: 1,
+	}
+
 	//PreExecute switcher
 	TxpoolPreExecDisableFlag = cli.BoolFlag{
 		Name:  "disable-tx-pool-pre-exec",
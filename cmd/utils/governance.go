@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/governance"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+	"sort"
+)
+
+const VERSION_CONTRACT_GOVERNANCE = byte(0)
+
+// GetStorage returns the value a contract stores under key, nil if there is none
+func GetStorage(contractAddress common.Address, key []byte) ([]byte, error) {
+	result, ontErr := sendRpcRequest("getstorage", []interface{}{contractAddress.ToHexString(), hex.EncodeToString(key)})
+	if ontErr != nil {
+		return nil, ontErr.Error
+	}
+	value := ""
+	if string(result) != "null" {
+		err := json.Unmarshal(result, &value)
+		if err != nil {
+			return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+		}
+	}
+	if value == "" {
+		return nil, nil
+	}
+	return hex.DecodeString(value)
+}
+
+// InvokeGovernance signs and sends a call of the governance contract, param is the param struct of method
+func InvokeGovernance(gasPrice, gasLimit uint64, signer *account.Account, method string, param interface{}) (string, error) {
+	return InvokeNativeContract(gasPrice, gasLimit, signer, nutils.GovernanceContractAddress,
+		VERSION_CONTRACT_GOVERNANCE, method, []interface{}{param})
+}
+
+// GovernanceKey returns the key of the governance contract storage, without the contract address
+func GovernanceKey(field []byte, args ...[]byte) []byte {
+	key := append([]byte{}, field...)
+	for _, arg := range args {
+		key = append(key, arg...)
+	}
+	return key
+}
+
+// AuthorizeInfoKey returns the key of the stake address authorized to the peer
+func AuthorizeInfoKey(peerPubkey string, address common.Address) ([]byte, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer pubkey %s", peerPubkey)
+	}
+	return GovernanceKey(governance.AUTHORIZE_INFO_POOL, peerPubkeyPrefix, address[:]), nil
+}
+
+func getGovernanceStorage(key []byte) ([]byte, error) {
+	return GetStorage(nutils.GovernanceContractAddress, key)
+}
+
+func GetGovernanceView() (*governance.GovernanceView, error) {
+	value, err := getGovernanceStorage(GovernanceKey([]byte(governance.GOVERNANCE_VIEW)))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("governance view not found")
+	}
+	governanceView := new(governance.GovernanceView)
+	err = governanceView.Deserialize(bytes.NewBuffer(value))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize governance view error:%s", err)
+	}
+	return governanceView, nil
+}
+
+// GetPeerPool returns the peers of the view, sorted by their index
+func GetPeerPool(view uint32) ([]*governance.PeerPoolItem, error) {
+	viewBytes, err := governance.GetUint32Bytes(view)
+	if err != nil {
+		return nil, err
+	}
+	value, err := getGovernanceStorage(GovernanceKey([]byte(governance.PEER_POOL), viewBytes))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("peer pool of view %d not found", view)
+	}
+	peerPoolMap := &governance.PeerPoolMap{}
+	err = peerPoolMap.Deserialize(bytes.NewBuffer(value))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize peer pool error:%s", err)
+	}
+	return SortPeerPool(peerPoolMap), nil
+}
+
+func SortPeerPool(peerPoolMap *governance.PeerPoolMap) []*governance.PeerPoolItem {
+	peers := make([]*governance.PeerPoolItem, 0, len(peerPoolMap.PeerPoolMap))
+	for _, peer := range peerPoolMap.PeerPoolMap {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Index < peers[j].Index
+	})
+	return peers
+}
+
+// GetTotalStake returns the stake of address in the governance contract, zero if it has none
+func GetTotalStake(address common.Address) (*governance.TotalStake, error) {
+	value, err := getGovernanceStorage(GovernanceKey([]byte(governance.TOTAL_STAKE), address[:]))
+	if err != nil {
+		return nil, err
+	}
+	totalStake := &governance.TotalStake{Address: address}
+	if value == nil {
+		return totalStake, nil
+	}
+	err = totalStake.Deserialize(bytes.NewBuffer(value))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize total stake error:%s", err)
+	}
+	return totalStake, nil
+}
+
+// GetSplitFee returns the ONG fee to be split to the peers
+func GetSplitFee() (uint64, error) {
+	value, err := getGovernanceStorage(GovernanceKey([]byte(governance.SPLIT_FEE)))
+	if err != nil {
+		return 0, err
+	}
+	if value == nil {
+		return 0, nil
+	}
+	return governance.GetBytesUint64(value)
+}
+
+// GetSplitFeeAddress returns the ONG fee split to address and not withdrawn yet
+func GetSplitFeeAddress(address common.Address) (*governance.SplitFeeAddress, error) {
+	value, err := getGovernanceStorage(GovernanceKey([]byte(governance.SPLIT_FEE_ADDRESS), address[:]))
+	if err != nil {
+		return nil, err
+	}
+	splitFeeAddress := &governance.SplitFeeAddress{Address: address}
+	if value == nil {
+		return splitFeeAddress, nil
+	}
+	err = splitFeeAddress.Deserialize(bytes.NewBuffer(value))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize split fee address error:%s", err)
+	}
+	return splitFeeAddress, nil
+}
+
+// GetAuthorizeInfo returns the stake address authorized to the peer, nil if it has none
+func GetAuthorizeInfo(peerPubkey string, address common.Address) (*governance.AuthorizeInfo, error) {
+	key, err := AuthorizeInfoKey(peerPubkey, address)
+	if err != nil {
+		return nil, err
+	}
+	value, err := getGovernanceStorage(key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	authorizeInfo := new(governance.AuthorizeInfo)
+	err = authorizeInfo.Deserialize(bytes.NewBuffer(value))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize authorize info error:%s", err)
+	}
+	return authorizeInfo, nil
+}
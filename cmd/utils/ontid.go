@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/serialization"
+	httpcom "github.com/ontio/ontology/http/base/common"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+	cstates "github.com/ontio/ontology/smartcontract/states"
+	"strings"
+)
+
+const VERSION_CONTRACT_ONTID = byte(0)
+
+// Methods of the ONT ID contract
+const (
+	ONTID_REG_ID_WITH_PUBLIC_KEY = "regIDWithPublicKey"
+	ONTID_REG_ID_WITH_ATTRIBUTES = "regIDWithAttributes"
+	ONTID_ADD_KEY                = "addKey"
+	ONTID_REMOVE_KEY             = "removeKey"
+	ONTID_ADD_RECOVERY           = "addRecovery"
+	ONTID_CHANGE_RECOVERY        = "changeRecovery"
+	ONTID_ADD_ATTRIBUTES         = "addAttributes"
+	ONTID_REMOVE_ATTRIBUTE       = "removeAttribute"
+	ONTID_VERIFY_SIGNATURE       = "verifySignature"
+	ONTID_GET_DDO                = "getDDO"
+)
+
+// OntIdAttribute is the param of an ONT ID attribute, fields in the order the contract reads them
+type OntIdAttribute struct {
+	Key   []byte
+	Type  []byte
+	Value []byte
+}
+
+// ParseOntIdAttribute parse attribute from "key:type:value", value may contain ':'
+func ParseOntIdAttribute(attr string) (*OntIdAttribute, error) {
+	items := strings.SplitN(attr, ":", 3)
+	if len(items) != 3 || items[0] == "" {
+		return nil, fmt.Errorf("invalid attribute %s, should be key:type:value", attr)
+	}
+	return &OntIdAttribute{
+		Key:   []byte(items[0]),
+		Type:  []byte(items[1]),
+		Value: []byte(items[2]),
+	}, nil
+}
+
+type DDOPublicKey struct {
+	Id    string `json:"id"`
+	Type  string `json:"type"`
+	Curve string `json:"curve"`
+	Value string `json:"value"`
+}
+
+type DDOAttribute struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// DDO is the description object of an ONT ID
+type DDO struct {
+	Id         string          `json:"id"`
+	PublicKeys []*DDOPublicKey `json:"publicKeys"`
+	Attributes []*DDOAttribute `json:"attributes"`
+	Recovery   string          `json:"recovery,omitempty"`
+}
+
+// InvokeOntId signs and sends a call of the ONT ID contract
+func InvokeOntId(gasPrice, gasLimit uint64, signer *account.Account, method string, params []interface{}) (string, error) {
+	return InvokeNativeContract(gasPrice, gasLimit, signer, nutils.OntIDContractAddress, VERSION_CONTRACT_ONTID,
+		method, params)
+}
+
+// PrepareInvokeOntId pre-execute a call of the ONT ID contract signed by signer, for methods checking witness
+func PrepareInvokeOntId(signer *account.Account, method string, params []interface{}) (*cstates.PreExecResult, error) {
+	mutable, err := httpcom.NewNativeInvokeTransaction(0, 0, nutils.OntIDContractAddress, VERSION_CONTRACT_ONTID,
+		method, params)
+	if err != nil {
+		return nil, err
+	}
+	err = SignTransaction(signer, mutable)
+	if err != nil {
+		return nil, fmt.Errorf("SignTransaction error:%s", err)
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	err = tx.Serialize(&buffer)
+	if err != nil {
+		return nil, fmt.Errorf("tx serialize error:%s", err)
+	}
+	return PrepareSendRawTransaction(hex.EncodeToString(buffer.Bytes()))
+}
+
+// GetDDO return the description object of ONT ID, nil if the ID is not registered
+func GetDDO(id string) (*DDO, error) {
+	preResult, err := PrepareInvokeNativeContract(nutils.OntIDContractAddress, VERSION_CONTRACT_ONTID,
+		ONTID_GET_DDO, []interface{}{[]byte(id)})
+	if err != nil {
+		return nil, err
+	}
+	if preResult.State == 0 {
+		return nil, fmt.Errorf("prepare invoke failed")
+	}
+	data, err := hex.DecodeString(fmt.Sprintf("%v", preResult.Result))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return ParseDDO(id, data)
+}
+
+// ParseDDO decode the getDDO result of ONT ID
+func ParseDDO(id string, data []byte) (*DDO, error) {
+	buf := bytes.NewBuffer(data)
+	pubKeysData, err := serialization.ReadVarBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("read public keys error:%s", err)
+	}
+	attrsData, err := serialization.ReadVarBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("read attributes error:%s", err)
+	}
+	recoveryData, err := serialization.ReadVarBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("read recovery error:%s", err)
+	}
+
+	ddo := &DDO{
+		Id:         id,
+		PublicKeys: make([]*DDOPublicKey, 0),
+		Attributes: make([]*DDOAttribute, 0),
+	}
+	pubKeysBuf := bytes.NewBuffer(pubKeysData)
+	for pubKeysBuf.Len() > 0 {
+		index, err := serialization.ReadUint32(pubKeysBuf)
+		if err != nil {
+			return nil, fmt.Errorf("read public key index error:%s", err)
+		}
+		pubKey, err := serialization.ReadVarBytes(pubKeysBuf)
+		if err != nil {
+			return nil, fmt.Errorf("read public key error:%s", err)
+		}
+		keyType, curve := ddoKeyType(pubKey)
+		ddo.PublicKeys = append(ddo.PublicKeys, &DDOPublicKey{
+			Id:    fmt.Sprintf("%s#keys-%d", id, index),
+			Type:  keyType,
+			Curve: curve,
+			Value: hex.EncodeToString(pubKey),
+		})
+	}
+	attrsBuf := bytes.NewBuffer(attrsData)
+	for attrsBuf.Len() > 0 {
+		key, err := serialization.ReadVarBytes(attrsBuf)
+		if err != nil {
+			return nil, fmt.Errorf("read attribute key error:%s", err)
+		}
+		valueType, err := serialization.ReadVarBytes(attrsBuf)
+		if err != nil {
+			return nil, fmt.Errorf("read attribute type error:%s", err)
+		}
+		value, err := serialization.ReadVarBytes(attrsBuf)
+		if err != nil {
+			return nil, fmt.Errorf("read attribute value error:%s", err)
+		}
+		ddo.Attributes = append(ddo.Attributes, &DDOAttribute{
+			Key:   string(key),
+			Type:  string(valueType),
+			Value: string(value),
+		})
+	}
+	if len(recoveryData) > 0 {
+		recovery, err := common.AddressParseFromBytes(recoveryData)
+		if err != nil {
+			return nil, fmt.Errorf("parse recovery error:%s", err)
+		}
+		ddo.Recovery = recovery.ToBase58()
+	}
+	return ddo, nil
+}
+
+// ddoKeyType return the algorithm and curve name of a serialized public key
+func ddoKeyType(pubKey []byte) (string, string) {
+	if len(pubKey) < 2 {
+		return "unknown", ""
+	}
+	switch keypair.KeyType(pubKey[0]) {
+	case keypair.PK_P256_E, keypair.PK_P256_O, keypair.PK_P256_NC:
+		return "ECDSA", "P-256"
+	case keypair.PK_EDDSA:
+		return "EDDSA", "Ed25519"
+	case keypair.PK_ECDSA, keypair.PK_SM2:
+		keyType := "ECDSA"
+		if keypair.KeyType(pubKey[0]) == keypair.PK_SM2 {
+			keyType = "SM2"
+		}
+		curve, err := keypair.GetCurve(pubKey[1])
+		if err != nil {
+			return keyType, ""
+		}
+		return keyType, curve.Params().Name
+	default:
+		return "unknown", ""
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/serialization"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOntIdAttribute(t *testing.T) {
+	attr, err := ParseOntIdAttribute("url:string:https://ont.io")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("url"), attr.Key)
+	assert.Equal(t, []byte("string"), attr.Type)
+	assert.Equal(t, []byte("https://ont.io"), attr.Value)
+
+	_, err = ParseOntIdAttribute("url:string")
+	assert.NotNil(t, err)
+	_, err = ParseOntIdAttribute(":string:value")
+	assert.NotNil(t, err)
+}
+
+func TestParseDDO(t *testing.T) {
+	_, pub, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	assert.Nil(t, err)
+	pubKey := keypair.SerializePublicKey(pub)
+	_, smPub, err := keypair.GenerateKeyPair(keypair.PK_SM2, keypair.SM2P256V1)
+	assert.Nil(t, err)
+	smPubKey := keypair.SerializePublicKey(smPub)
+	recovery := common.Address{1, 2, 3}
+
+	pubKeys := new(bytes.Buffer)
+	serialization.WriteUint32(pubKeys, 1)
+	serialization.WriteVarBytes(pubKeys, pubKey)
+	serialization.WriteUint32(pubKeys, 3)
+	serialization.WriteVarBytes(pubKeys, smPubKey)
+	attrs := new(bytes.Buffer)
+	serialization.WriteVarBytes(attrs, []byte("name"))
+	serialization.WriteVarBytes(attrs, []byte("string"))
+	serialization.WriteVarBytes(attrs, []byte("alice"))
+	ddoData := new(bytes.Buffer)
+	serialization.WriteVarBytes(ddoData, pubKeys.Bytes())
+	serialization.WriteVarBytes(ddoData, attrs.Bytes())
+	serialization.WriteVarBytes(ddoData, recovery[:])
+
+	id := "did:ont:TVuF6FH1PskzWJAFhWAFg17NSitMDEBNoa"
+	ddo, err := ParseDDO(id, ddoData.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, id, ddo.Id)
+	assert.Equal(t, 2, len(ddo.PublicKeys))
+	assert.Equal(t, &DDOPublicKey{
+		Id:    id + "#keys-1",
+		Type:  "ECDSA",
+		Curve: "P-256",
+		Value: hex.EncodeToString(pubKey),
+	}, ddo.PublicKeys[0])
+	assert.Equal(t, id+"#keys-3", ddo.PublicKeys[1].Id)
+	assert.Equal(t, "SM2", ddo.PublicKeys[1].Type)
+	assert.Equal(t, "sm2p256v1", ddo.PublicKeys[1].Curve)
+	assert.Equal(t, []*DDOAttribute{{Key: "name", Type: "string", Value: "alice"}}, ddo.Attributes)
+	assert.Equal(t, recovery.ToBase58(), ddo.Recovery)
+
+	ddoData.Reset()
+	serialization.WriteVarBytes(ddoData, pubKeys.Bytes())
+	serialization.WriteVarBytes(ddoData, nil)
+	serialization.WriteVarBytes(ddoData, nil)
+	ddo, err = ParseDDO(id, ddoData.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ddo.Attributes))
+	assert.Equal(t, "", ddo.Recovery)
+}
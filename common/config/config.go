@@ -124,6 +124,26 @@ func GetOpcodeUpdateCheckHeight(id uint32) uint32 {
 	return OPCODE_UPDATE_CHECK_HEIGHT[id]
 }
 
+var ONTFS_RECORD_UPGRADE_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.ONTFS_RECORD_UPGRADE_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.ONTFS_RECORD_UPGRADE_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                             //Network solo
+}
+
+func GetOntFsRecordUpgradeHeight(id uint32) uint32 {
+	return ONTFS_RECORD_UPGRADE_HEIGHT[id]
+}
+
+var ONTFS_PDP_CHALLENGE_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.ONTFS_PDP_CHALLENGE_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.ONTFS_PDP_CHALLENGE_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                            //Network solo
+}
+
+func GetOntFsPdpChallengeHeight(id uint32) uint32 {
+	return ONTFS_PDP_CHALLENGE_HEIGHT[id]
+}
+
 func GetNetworkName(id uint32) string {
 	name, ok := NETWORK_NAME[id]
 	if ok {
@@ -499,6 +519,8 @@ type CommonConfig struct {
 	GasLimit       uint64
 	GasPrice       uint64
 	DataDir        string
+
+	OntFsHistoryBlocks uint32
 }
 
 type ConsensusConfig struct {
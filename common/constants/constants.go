@@ -19,6 +19,7 @@
 package constants
 
 import (
+	"math"
 	"time"
 )
 
@@ -86,3 +87,11 @@ const STATE_HASH_HEIGHT_POLARIS = 850000
 // neovm opcode update check height
 const OPCODE_HEIGHT_UPDATE_FIRST_MAINNET = 6300000
 const OPCODE_HEIGHT_UPDATE_FIRST_POLARIS = 2100000
+
+// ontfs record version upgrade height, not scheduled until the release setting it is agreed
+const ONTFS_RECORD_UPGRADE_HEIGHT_MAINNET = math.MaxUint32
+const ONTFS_RECORD_UPGRADE_HEIGHT_POLARIS = math.MaxUint32
+
+// ontfs pdp challenge jitter height, not scheduled until the release setting it is agreed
+const ONTFS_PDP_CHALLENGE_HEIGHT_MAINNET = math.MaxUint32
+const ONTFS_PDP_CHALLENGE_HEIGHT_POLARIS = math.MaxUint32
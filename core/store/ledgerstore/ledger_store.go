@@ -51,6 +51,7 @@ import (
 	scommon "github.com/ontio/ontology/smartcontract/common"
 	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/service/native/global_params"
+	"github.com/ontio/ontology/smartcontract/service/native/ont"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 	"github.com/ontio/ontology/smartcontract/service/neovm"
 	sstate "github.com/ontio/ontology/smartcontract/states"
@@ -86,6 +87,7 @@ type LedgerStoreImp struct {
 	vbftPeerInfoblock    map[string]uint32 //pubInfo save pubkey,peerindex
 	lock                 sync.RWMutex
 	stateHashCheckHeight uint32
+	ontFsUpgradeHeight   uint32
 }
 
 //NewLedgerStore return LedgerStoreImp instance
@@ -97,6 +99,7 @@ func NewLedgerStore(dataDir string, stateHashHeight uint32) (*LedgerStoreImp, er
 		vbftPeerInfoblock:    make(map[string]uint32),
 		savingBlockSemaphore: make(chan bool, 1),
 		stateHashCheckHeight: stateHashHeight,
+		ontFsUpgradeHeight:   config.GetOntFsRecordUpgradeHeight(config.DefConfig.P2PNode.NetworkId),
 	}
 
 	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), true)
@@ -111,6 +114,7 @@ func NewLedgerStore(dataDir string, stateHashHeight uint32) (*LedgerStoreImp, er
 	if err != nil {
 		return nil, fmt.Errorf("NewStateStore error %s", err)
 	}
+	stateStore.SetStateHistory(config.DefConfig.Common.OntFsHistoryBlocks, stateHistoryPrefixes()...)
 	ledgerStore.stateStore = stateStore
 
 	eventState, err := NewEventStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirEvent))
@@ -122,6 +126,17 @@ func NewLedgerStore(dataDir string, stateHashHeight uint32) (*LedgerStoreImp, er
 	return ledgerStore, nil
 }
 
+// stateHistoryPrefixes return the raw key prefixes of the state kept in history. The ontfs audit needs
+// the ong balance of the contract at the same height, ONT IDs are resolved to DID documents at past
+// heights too.
+func stateHistoryPrefixes() [][]byte {
+	return [][]byte{
+		genStorageKeyPrefix(utils.OntFSContractAddress),
+		genStorageKeyPrefix(utils.OntIDContractAddress),
+		append([]byte{byte(scom.ST_STORAGE)}, ont.GenBalanceKey(utils.OngContractAddress, utils.OntFSContractAddress)...),
+	}
+}
+
 //InitLedgerStoreWithGenesisBlock init the ledger store with genesis block. It's the first operation after NewLedgerStore.
 func (this *LedgerStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.Block, defaultBookkeeper []keypair.PublicKey) error {
 	hasInit, err := this.hasAlreadyInitGenesisBlock()
@@ -633,6 +648,15 @@ func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.Execu
 		if err != nil {
 			return
 		}
+
+		if block.Header.Height == this.ontFsUpgradeHeight {
+			cache := storage.NewCacheDB(overlay)
+			err = migrateOntFsRecords(config, cache, this)
+			if err != nil {
+				return
+			}
+			cache.Commit()
+		}
 	}
 
 	cache := storage.NewCacheDB(overlay)
@@ -722,6 +746,11 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 
 	log.Debugf("the state transition hash of block %d is:%s", blockHeight, result.Hash.ToHexString())
 
+	err = this.stateStore.saveStateHistory(blockHeight, result.WriteSet)
+	if err != nil {
+		return fmt.Errorf("saveStateHistory error %s", err)
+	}
+
 	result.WriteSet.ForEach(func(key, val []byte) {
 		if len(val) == 0 {
 			this.stateStore.BatchDeleteRawKey(key)
@@ -1019,16 +1048,44 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 //PreExecuteContract return the result of smart contract execution without commit to store
 func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.PreExecResult, error) {
 	height := this.GetCurrentBlockHeight()
-	stf := &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: neovm.MIN_TRANSACTION_GAS, Result: nil}
-
 	config := &smartcontract.Config{
 		Time:      uint32(time.Now().Unix()),
 		Height:    height + 1,
 		Tx:        tx,
 		BlockHash: this.GetBlockHash(height),
 	}
+	return this.preExecuteContract(config, this.stateStore.NewOverlayDB())
+}
+
+//PreExecuteContractAtHeight return the result of smart contract execution without commit to store,
+//against the state after the block at height. Only the ontfs contract state and its ong balance are
+//kept in history, the state of other contracts is the current one.
+func (this *LedgerStoreImp) PreExecuteContractAtHeight(tx *types.Transaction, height uint32) (*sstate.PreExecResult, error) {
+	stf := &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: neovm.MIN_TRANSACTION_GAS, Result: nil}
+	overlay, err := this.stateStore.NewHistoryOverlayDB(height)
+	if err != nil {
+		return stf, err
+	}
+	header, err := this.GetHeaderByHeight(height)
+	if err != nil {
+		return stf, err
+	}
+	if header == nil {
+		return stf, fmt.Errorf("header of height %d not found", height)
+	}
+	config := &smartcontract.Config{
+		Time:      header.Timestamp,
+		Height:    height + 1,
+		Tx:        tx,
+		BlockHash: header.Hash(),
+	}
+	return this.preExecuteContract(config, overlay)
+}
+
+func (this *LedgerStoreImp) preExecuteContract(config *smartcontract.Config, overlay *overlaydb.OverlayDB) (*sstate.PreExecResult, error) {
+	tx := config.Tx
+	stf := &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: neovm.MIN_TRANSACTION_GAS, Result: nil}
 
-	overlay := this.stateStore.NewOverlayDB()
 	cache := storage.NewCacheDB(overlay)
 	preGas, err := this.getPreGas(config, cache)
 	if err != nil {
@@ -0,0 +1,301 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// The state history journals, for every block, the value each journaled
+// storage key had before the block was applied. Rolling the current state
+// back through the journal rebuilds the journaled state at a past height.
+//
+// key:   DATA_STATE_HISTORY + height(big endian) + raw storage key
+// value: 0x00 if the key did not exist, 0x01 + value otherwise
+//
+// Every journaled key is indexed by key too, so a read at a past height seeks
+// the first block after it which changed the key instead of loading the journal.
+//
+// key:   DATA_STATE_HISTORY_INDEX + key length(big endian) + raw storage key + height(big endian)
+// value: empty
+//
+// Every journaled height also writes a marker key without storage key, so a
+// gap in the journal (history disabled for a while) restarts the history.
+const (
+	historyKeyAbsent  byte = 0
+	historyKeyPresent byte = 1
+)
+
+// SetStateHistory keeps the overwritten state under prefixes for the latest
+// blocks blocks. blocks 0 disables the state history.
+func (self *StateStore) SetStateHistory(blocks uint32, prefixes ...[]byte) {
+	self.historyBlocks = blocks
+	self.historyPrefixes = prefixes
+}
+
+// StateHistoryWindow return the lowest and highest height the journaled state can
+// be rebuilt at.
+func (self *StateStore) StateHistoryWindow() (uint32, uint32, error) {
+	if self.historyBlocks == 0 {
+		return 0, 0, fmt.Errorf("state history is disabled")
+	}
+	_, current, err := self.GetCurrentBlock()
+	if err != nil {
+		return 0, 0, fmt.Errorf("GetCurrentBlock error %s", err)
+	}
+	start, err := self.getStateHistoryStart()
+	if err != nil {
+		return 0, 0, fmt.Errorf("getStateHistoryStart error %s", err)
+	}
+	lowest := uint32(0)
+	if start > 0 {
+		lowest = start - 1
+	}
+	if current >= self.historyBlocks && current-self.historyBlocks+1 > lowest {
+		lowest = current - self.historyBlocks + 1
+	}
+	return lowest, current, nil
+}
+
+// NewHistoryOverlayDB return an overlay of the state at height. Only state
+// under the journaled prefixes is rolled back, other state is the current one.
+func (self *StateStore) NewHistoryOverlayDB(height uint32) (*overlaydb.OverlayDB, error) {
+	lowest, current, err := self.StateHistoryWindow()
+	if err != nil {
+		return nil, err
+	}
+	if height < lowest || height > current {
+		return nil, fmt.Errorf("height %d out of state history [%d, %d]", height, lowest, current)
+	}
+	return overlaydb.NewOverlayDB(&historyStore{PersistStore: self.store, state: self, base: height}), nil
+}
+
+// saveStateHistory journals the state overwritten by the write set of the block at height.
+// It should be called in the batch of the block, before the write set is put.
+func (self *StateStore) saveStateHistory(height uint32, writeSet *overlaydb.MemDB) error {
+	if self.historyBlocks == 0 {
+		return nil
+	}
+	continued := false
+	if height > 0 {
+		has, err := self.store.Has(genStateHistoryKey(height-1, nil))
+		if err != nil {
+			return err
+		}
+		continued = has
+	}
+	if !continued {
+		start := make([]byte, 4)
+		binary.LittleEndian.PutUint32(start, height)
+		self.store.BatchPut(genStateHistoryStartKey(), start)
+	}
+	self.store.BatchPut(genStateHistoryKey(height, nil), []byte{historyKeyPresent})
+
+	var err error
+	writeSet.ForEach(func(key, val []byte) {
+		if err != nil || !self.isHistoryKey(key) {
+			return
+		}
+		old, e := self.store.Get(key)
+		entry := []byte{historyKeyPresent}
+		if e == scom.ErrNotFound {
+			entry[0] = historyKeyAbsent
+		} else if e != nil {
+			err = e
+			return
+		}
+		self.store.BatchPut(genStateHistoryKey(height, key), append(entry, old...))
+		self.store.BatchPut(genStateHistoryIndexKey(key, height), []byte{})
+	})
+	if err != nil {
+		return err
+	}
+
+	if height >= self.historyBlocks {
+		return self.pruneStateHistory(height - self.historyBlocks)
+	}
+	return nil
+}
+
+func (self *StateStore) pruneStateHistory(height uint32) error {
+	heightKey := genStateHistoryKey(height, nil)
+	iter := self.store.NewIterator(heightKey)
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		key := iter.Key()
+		if len(key) > len(heightKey) {
+			self.store.BatchDelete(genStateHistoryIndexKey(key[len(heightKey):], height))
+		}
+		self.store.BatchDelete(append([]byte{}, key...))
+	}
+	return iter.Error()
+}
+
+// loadStateHistory put into rollback the journaled values under prefix, taking the lowest
+// height in [from, to] for each key, without overriding keys already in rollback.
+func (self *StateStore) loadStateHistory(rollback *overlaydb.MemDB, prefix []byte, from, to uint32) error {
+	for h := from; h <= to; h++ {
+		heightKey := genStateHistoryKey(h, nil)
+		iter := self.store.NewIterator(genStateHistoryKey(h, prefix))
+		for has := iter.First(); has; has = iter.Next() {
+			key := iter.Key()[len(heightKey):]
+			if len(key) == 0 {
+				continue
+			}
+			if _, unknown := rollback.Get(key); !unknown {
+				continue
+			}
+			value := iter.Value()
+			if len(value) == 0 || value[0] == historyKeyAbsent {
+				rollback.Delete(key)
+			} else {
+				rollback.Put(key, value[1:])
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackValue return the value of key before the blocks after base, if one of them changed it.
+func (self *StateStore) rollbackValue(base uint32, key []byte) (value []byte, found bool, err error) {
+	prefix := genStateHistoryIndexPrefix(key)
+	iter := self.store.NewIterator(prefix)
+	defer iter.Release()
+	has := false
+	if seeker, ok := iter.(interface{ Seek(key []byte) bool }); ok {
+		has = seeker.Seek(genStateHistoryIndexKey(key, base+1))
+	} else {
+		for has = iter.First(); has; has = iter.Next() {
+			if binary.BigEndian.Uint32(iter.Key()[len(prefix):]) > base {
+				break
+			}
+		}
+	}
+	if !has {
+		return nil, false, iter.Error()
+	}
+
+	height := binary.BigEndian.Uint32(iter.Key()[len(prefix):])
+	entry, err := self.store.Get(genStateHistoryKey(height, key))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(entry) == 0 || entry[0] == historyKeyAbsent {
+		return nil, true, nil
+	}
+	return entry[1:], true, nil
+}
+
+func (self *StateStore) isHistoryKey(key []byte) bool {
+	for _, prefix := range self.historyPrefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *StateStore) getStateHistoryStart() (uint32, error) {
+	data, err := self.store.Get(genStateHistoryStartKey())
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, fmt.Errorf("invalid state history start")
+	}
+	return binary.LittleEndian.Uint32(data), nil
+}
+
+func genStateHistoryKey(height uint32, key []byte) []byte {
+	data := make([]byte, 5, 5+len(key))
+	data[0] = byte(scom.DATA_STATE_HISTORY)
+	binary.BigEndian.PutUint32(data[1:], height)
+	return append(data, key...)
+}
+
+func genStateHistoryIndexPrefix(key []byte) []byte {
+	data := make([]byte, 5, 9+len(key))
+	data[0] = byte(scom.DATA_STATE_HISTORY_INDEX)
+	binary.BigEndian.PutUint32(data[1:], uint32(len(key)))
+	return append(data, key...)
+}
+
+func genStateHistoryIndexKey(key []byte, height uint32) []byte {
+	data := genStateHistoryIndexPrefix(key)
+	return append(data, byte(height>>24), byte(height>>16), byte(height>>8), byte(height))
+}
+
+func genStateHistoryStartKey() []byte {
+	return []byte{byte(scom.SYS_STATE_HISTORY)}
+}
+
+// historyStore reads the state under the journaled prefixes at the base height, a key
+// at a time, even if blocks are saved while it is read.
+type historyStore struct {
+	scom.PersistStore
+	state *StateStore
+	base  uint32
+}
+
+func (self *historyStore) Get(key []byte) ([]byte, error) {
+	value, err := self.PersistStore.Get(key)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, err
+	}
+	if !self.state.isHistoryKey(key) {
+		return value, err
+	}
+	old, found, e := self.state.rollbackValue(self.base, key)
+	if e != nil {
+		return nil, e
+	}
+	if !found {
+		return value, err
+	}
+	if old == nil {
+		return nil, scom.ErrNotFound
+	}
+	return old, nil
+}
+
+func (self *historyStore) NewIterator(prefix []byte) scom.StoreIterator {
+	// the backend iterator reads a snapshot, so the blocks saved after it are all in the journal
+	backIter := self.PersistStore.NewIterator(prefix)
+	late := overlaydb.NewMemDB(0, 0)
+	_, current, err := self.state.GetCurrentBlock()
+	if err == nil && current > self.base {
+		err = self.state.loadStateHistory(late, prefix, self.base+1, current)
+	}
+	if err != nil {
+		backIter.Release()
+		return iterator.NewEmptyIterator(err)
+	}
+	return overlaydb.NewJoinIter(late.NewIterator(util.BytesPrefix(prefix)), backIter)
+}
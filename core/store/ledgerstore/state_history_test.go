@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ont"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	historyPrefix = []byte("\x05fs")
+	otherPrefix   = []byte("\x05other")
+)
+
+func historyKey(name string) []byte {
+	return append(append([]byte{}, historyPrefix...), name...)
+}
+
+func saveHistoryBlock(t *testing.T, db *StateStore, height uint32, puts map[string]string, dels ...string) {
+	overlay := db.NewOverlayDB()
+	for key, val := range puts {
+		overlay.Put([]byte(key), []byte(val))
+	}
+	for _, key := range dels {
+		overlay.Delete([]byte(key))
+	}
+	saveHistoryWriteSet(t, db, height, overlay)
+}
+
+func saveHistoryWriteSet(t *testing.T, db *StateStore, height uint32, overlay *overlaydb.OverlayDB) {
+	db.NewBatch()
+	assert.Nil(t, db.saveStateHistory(height, overlay.GetWriteSet()))
+	overlay.GetWriteSet().ForEach(func(key, val []byte) {
+		if len(val) == 0 {
+			db.BatchDeleteRawKey(key)
+		} else {
+			db.BatchPutRawKeyVal(key, val)
+		}
+	})
+	assert.Nil(t, db.SaveCurrentBlock(height, common.Uint256{}))
+	assert.Nil(t, db.CommitTo())
+}
+
+func checkHistoryState(t *testing.T, overlay *overlaydb.OverlayDB, expected map[string]string) {
+	for _, name := range []string{"a", "b", "c", "d"} {
+		val, err := overlay.Get(historyKey(name))
+		assert.Nil(t, err)
+		assert.Equal(t, expected[name], string(val), name)
+	}
+	found := make(map[string]string)
+	iter := overlay.NewIterator(historyPrefix)
+	for has := iter.First(); has; has = iter.Next() {
+		found[string(iter.Key()[len(historyPrefix):])] = string(iter.Value())
+	}
+	iter.Release()
+	assert.Nil(t, iter.Error())
+	assert.Equal(t, expected, found)
+}
+
+func TestStateHistory(t *testing.T) {
+	db := NewMemStateStore(0)
+	db.SetStateHistory(3, historyPrefix)
+	other := string(otherPrefix) + "x"
+	a, b, c, d := string(historyKey("a")), string(historyKey("b")), string(historyKey("c")), string(historyKey("d"))
+
+	saveHistoryBlock(t, db, 0, map[string]string{a: "a0", other: "x0"})
+	saveHistoryBlock(t, db, 1, map[string]string{a: "a1", b: "b1", other: "x1"})
+	saveHistoryBlock(t, db, 2, map[string]string{b: "b2"}, a)
+	saveHistoryBlock(t, db, 3, map[string]string{a: "a3"})
+	saveHistoryBlock(t, db, 4, map[string]string{c: "c4"})
+
+	lowest, current, err := db.StateHistoryWindow()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), lowest)
+	assert.Equal(t, uint32(4), current)
+	_, err = db.NewHistoryOverlayDB(1)
+	assert.NotNil(t, err)
+	_, err = db.NewHistoryOverlayDB(5)
+	assert.NotNil(t, err)
+
+	at2, err := db.NewHistoryOverlayDB(2)
+	assert.Nil(t, err)
+	checkHistoryState(t, at2, map[string]string{"b": "b2"})
+	at3, err := db.NewHistoryOverlayDB(3)
+	assert.Nil(t, err)
+	checkHistoryState(t, at3, map[string]string{"a": "a3", "b": "b2"})
+	at4, err := db.NewHistoryOverlayDB(4)
+	assert.Nil(t, err)
+	checkHistoryState(t, at4, map[string]string{"a": "a3", "b": "b2", "c": "c4"})
+
+	// state outside the journaled prefixes is the current one
+	val, err := at2.Get([]byte(other))
+	assert.Nil(t, err)
+	assert.Equal(t, "x1", string(val))
+
+	// blocks saved after the overlay was built don't leak into it
+	saveHistoryBlock(t, db, 5, map[string]string{b: "b5", d: "d5"}, c)
+	checkHistoryState(t, at2, map[string]string{"b": "b2"})
+	checkHistoryState(t, at4, map[string]string{"a": "a3", "b": "b2", "c": "c4"})
+
+	// the journal of height 2 is pruned
+	iter := db.store.NewIterator(genStateHistoryKey(2, nil))
+	assert.False(t, iter.First())
+	iter.Release()
+	_, err = db.NewHistoryOverlayDB(2)
+	assert.NotNil(t, err)
+
+	// a gap in the journal restarts the history
+	db.SetStateHistory(0)
+	saveHistoryBlock(t, db, 6, map[string]string{a: "a6"})
+	_, err = db.NewHistoryOverlayDB(6)
+	assert.NotNil(t, err)
+	db.SetStateHistory(3, historyPrefix)
+	saveHistoryBlock(t, db, 7, map[string]string{a: "a7"})
+	lowest, _, err = db.StateHistoryWindow()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(6), lowest)
+	at6, err := db.NewHistoryOverlayDB(6)
+	assert.Nil(t, err)
+	checkHistoryState(t, at6, map[string]string{"a": "a6", "b": "b5", "d": "d5"})
+}
+
+func TestStateHistory_OntFsBalance(t *testing.T) {
+	db := NewMemStateStore(0)
+	db.SetStateHistory(3, stateHistoryPrefixes()...)
+	other := common.Address{0x01}
+	setBalances := func(height uint32, fsBalance uint64, otherBalance uint64) {
+		overlay := db.NewOverlayDB()
+		cache := storage.NewCacheDB(overlay)
+		cache.Put(ont.GenBalanceKey(utils.OngContractAddress, utils.OntFSContractAddress),
+			utils.GenUInt64StorageItem(fsBalance).ToArray())
+		cache.Put(ont.GenBalanceKey(utils.OngContractAddress, other), utils.GenUInt64StorageItem(otherBalance).ToArray())
+		cache.Commit()
+		saveHistoryWriteSet(t, db, height, overlay)
+	}
+	balance := func(overlay *overlaydb.OverlayDB, addr common.Address) uint64 {
+		value, err := utils.GetStorageUInt64(&native.NativeService{CacheDB: storage.NewCacheDB(overlay)},
+			ont.GenBalanceKey(utils.OngContractAddress, addr))
+		assert.Nil(t, err)
+		return value
+	}
+
+	setBalances(0, 100, 1)
+	setBalances(1, 200, 2)
+	setBalances(2, 300, 3)
+
+	// the ong balance of the ontfs contract is rolled back, other balances are the current ones
+	at0, err := db.NewHistoryOverlayDB(0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), balance(at0, utils.OntFSContractAddress))
+	assert.Equal(t, uint64(3), balance(at0, other))
+	at1, err := db.NewHistoryOverlayDB(1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(200), balance(at1, utils.OntFSContractAddress))
+	at2, err := db.NewHistoryOverlayDB(2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300), balance(at2, utils.OntFSContractAddress))
+}
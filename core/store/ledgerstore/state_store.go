@@ -51,6 +51,8 @@ type StateStore struct {
 	deltaMerkleTree      *merkle.CompactMerkleTree //Merkle tree of delta state root
 	merkleHashStore      merkle.HashStore
 	stateHashCheckHeight uint32
+	historyBlocks        uint32   //Count of blocks whose overwritten state is kept
+	historyPrefixes      [][]byte //Raw key prefixes of the state kept in history
 }
 
 //NewStateStore return state store instance
@@ -372,6 +374,10 @@ func (self *StateStore) getStorageKey(key *states.StorageKey) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func genStorageKeyPrefix(contractAddress common.Address) []byte {
+	return append([]byte{byte(scom.ST_STORAGE)}, contractAddress[:]...)
+}
+
 func (self *StateStore) GetStateMerkleRootWithNewHash(writeSetHash common.Uint256) common.Uint256 {
 	return self.deltaMerkleTree.GetRootWithNewLeaf(writeSetHash)
 }
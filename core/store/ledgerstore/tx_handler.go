@@ -36,9 +36,11 @@ import (
 	"github.com/ontio/ontology/errors"
 	"github.com/ontio/ontology/smartcontract"
 	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native"
 	"github.com/ontio/ontology/smartcontract/service/native/global_params"
 	ninit "github.com/ontio/ontology/smartcontract/service/native/init"
 	"github.com/ontio/ontology/smartcontract/service/native/ont"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 	"github.com/ontio/ontology/smartcontract/service/neovm"
 	"github.com/ontio/ontology/smartcontract/storage"
@@ -335,6 +337,28 @@ func refreshGlobalParam(config *smartcontract.Config, cache *storage.CacheDB, st
 	return nil
 }
 
+// migrateOntFsRecords rewrites the ontfs records stored before record versioning in the current version
+func migrateOntFsRecords(config *smartcontract.Config, cache *storage.CacheDB, store store.LedgerStore) error {
+	sc := smartcontract.SmartContract{
+		Config:  config,
+		CacheDB: cache,
+		Store:   store,
+		Gas:     math.MaxUint64,
+	}
+
+	service, _ := sc.NewNativeService()
+	var count uint64
+	var err error
+	ontfs.InteropCall(service, func(native *native.NativeService) {
+		count, err = ontfs.MigrateRecords(native)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate ontfs records error:%s", err)
+	}
+	log.Infof("[migrateOntFsRecords] %d ontfs records migrated at height %d", count, config.Height)
+	return nil
+}
+
 func getBalanceFromNative(config *smartcontract.Config, cache *storage.CacheDB, store store.LedgerStore, address common.Address) (uint64, error) {
 	bf := new(bytes.Buffer)
 	if err := utils.WriteAddress(bf, address); err != nil {
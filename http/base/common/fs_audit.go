@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	cstate "github.com/ontio/ontology/smartcontract/states"
+)
+
+type FsAuditMismatchRsp struct {
+	Kind     uint64   `json:"kind"`
+	Address  string   `json:"address"`
+	Expected uint64   `json:"expected"`
+	Actual   uint64   `json:"actual"`
+	Records  []string `json:"records"`
+}
+
+type FsAuditRsp struct {
+	ContractBalance uint64               `json:"contractBalance"`
+	FileRestAmount  uint64               `json:"fileRestAmount"`
+	SpaceRestAmount uint64               `json:"spaceRestAmount"`
+	ReadPledgeMoney uint64               `json:"readPledgeMoney"`
+	NodePledge      uint64               `json:"nodePledge"`
+	NodeProfit      uint64               `json:"nodeProfit"`
+	RenewEscrow     uint64               `json:"renewEscrow"`
+	TreasuryBalance uint64               `json:"treasuryBalance"`
+	InsurancePool   uint64               `json:"insurancePool"`
+	Liabilities     uint64               `json:"liabilities"`
+	Mismatches      []FsAuditMismatchRsp `json:"mismatches"`
+}
+
+// NewFsAuditTransaction return the transaction which audits the books of the ontfs contract
+func NewFsAuditTransaction() (*types.Transaction, error) {
+	mutable, err := NewNativeInvokeTransaction(0, 0, utils.OntFSContractAddress, 0, ontfs.FS_AUDIT,
+		[]interface{}{[]byte{}})
+	if err != nil {
+		return nil, fmt.Errorf("NewNativeInvokeTransaction error:%s", err)
+	}
+	return mutable.IntoImmutable()
+}
+
+// ParseFsAuditResult parse the pre-execute result of the ontfs audit transaction
+func ParseFsAuditResult(result *cstate.PreExecResult) (*FsAuditRsp, error) {
+	if result.State == 0 {
+		return nil, fmt.Errorf("prepare invoke failed")
+	}
+	data, err := hex.DecodeString(result.Result.(string))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	retInfo := ontfs.DecRet(data)
+	if !retInfo.Ret {
+		return nil, fmt.Errorf("%s", retInfo.Info)
+	}
+	var report ontfs.FsAuditReport
+	if err = report.Deserialization(common.NewZeroCopySource(retInfo.Info)); err != nil {
+		return nil, fmt.Errorf("FsAuditReport deserialize error:%s", err)
+	}
+
+	rsp := &FsAuditRsp{
+		ContractBalance: report.ContractBalance,
+		FileRestAmount:  report.FileRestAmount,
+		SpaceRestAmount: report.SpaceRestAmount,
+		ReadPledgeMoney: report.ReadPledgeMoney,
+		NodePledge:      report.NodePledge,
+		NodeProfit:      report.NodeProfit,
+		RenewEscrow:     report.RenewEscrow,
+		TreasuryBalance: report.TreasuryBalance,
+		InsurancePool:   report.InsurancePool,
+		Liabilities:     report.Liabilities,
+		Mismatches:      make([]FsAuditMismatchRsp, 0, len(report.Mismatches)),
+	}
+	for _, mismatch := range report.Mismatches {
+		records := make([]string, 0, len(mismatch.Records))
+		for _, record := range mismatch.Records {
+			if mismatch.Kind == ontfs.AuditBrokenRecord {
+				records = append(records, hex.EncodeToString(record))
+			} else {
+				records = append(records, string(record))
+			}
+		}
+		rsp.Mismatches = append(rsp.Mismatches, FsAuditMismatchRsp{
+			Kind:     mismatch.Kind,
+			Address:  mismatch.Addr.ToBase58(),
+			Expected: mismatch.Expected,
+			Actual:   mismatch.Actual,
+			Records:  records,
+		})
+	}
+	return rsp, nil
+}
+
+// GetFsAudit audit the books of the ontfs contract at the current block
+func GetFsAudit() (*FsAuditRsp, error) {
+	tx, err := NewFsAuditTransaction()
+	if err != nil {
+		return nil, err
+	}
+	result, err := bactor.PreExecuteContract(tx)
+	if err != nil {
+		return nil, fmt.Errorf("PrepareInvokeContract error:%s", err)
+	}
+	return ParseFsAuditResult(result)
+}
+
+// GetFsAuditAtHeight audit the books of the ontfs contract at a past block kept in the state history
+func GetFsAuditAtHeight(height uint32) (*FsAuditRsp, error) {
+	tx, err := NewFsAuditTransaction()
+	if err != nil {
+		return nil, err
+	}
+	result, err := bactor.PreExecuteContractAtHeight(tx, height)
+	if err != nil {
+		return nil, fmt.Errorf("PrepareInvokeContract error:%s", err)
+	}
+	return ParseFsAuditResult(result)
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/ontio/ontology/common"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	"github.com/ontio/ontology/smartcontract/service/native/governance"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+type GovernancePeerRsp struct {
+	Index      uint32 `json:"index"`
+	PeerPubkey string `json:"peerPubkey"`
+	Address    string `json:"address"`
+	Status     uint8  `json:"status"`
+	InitPos    uint64 `json:"initPos"`
+	TotalPos   uint64 `json:"totalPos"`
+}
+
+type GovernancePeerInfoRsp struct {
+	GovernancePeerRsp
+	MaxAuthorize        uint64 `json:"maxAuthorize"`
+	TPeerCost           uint64 `json:"tPeerCost"`
+	T1PeerCost          uint64 `json:"t1PeerCost"`
+	T2PeerCost          uint64 `json:"t2PeerCost"`
+	PromisePos          uint64 `json:"promisePos"`
+	PenaltyInitPos      uint64 `json:"penaltyInitPos"`
+	PenaltyAuthorizePos uint64 `json:"penaltyAuthorizePos"`
+}
+
+type GovernanceAuthorizeRsp struct {
+	PeerPubkey           string `json:"peerPubkey"`
+	ConsensusPos         uint64 `json:"consensusPos"`
+	CandidatePos         uint64 `json:"candidatePos"`
+	NewPos               uint64 `json:"newPos"`
+	WithdrawConsensusPos uint64 `json:"withdrawConsensusPos"`
+	WithdrawCandidatePos uint64 `json:"withdrawCandidatePos"`
+	WithdrawUnfreezePos  uint64 `json:"withdrawUnfreezePos"`
+	PendingPos           uint64 `json:"pendingPos"`
+	WithdrawablePos      uint64 `json:"withdrawablePos"`
+}
+
+type GovernanceViewRsp struct {
+	View               uint32 `json:"view"`
+	Height             uint32 `json:"height"`
+	TxHash             string `json:"txHash"`
+	NextElectionHeight uint32 `json:"nextElectionHeight"`
+}
+
+type GovernanceRewardRsp struct {
+	Address    string `json:"address"`
+	SplitFee   uint64 `json:"splitFee"`
+	UnboundOng uint64 `json:"unboundOng"`
+}
+
+// preExecuteGovernance pre-execute a read only method of the governance contract and return its result
+func preExecuteGovernance(method string, param interface{}) ([]byte, error) {
+	mutable, err := NewNativeInvokeTransaction(0, 0, utils.GovernanceContractAddress, 0, method,
+		[]interface{}{param})
+	if err != nil {
+		return nil, fmt.Errorf("NewNativeInvokeTransaction error:%s", err)
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return nil, err
+	}
+	result, err := bactor.PreExecuteContract(tx)
+	if err != nil {
+		return nil, fmt.Errorf("PrepareInvokeContract error:%s", err)
+	}
+	if result.State == 0 {
+		return nil, fmt.Errorf("prepare invoke failed")
+	}
+	data, err := hex.DecodeString(result.Result.(string))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	return data, nil
+}
+
+func newGovernancePeerRsp(peer *governance.PeerPoolItem) GovernancePeerRsp {
+	return GovernancePeerRsp{
+		Index:      peer.Index,
+		PeerPubkey: peer.PeerPubkey,
+		Address:    peer.Address.ToBase58(),
+		Status:     uint8(peer.Status),
+		InitPos:    peer.InitPos,
+		TotalPos:   peer.TotalPos,
+	}
+}
+
+// GetGovernancePeerPool return the peers of the current view ordered by index
+func GetGovernancePeerPool() ([]GovernancePeerRsp, error) {
+	data, err := preExecuteGovernance(governance.GET_PEER_POOL, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	peerPoolMap := new(governance.PeerPoolMap)
+	if err = peerPoolMap.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("PeerPoolMap deserialize error:%s", err)
+	}
+	rsp := make([]GovernancePeerRsp, 0, len(peerPoolMap.PeerPoolMap))
+	for _, peer := range peerPoolMap.PeerPoolMap {
+		rsp = append(rsp, newGovernancePeerRsp(peer))
+	}
+	sort.Slice(rsp, func(i, j int) bool {
+		return rsp[i].Index < rsp[j].Index
+	})
+	return rsp, nil
+}
+
+// GetGovernancePeerInfo return the state, attributes and cost of one peer of the current view
+func GetGovernancePeerInfo(peerPubkey string) (*GovernancePeerInfoRsp, error) {
+	data, err := preExecuteGovernance(governance.GET_PEER_INFO, &governance.GetPeerInfoParam{
+		PeerPubkey: peerPubkey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	peerInfo := new(governance.PeerInfo)
+	if err = peerInfo.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("PeerInfo deserialize error:%s", err)
+	}
+	return &GovernancePeerInfoRsp{
+		GovernancePeerRsp:   newGovernancePeerRsp(peerInfo.PeerPoolItem),
+		MaxAuthorize:        peerInfo.PeerAttributes.MaxAuthorize,
+		TPeerCost:           peerInfo.PeerAttributes.TPeerCost,
+		T1PeerCost:          peerInfo.PeerAttributes.T1PeerCost,
+		T2PeerCost:          peerInfo.PeerAttributes.T2PeerCost,
+		PromisePos:          peerInfo.PromisePos,
+		PenaltyInitPos:      peerInfo.PenaltyStake.InitPos,
+		PenaltyAuthorizePos: peerInfo.PenaltyStake.AuthorizePos,
+	}, nil
+}
+
+// GetGovernanceAuthorizeInfo return the authorizations of addr to the given peers, or to all peers of the current view
+func GetGovernanceAuthorizeInfo(addr common.Address, peerPubkeys []string) ([]GovernanceAuthorizeRsp, error) {
+	if peerPubkeys == nil {
+		peerPubkeys = []string{}
+	}
+	data, err := preExecuteGovernance(governance.GET_AUTHORIZE_INFO, &governance.GetAuthorizeInfoParam{
+		Address:        addr,
+		PeerPubkeyList: peerPubkeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+	authorizeInfoList := new(governance.AuthorizeInfoList)
+	if err = authorizeInfoList.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("AuthorizeInfoList deserialize error:%s", err)
+	}
+	rsp := make([]GovernanceAuthorizeRsp, 0, len(authorizeInfoList.AuthorizeInfos))
+	for _, info := range authorizeInfoList.AuthorizeInfos {
+		rsp = append(rsp, GovernanceAuthorizeRsp{
+			PeerPubkey:           info.PeerPubkey,
+			ConsensusPos:         info.ConsensusPos,
+			CandidatePos:         info.CandidatePos,
+			NewPos:               info.NewPos,
+			WithdrawConsensusPos: info.WithdrawConsensusPos,
+			WithdrawCandidatePos: info.WithdrawCandidatePos,
+			WithdrawUnfreezePos:  info.WithdrawUnfreezePos,
+			PendingPos:           info.WithdrawConsensusPos + info.WithdrawCandidatePos,
+			WithdrawablePos:      info.WithdrawUnfreezePos,
+		})
+	}
+	return rsp, nil
+}
+
+// GetGovernanceView return the current view of the governance contract and the height of the next election
+func GetGovernanceView() (*GovernanceViewRsp, error) {
+	data, err := preExecuteGovernance(governance.GET_GOVERNANCE_VIEW, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	viewInfo := new(governance.GovernanceViewInfo)
+	if err = viewInfo.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("GovernanceViewInfo deserialize error:%s", err)
+	}
+	return &GovernanceViewRsp{
+		View:               viewInfo.GovernanceView.View,
+		Height:             viewInfo.GovernanceView.Height,
+		TxHash:             viewInfo.GovernanceView.TxHash.ToHexString(),
+		NextElectionHeight: viewInfo.NextElectionHeight,
+	}, nil
+}
+
+// GetGovernanceReward return the ong rewards of addr in the governance contract not withdrawn yet
+func GetGovernanceReward(addr common.Address) (*GovernanceRewardRsp, error) {
+	data, err := preExecuteGovernance(governance.GET_UNCLAIMED_REWARD, &governance.GetUnclaimedRewardParam{
+		Address: addr,
+	})
+	if err != nil {
+		return nil, err
+	}
+	reward := new(governance.UnclaimedReward)
+	if err = reward.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("UnclaimedReward deserialize error:%s", err)
+	}
+	return &GovernanceRewardRsp{
+		Address:    reward.Address.ToBase58(),
+		SplitFee:   reward.SplitFee,
+		UnboundOng: reward.UnboundOng,
+	}, nil
+}
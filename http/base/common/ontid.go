@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ontio/ontology/core/types"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	cstate "github.com/ontio/ontology/smartcontract/states"
+)
+
+// GetDIDDocument resolve the ONT ID to its DID document at the current block, nil if the ID is not registered
+func GetDIDDocument(id string) (*ontid.DIDDocument, error) {
+	tx, err := newDIDDocumentTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := bactor.PreExecuteContract(tx)
+	if err != nil {
+		return nil, fmt.Errorf("PrepareInvokeContract error:%s", err)
+	}
+	return parseDIDDocumentResult(result)
+}
+
+// GetDIDDocumentAtHeight resolve the ONT ID to its DID document at a past block kept in the state history
+func GetDIDDocumentAtHeight(id string, height uint32) (*ontid.DIDDocument, error) {
+	tx, err := newDIDDocumentTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := bactor.PreExecuteContractAtHeight(tx, height)
+	if err != nil {
+		return nil, fmt.Errorf("PrepareInvokeContract error:%s", err)
+	}
+	return parseDIDDocumentResult(result)
+}
+
+func newDIDDocumentTransaction(id string) (*types.Transaction, error) {
+	mutable, err := NewNativeInvokeTransaction(0, 0, utils.OntIDContractAddress, 0, "getDocument",
+		[]interface{}{[]byte(id)})
+	if err != nil {
+		return nil, fmt.Errorf("NewNativeInvokeTransaction error:%s", err)
+	}
+	return mutable.IntoImmutable()
+}
+
+func parseDIDDocumentResult(result *cstate.PreExecResult) (*ontid.DIDDocument, error) {
+	if result.State == 0 {
+		return nil, fmt.Errorf("prepare invoke failed")
+	}
+	data, err := hex.DecodeString(result.Result.(string))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	doc := new(ontid.DIDDocument)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("unmarshal DID document error:%s", err)
+	}
+	return doc, nil
+}
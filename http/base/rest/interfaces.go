@@ -29,8 +29,11 @@ import (
 	bactor "github.com/ontio/ontology/http/base/actor"
 	bcomn "github.com/ontio/ontology/http/base/common"
 	berr "github.com/ontio/ontology/http/base/error"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	cstate "github.com/ontio/ontology/smartcontract/states"
 	"strconv"
+	"strings"
 )
 
 const TLS_PORT int = 443
@@ -264,7 +267,16 @@ func SendRawTransaction(cmd map[string]interface{}) map[string]interface{} {
 	log.Debugf("SendRawTransaction recv %s", hash.ToHexString())
 	if txn.TxType == types.Invoke || txn.TxType == types.Deploy {
 		if preExec, ok := cmd["PreExec"].(string); ok && preExec == "1" {
-			rst, err := bactor.PreExecuteContract(txn)
+			var rst *cstate.PreExecResult
+			if height, ok := cmd["Height"].(string); ok && len(height) > 0 {
+				h, perr := strconv.ParseUint(height, 10, 32)
+				if perr != nil {
+					return ResponsePack(berr.INVALID_PARAMS)
+				}
+				rst, err = bactor.PreExecuteContractAtHeight(txn, uint32(h))
+			} else {
+				rst, err = bactor.PreExecuteContract(txn)
+			}
 			if err != nil {
 				log.Infof("PreExec: ", err)
 				resp = ResponsePack(berr.SMARTCODE_ERROR)
@@ -580,3 +592,107 @@ func GetMemPoolTxState(cmd map[string]interface{}) map[string]interface{} {
 	resp["Result"] = bcomn.TXNEntryInfo{attrs}
 	return resp
 }
+
+//get peers of current governance view
+func GetGovernancePeerPool(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	rsp, err := bcomn.GetGovernancePeerPool()
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+//get state, attributes and cost of a peer
+func GetGovernancePeerInfo(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	peerPubkey, ok := cmd["PeerPubkey"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	rsp, err := bcomn.GetGovernancePeerInfo(peerPubkey)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+//get authorizations of address, to the comma separated peers or to all peers of current view
+func GetGovernanceAuthorizeInfo(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	addrStr, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	addr, err := bcomn.GetAddress(addrStr)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	var peerPubkeys []string
+	if peers, ok := cmd["Peers"].(string); ok && peers != "" {
+		peerPubkeys = strings.Split(peers, ",")
+	}
+	rsp, err := bcomn.GetGovernanceAuthorizeInfo(addr, peerPubkeys)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+//get current governance view and height of next election
+func GetGovernanceView(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	rsp, err := bcomn.GetGovernanceView()
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+//get governance rewards of address not withdrawn yet
+func GetGovernanceReward(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	addrStr, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	addr, err := bcomn.GetAddress(addrStr)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	rsp, err := bcomn.GetGovernanceReward(addr)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+// resolve the ONT ID to its W3C DID document, at the current block or at the optional height
+func GetDIDDocument(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	id, ok := cmd["Id"].(string)
+	if !ok || id == "" {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	var rsp *ontid.DIDDocument
+	var err error
+	if height, ok := cmd["Height"].(string); ok && len(height) > 0 {
+		h, perr := strconv.ParseUint(height, 10, 32)
+		if perr != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		rsp, err = bcomn.GetDIDDocumentAtHeight(id, uint32(h))
+	} else {
+		rsp, err = bcomn.GetDIDDocument(id)
+	}
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = rsp
+	return resp
+}
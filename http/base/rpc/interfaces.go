@@ -31,7 +31,10 @@ import (
 	bactor "github.com/ontio/ontology/http/base/actor"
 	bcomn "github.com/ontio/ontology/http/base/common"
 	berr "github.com/ontio/ontology/http/base/error"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	cstate "github.com/ontio/ontology/smartcontract/states"
+	"math"
 )
 
 //get best block hash
@@ -259,6 +262,8 @@ func GetStorage(params []interface{}) map[string]interface{} {
 //send raw transaction
 // A JSON example for sendrawtransaction method as following:
 //   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex"], "id": 0}
+// A pre-execution against the state at a past height:
+//   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex", 1, height], "id": 0}
 func SendRawTransaction(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return responsePack(berr.INVALID_PARAMS, nil)
@@ -281,7 +286,16 @@ func SendRawTransaction(params []interface{}) map[string]interface{} {
 			if len(params) > 1 {
 				preExec, ok := params[1].(float64)
 				if ok && preExec == 1 {
-					result, err := bactor.PreExecuteContract(txn)
+					var result *cstate.PreExecResult
+					if len(params) > 2 {
+						height, ok := params[2].(float64)
+						if !ok || height < 0 || height > math.MaxUint32 {
+							return responsePack(berr.INVALID_PARAMS, "")
+						}
+						result, err = bactor.PreExecuteContractAtHeight(txn, uint32(height))
+					} else {
+						result, err = bactor.PreExecuteContract(txn)
+					}
 					if err != nil {
 						log.Infof("PreExec: ", err)
 						return responsePack(berr.SMARTCODE_ERROR, err.Error())
@@ -585,3 +599,133 @@ func GetGrantOng(params []interface{}) map[string]interface{} {
 	}
 	return responseSuccess(rsp)
 }
+
+// audit the books of the ontfs contract, at the current block or at the optional height
+func GetFsAudit(params []interface{}) map[string]interface{} {
+	var rsp *bcomn.FsAuditRsp
+	var err error
+	if len(params) > 0 {
+		height, ok := params[0].(float64)
+		if !ok || height < 0 || height > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		rsp, err = bcomn.GetFsAuditAtHeight(uint32(height))
+	} else {
+		rsp, err = bcomn.GetFsAudit()
+	}
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+// get the peers of the current governance view
+func GetGovernancePeerPool(params []interface{}) map[string]interface{} {
+	rsp, err := bcomn.GetGovernancePeerPool()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+// get the state, attributes and cost of a peer
+func GetGovernancePeerInfo(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	peerPubkey, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	rsp, err := bcomn.GetGovernancePeerInfo(peerPubkey)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+// get the authorizations of address, to the optional peers or to all peers of the current view
+// {"jsonrpc": "2.0", "method": "getgovernanceauthorizeinfo", "params": ["address", "peer pubkey", ...], "id": 0}
+func GetGovernanceAuthorizeInfo(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	addr, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	peerPubkeys := make([]string, 0, len(params)-1)
+	for _, param := range params[1:] {
+		peerPubkey, ok := param.(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		peerPubkeys = append(peerPubkeys, peerPubkey)
+	}
+	rsp, err := bcomn.GetGovernanceAuthorizeInfo(addr, peerPubkeys)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+// get the current governance view and the height of the next election
+func GetGovernanceView(params []interface{}) map[string]interface{} {
+	rsp, err := bcomn.GetGovernanceView()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+// get the governance rewards of address not withdrawn yet
+func GetGovernanceReward(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	addr, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	rsp, err := bcomn.GetGovernanceReward(addr)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+// resolve the ONT ID to its W3C DID document, at the current block or at the optional height,
+// the result is null if the ID is not registered
+// {"jsonrpc": "2.0", "method": "getdiddocument", "params": ["did:ont:...", height], "id": 0}
+func GetDIDDocument(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	id, ok := params[0].(string)
+	if !ok || id == "" {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var rsp *ontid.DIDDocument
+	var err error
+	if len(params) > 1 {
+		height, ok := params[1].(float64)
+		if !ok || height < 0 || height > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		rsp, err = bcomn.GetDIDDocumentAtHeight(id, uint32(height))
+	} else {
+		rsp, err = bcomn.GetDIDDocument(id)
+	}
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
@@ -59,6 +59,13 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getgasprice", rpc.GetGasPrice)
 	rpc.HandleFunc("getunboundong", rpc.GetUnboundOng)
 	rpc.HandleFunc("getgrantong", rpc.GetGrantOng)
+	rpc.HandleFunc("getfsaudit", rpc.GetFsAudit)
+	rpc.HandleFunc("getgovernancepeerpool", rpc.GetGovernancePeerPool)
+	rpc.HandleFunc("getgovernancepeerinfo", rpc.GetGovernancePeerInfo)
+	rpc.HandleFunc("getgovernanceauthorizeinfo", rpc.GetGovernanceAuthorizeInfo)
+	rpc.HandleFunc("getgovernanceview", rpc.GetGovernanceView)
+	rpc.HandleFunc("getgovernancereward", rpc.GetGovernanceReward)
+	rpc.HandleFunc("getdiddocument", rpc.GetDIDDocument)
 
 	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
 	if err != nil {
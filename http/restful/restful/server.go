@@ -76,6 +76,13 @@ const (
 	GET_VERSION           = "/api/v1/version"
 	GET_NETWORKID         = "/api/v1/networkid"
 
+	GET_GOVERNANCE_PEERPOOL  = "/api/v1/governance/peerpool"
+	GET_GOVERNANCE_PEERINFO  = "/api/v1/governance/peerinfo/:pubkey"
+	GET_GOVERNANCE_AUTHORIZE = "/api/v1/governance/authorizeinfo/:addr"
+	GET_GOVERNANCE_VIEW      = "/api/v1/governance/view"
+	GET_GOVERNANCE_REWARD    = "/api/v1/governance/reward/:addr"
+	GET_DID_DOCUMENT         = "/api/v1/ontid/document/:id"
+
 	POST_RAW_TX = "/api/v1/transaction"
 )
 
@@ -155,6 +162,13 @@ func (this *restServer) registryMethod() {
 		GET_MEMPOOL_TXSTATE:   {name: "getmempooltxstate", handler: rest.GetMemPoolTxState},
 		GET_VERSION:           {name: "getversion", handler: rest.GetNodeVersion},
 		GET_NETWORKID:         {name: "getnetworkid", handler: rest.GetNetworkId},
+
+		GET_GOVERNANCE_PEERPOOL:  {name: "getgovernancepeerpool", handler: rest.GetGovernancePeerPool},
+		GET_GOVERNANCE_PEERINFO:  {name: "getgovernancepeerinfo", handler: rest.GetGovernancePeerInfo},
+		GET_GOVERNANCE_AUTHORIZE: {name: "getgovernanceauthorizeinfo", handler: rest.GetGovernanceAuthorizeInfo},
+		GET_GOVERNANCE_VIEW:      {name: "getgovernanceview", handler: rest.GetGovernanceView},
+		GET_GOVERNANCE_REWARD:    {name: "getgovernancereward", handler: rest.GetGovernanceReward},
+		GET_DID_DOCUMENT:         {name: "getdiddocument", handler: rest.GetDIDDocument},
 	}
 
 	postMethodMap := map[string]Action{
@@ -197,6 +211,14 @@ func (this *restServer) getPath(url string) string {
 		return GET_GRANTONG
 	} else if strings.Contains(url, strings.TrimRight(GET_MEMPOOL_TXSTATE, ":hash")) {
 		return GET_MEMPOOL_TXSTATE
+	} else if strings.Contains(url, strings.TrimRight(GET_GOVERNANCE_PEERINFO, ":pubkey")) {
+		return GET_GOVERNANCE_PEERINFO
+	} else if strings.Contains(url, strings.TrimRight(GET_GOVERNANCE_AUTHORIZE, ":addr")) {
+		return GET_GOVERNANCE_AUTHORIZE
+	} else if strings.Contains(url, strings.TrimRight(GET_GOVERNANCE_REWARD, ":addr")) {
+		return GET_GOVERNANCE_REWARD
+	} else if strings.Contains(url, strings.TrimRight(GET_DID_DOCUMENT, ":id")) {
+		return GET_DID_DOCUMENT
 	}
 	return url
 }
@@ -219,7 +241,7 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 	case GET_CONTRACT_STATE:
 		req["Hash"], req["Raw"] = getParam(r, "hash"), r.FormValue("raw")
 	case POST_RAW_TX:
-		req["PreExec"] = r.FormValue("preExec")
+		req["PreExec"], req["Height"] = r.FormValue("preExec"), r.FormValue("height")
 	case GET_STORAGE:
 		req["Hash"], req["Key"] = getParam(r, "hash"), getParam(r, "key")
 	case GET_SMTCOCE_EVT_TXS:
@@ -241,6 +263,14 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["Addr"] = getParam(r, "addr")
 	case GET_MEMPOOL_TXSTATE:
 		req["Hash"] = getParam(r, "hash")
+	case GET_GOVERNANCE_PEERINFO:
+		req["PeerPubkey"] = getParam(r, "pubkey")
+	case GET_GOVERNANCE_AUTHORIZE:
+		req["Addr"], req["Peers"] = getParam(r, "addr"), r.FormValue("peers")
+	case GET_GOVERNANCE_REWARD:
+		req["Addr"] = getParam(r, "addr")
+	case GET_DID_DOCUMENT:
+		req["Id"], req["Height"] = getParam(r, "id"), r.FormValue("height")
 	default:
 	}
 	return req
@@ -74,6 +74,9 @@ func setupAPP() *cli.App {
 		cmd.ContractCommand,
 		cmd.ImportCommand,
 		cmd.ExportCommand,
+		cmd.FsAuditCommand,
+		cmd.GovernanceCommand,
+		cmd.OntIdCommand,
 		cmd.TxCommond,
 		cmd.SigTxCommand,
 		cmd.MultiSigAddrCommand,
@@ -86,6 +89,7 @@ func setupAPP() *cli.App {
 		utils.ConfigFlag,
 		utils.LogLevelFlag,
 		utils.DisableEventLogFlag,
+		utils.OntFsHistoryBlocksFlag,
 		utils.DataDirFlag,
 		//account setting
 		utils.WalletFileFlag,
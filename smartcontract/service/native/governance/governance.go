@@ -76,6 +76,11 @@ const (
 	REDUCE_INIT_POS                  = "reduceInitPos"
 	SET_PROMISE_POS                  = "setPromisePos"
 	SET_GAS_ADDRESS                  = "setGasAddress"
+	GET_PEER_POOL                    = "getPeerPool"
+	GET_PEER_INFO                    = "getPeerInfo"
+	GET_AUTHORIZE_INFO               = "getAuthorizeInfo"
+	GET_GOVERNANCE_VIEW              = "getGovernanceView"
+	GET_UNCLAIMED_REWARD             = "getUnclaimedReward"
 
 	//key prefix
 	GLOBAL_PARAM      = "globalParam"
@@ -152,6 +157,15 @@ func RegisterGovernanceContract(native *native.NativeService) {
 	native.Register(TRANSFER_PENALTY, TransferPenalty)
 	native.Register(SET_PROMISE_POS, SetPromisePos)
 	native.Register(SET_GAS_ADDRESS, SetGasAddress)
+
+	//the queries are only served to pre-executed invokes, transactions can't reach them
+	if native.PreExec {
+		native.Register(GET_PEER_POOL, QueryPeerPool)
+		native.Register(GET_PEER_INFO, QueryPeerInfo)
+		native.Register(GET_AUTHORIZE_INFO, QueryAuthorizeInfo)
+		native.Register(GET_GOVERNANCE_VIEW, QueryGovernanceView)
+		native.Register(GET_UNCLAIMED_REWARD, QueryUnclaimedReward)
+	}
 }
 
 //Init governance contract, include vbft config, global param and ontid admin.
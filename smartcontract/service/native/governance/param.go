@@ -1020,3 +1020,91 @@ func (this *GasAddress) Deserialization(source *common.ZeroCopySource) error {
 	this.Address = address
 	return nil
 }
+
+type GetPeerInfoParam struct {
+	PeerPubkey string
+}
+
+func (this *GetPeerInfoParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteString(w, this.PeerPubkey); err != nil {
+		return fmt.Errorf("serialization.WriteString, serialize peerPubkey error: %v", err)
+	}
+	return nil
+}
+
+func (this *GetPeerInfoParam) Deserialize(r io.Reader) error {
+	peerPubkey, err := serialization.ReadString(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadString, deserialize peerPubkey error: %v", err)
+	}
+	this.PeerPubkey = peerPubkey
+	return nil
+}
+
+type GetAuthorizeInfoParam struct {
+	Address        common.Address
+	PeerPubkeyList []string //peers to query, all peers of current view if empty
+}
+
+func (this *GetAuthorizeInfoParam) Serialize(w io.Writer) error {
+	if len(this.PeerPubkeyList) > 1024 {
+		return fmt.Errorf("length of input list > 1024")
+	}
+	if err := serialization.WriteVarBytes(w, this.Address[:]); err != nil {
+		return fmt.Errorf("serialization.WriteVarBytes, serialize address error: %v", err)
+	}
+	if err := utils.WriteVarUint(w, uint64(len(this.PeerPubkeyList))); err != nil {
+		return fmt.Errorf("utils.WriteVarUint, serialize peerPubkeyList length error: %v", err)
+	}
+	for _, v := range this.PeerPubkeyList {
+		if err := serialization.WriteString(w, v); err != nil {
+			return fmt.Errorf("serialization.WriteString, serialize peerPubkey error: %v", err)
+		}
+	}
+	return nil
+}
+
+func (this *GetAuthorizeInfoParam) Deserialize(r io.Reader) error {
+	address, err := utils.ReadAddress(r)
+	if err != nil {
+		return fmt.Errorf("utils.ReadAddress, deserialize address error: %v", err)
+	}
+	n, err := utils.ReadVarUint(r)
+	if err != nil {
+		return fmt.Errorf("utils.ReadVarUint, deserialize peerPubkeyList length error: %v", err)
+	}
+	if n > 1024 {
+		return fmt.Errorf("length of input list > 1024")
+	}
+	peerPubkeyList := make([]string, 0, n)
+	for i := 0; uint64(i) < n; i++ {
+		k, err := serialization.ReadString(r)
+		if err != nil {
+			return fmt.Errorf("serialization.ReadString, deserialize peerPubkey error: %v", err)
+		}
+		peerPubkeyList = append(peerPubkeyList, k)
+	}
+	this.Address = address
+	this.PeerPubkeyList = peerPubkeyList
+	return nil
+}
+
+type GetUnclaimedRewardParam struct {
+	Address common.Address
+}
+
+func (this *GetUnclaimedRewardParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Address[:]); err != nil {
+		return fmt.Errorf("serialization.WriteVarBytes, serialize address error: %v", err)
+	}
+	return nil
+}
+
+func (this *GetUnclaimedRewardParam) Deserialize(r io.Reader) error {
+	address, err := utils.ReadAddress(r)
+	if err != nil {
+		return fmt.Errorf("utils.ReadAddress, deserialize address error: %v", err)
+	}
+	this.Address = address
+	return nil
+}
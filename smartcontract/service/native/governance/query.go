@@ -0,0 +1,231 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package governance
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/constants"
+	cstates "github.com/ontio/ontology/core/states"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+//Get peer pool of current view, include status, init pos and total pos of each peer
+func QueryPeerPool(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	view, err := GetView(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getView, get view error: %v", err)
+	}
+	peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerPoolMap, get peerPoolMap error: %v", err)
+	}
+
+	bf := new(bytes.Buffer)
+	if err := peerPoolMap.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("serialize, serialize peerPoolMap error: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+//Get peer pool item, attributes, promise pos and penalty stake of a peer in current view
+func QueryPeerInfo(native *native.NativeService) ([]byte, error) {
+	params := new(GetPeerInfoParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("deserialize, deserialize getPeerInfoParam error: %v", err)
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	view, err := GetView(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getView, get view error: %v", err)
+	}
+	peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerPoolMap, get peerPoolMap error: %v", err)
+	}
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerInfo, peerPubkey is not in peerPoolMap")
+	}
+
+	peerAttributes, err := getPeerAttributes(native, contract, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerAttributes, get peerAttributes error: %v", err)
+	}
+	promisePos, err := queryPromisePos(native, contract, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("queryPromisePos, get promisePos error: %v", err)
+	}
+	penaltyStake, err := getPenaltyStake(native, contract, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPenaltyStake, get penaltyStake error: %v", err)
+	}
+
+	peerInfo := &PeerInfo{
+		PeerPoolItem:   peerPoolItem,
+		PeerAttributes: peerAttributes,
+		PromisePos:     promisePos,
+		PenaltyStake:   penaltyStake,
+	}
+	bf := new(bytes.Buffer)
+	if err := peerInfo.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("serialize, serialize peerInfo error: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+//Get authorizations of an address to the given peers, or to all peers of current view if none given.
+//Peers the address has nothing authorized to are left out.
+func QueryAuthorizeInfo(native *native.NativeService) ([]byte, error) {
+	params := new(GetAuthorizeInfoParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("deserialize, deserialize getAuthorizeInfoParam error: %v", err)
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	peerPubkeyList := params.PeerPubkeyList
+	if len(peerPubkeyList) == 0 {
+		view, err := GetView(native, contract)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("getView, get view error: %v", err)
+		}
+		peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("getPeerPoolMap, get peerPoolMap error: %v", err)
+		}
+		for peerPubkey := range peerPoolMap.PeerPoolMap {
+			peerPubkeyList = append(peerPubkeyList, peerPubkey)
+		}
+	}
+
+	authorizeInfoList := new(AuthorizeInfoList)
+	for _, peerPubkey := range peerPubkeyList {
+		authorizeInfo, err := getAuthorizeInfo(native, contract, peerPubkey, params.Address)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("getAuthorizeInfo, get authorizeInfo error: %v", err)
+		}
+		if authorizeInfo.ConsensusPos+authorizeInfo.CandidatePos+authorizeInfo.NewPos+
+			authorizeInfo.WithdrawConsensusPos+authorizeInfo.WithdrawCandidatePos+authorizeInfo.WithdrawUnfreezePos == 0 {
+			continue
+		}
+		authorizeInfoList.AuthorizeInfos = append(authorizeInfoList.AuthorizeInfos, authorizeInfo)
+	}
+	sortAuthorizeInfos(authorizeInfoList.AuthorizeInfos)
+
+	bf := new(bytes.Buffer)
+	if err := authorizeInfoList.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("serialize, serialize authorizeInfoList error: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+//Get current governance view and the height of next election
+func QueryGovernanceView(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	governanceView, err := GetGovernanceView(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getGovernanceView, get governanceView error: %v", err)
+	}
+	config, err := getConfig(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getConfig, get config error: %v", err)
+	}
+
+	governanceViewInfo := &GovernanceViewInfo{
+		GovernanceView:     governanceView,
+		NextElectionHeight: governanceView.Height + config.MaxBlockChangeView,
+	}
+	bf := new(bytes.Buffer)
+	if err := governanceViewInfo.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("serialize, serialize governanceViewInfo error: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+//Get ong rewards of an address not withdrawn yet, unbound ong is estimated at current block time
+func QueryUnclaimedReward(native *native.NativeService) ([]byte, error) {
+	params := new(GetUnclaimedRewardParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("deserialize, deserialize getUnclaimedRewardParam error: %v", err)
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	splitFeeAddress, err := getSplitFeeAddress(native, contract, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getSplitFeeAddress, get splitFeeAddress error: %v", err)
+	}
+	totalStake, err := getTotalStake(native, contract, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getTotalStake, get totalStake error: %v", err)
+	}
+	var unboundOng uint64
+	if timeOffset := native.Time - constants.GENESIS_BLOCK_TIMESTAMP; timeOffset > totalStake.TimeOffset {
+		unboundOng = utils.CalcUnbindOng(totalStake.Stake, totalStake.TimeOffset, timeOffset)
+	}
+
+	unclaimedReward := &UnclaimedReward{
+		Address:    params.Address,
+		SplitFee:   splitFeeAddress.Amount,
+		UnboundOng: unboundOng,
+	}
+	bf := new(bytes.Buffer)
+	if err := unclaimedReward.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("serialize, serialize unclaimedReward error: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+//promise pos of peer, 0 if not set
+func queryPromisePos(native *native.NativeService, contract common.Address, peerPubkey string) (uint64, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return 0, fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
+	}
+	promisePosBytes, err := native.CacheDB.Get(utils.ConcatKey(contract, []byte(PROMISE_POS), peerPubkeyPrefix))
+	if err != nil {
+		return 0, fmt.Errorf("get promisePosBytes error: %v", err)
+	}
+	if promisePosBytes == nil {
+		return 0, nil
+	}
+	promisePosStore, err := cstates.GetValueFromRawStorageItem(promisePosBytes)
+	if err != nil {
+		return 0, fmt.Errorf("get value from promisePosBytes err:%v", err)
+	}
+	promisePos := new(PromisePos)
+	if err := promisePos.Deserialize(bytes.NewBuffer(promisePosStore)); err != nil {
+		return 0, fmt.Errorf("deserialize, deserialize promisePos error: %v", err)
+	}
+	return promisePos.PromisePos, nil
+}
+
+func sortAuthorizeInfos(authorizeInfos []*AuthorizeInfo) {
+	sort.SliceStable(authorizeInfos, func(i, j int) bool {
+		return authorizeInfos[i].PeerPubkey < authorizeInfos[j].PeerPubkey
+	})
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package governance
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueries_PreExecOnly(t *testing.T) {
+	queries := []string{GET_PEER_POOL, GET_PEER_INFO, GET_AUTHORIZE_INFO, GET_GOVERNANCE_VIEW, GET_UNCLAIMED_REWARD}
+	service := &native.NativeService{ServiceMap: make(map[string]native.Handler)}
+	RegisterGovernanceContract(service)
+	for _, query := range queries {
+		_, ok := service.ServiceMap[query]
+		assert.False(t, ok, query)
+	}
+
+	service = &native.NativeService{ServiceMap: make(map[string]native.Handler), PreExec: true}
+	RegisterGovernanceContract(service)
+	for _, query := range queries {
+		_, ok := service.ServiceMap[query]
+		assert.True(t, ok, query)
+	}
+}
+
+func TestGetAuthorizeInfoParam(t *testing.T) {
+	param := &GetAuthorizeInfoParam{
+		Address:        common.Address{1, 2, 3},
+		PeerPubkeyList: []string{"02aa", "03bb"},
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, param.Serialize(bf))
+	decoded := new(GetAuthorizeInfoParam)
+	assert.Nil(t, decoded.Deserialize(bytes.NewBuffer(bf.Bytes())))
+	assert.Equal(t, param, decoded)
+
+	param.PeerPubkeyList = []string{}
+	bf.Reset()
+	assert.Nil(t, param.Serialize(bf))
+	decoded = new(GetAuthorizeInfoParam)
+	assert.Nil(t, decoded.Deserialize(bytes.NewBuffer(bf.Bytes())))
+	assert.Equal(t, param, decoded)
+}
+
+func TestPeerInfo(t *testing.T) {
+	peerInfo := &PeerInfo{
+		PeerPoolItem: &PeerPoolItem{
+			Index:      3,
+			PeerPubkey: "02aa",
+			Address:    common.Address{1},
+			Status:     ConsensusStatus,
+			InitPos:    10000,
+			TotalPos:   500,
+		},
+		PeerAttributes: &PeerAttributes{
+			PeerPubkey:   "02aa",
+			MaxAuthorize: 1000,
+			T2PeerCost:   50,
+			T1PeerCost:   60,
+			TPeerCost:    70,
+		},
+		PromisePos:   2000,
+		PenaltyStake: &PenaltyStake{PeerPubkey: "02aa"},
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, peerInfo.Serialize(bf))
+	decoded := new(PeerInfo)
+	assert.Nil(t, decoded.Deserialize(bytes.NewBuffer(bf.Bytes())))
+	assert.Equal(t, peerInfo, decoded)
+}
+
+func TestAuthorizeInfoList(t *testing.T) {
+	list := &AuthorizeInfoList{
+		AuthorizeInfos: []*AuthorizeInfo{
+			{PeerPubkey: "03bb", Address: common.Address{1}, NewPos: 10, WithdrawUnfreezePos: 5},
+			{PeerPubkey: "02aa", Address: common.Address{1}, ConsensusPos: 100},
+		},
+	}
+	sortAuthorizeInfos(list.AuthorizeInfos)
+	assert.Equal(t, "02aa", list.AuthorizeInfos[0].PeerPubkey)
+
+	bf := new(bytes.Buffer)
+	assert.Nil(t, list.Serialize(bf))
+	decoded := new(AuthorizeInfoList)
+	assert.Nil(t, decoded.Deserialize(bytes.NewBuffer(bf.Bytes())))
+	assert.Equal(t, list, decoded)
+}
+
+func TestGovernanceViewInfo(t *testing.T) {
+	viewInfo := &GovernanceViewInfo{
+		GovernanceView:     &GovernanceView{View: 5, Height: 1200, TxHash: common.Uint256{9}},
+		NextElectionHeight: 121200,
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, viewInfo.Serialize(bf))
+	decoded := new(GovernanceViewInfo)
+	assert.Nil(t, decoded.Deserialize(bytes.NewBuffer(bf.Bytes())))
+	assert.Equal(t, viewInfo, decoded)
+}
+
+func TestUnclaimedReward(t *testing.T) {
+	reward := &UnclaimedReward{Address: common.Address{7}, SplitFee: 300, UnboundOng: 42}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, reward.Serialize(bf))
+	decoded := new(UnclaimedReward)
+	assert.Nil(t, decoded.Deserialize(bytes.NewBuffer(bf.Bytes())))
+	assert.Equal(t, reward, decoded)
+}
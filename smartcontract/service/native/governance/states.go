@@ -547,3 +547,150 @@ func (this *SplitFeeAddress) Deserialize(r io.Reader) error {
 	this.Amount = amount
 	return nil
 }
+
+type PeerInfo struct { //query result of one peer
+	PeerPoolItem   *PeerPoolItem
+	PeerAttributes *PeerAttributes
+	PromisePos     uint64
+	PenaltyStake   *PenaltyStake
+}
+
+func (this *PeerInfo) Serialize(w io.Writer) error {
+	if err := this.PeerPoolItem.Serialize(w); err != nil {
+		return fmt.Errorf("peerPoolItem.Serialize, serialize peerPoolItem error: %v", err)
+	}
+	if err := this.PeerAttributes.Serialize(w); err != nil {
+		return fmt.Errorf("peerAttributes.Serialize, serialize peerAttributes error: %v", err)
+	}
+	if err := serialization.WriteUint64(w, this.PromisePos); err != nil {
+		return fmt.Errorf("serialization.WriteUint64, serialize promisePos error: %v", err)
+	}
+	if err := this.PenaltyStake.Serialize(w); err != nil {
+		return fmt.Errorf("penaltyStake.Serialize, serialize penaltyStake error: %v", err)
+	}
+	return nil
+}
+
+func (this *PeerInfo) Deserialize(r io.Reader) error {
+	peerPoolItem := new(PeerPoolItem)
+	if err := peerPoolItem.Deserialize(r); err != nil {
+		return fmt.Errorf("peerPoolItem.Deserialize, deserialize peerPoolItem error: %v", err)
+	}
+	peerAttributes := new(PeerAttributes)
+	if err := peerAttributes.Deserialize(r); err != nil {
+		return fmt.Errorf("peerAttributes.Deserialize, deserialize peerAttributes error: %v", err)
+	}
+	promisePos, err := serialization.ReadUint64(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize promisePos error: %v", err)
+	}
+	penaltyStake := new(PenaltyStake)
+	if err := penaltyStake.Deserialize(r); err != nil {
+		return fmt.Errorf("penaltyStake.Deserialize, deserialize penaltyStake error: %v", err)
+	}
+	this.PeerPoolItem = peerPoolItem
+	this.PeerAttributes = peerAttributes
+	this.PromisePos = promisePos
+	this.PenaltyStake = penaltyStake
+	return nil
+}
+
+type AuthorizeInfoList struct { //query result of an address's authorizations
+	AuthorizeInfos []*AuthorizeInfo
+}
+
+func (this *AuthorizeInfoList) Serialize(w io.Writer) error {
+	if err := serialization.WriteUint32(w, uint32(len(this.AuthorizeInfos))); err != nil {
+		return fmt.Errorf("serialization.WriteUint32, serialize authorizeInfos length error: %v", err)
+	}
+	for _, v := range this.AuthorizeInfos {
+		if err := v.Serialize(w); err != nil {
+			return fmt.Errorf("serialize authorizeInfo error: %v", err)
+		}
+	}
+	return nil
+}
+
+func (this *AuthorizeInfoList) Deserialize(r io.Reader) error {
+	n, err := serialization.ReadUint32(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint32, deserialize authorizeInfos length error: %v", err)
+	}
+	authorizeInfos := make([]*AuthorizeInfo, 0)
+	for i := 0; uint32(i) < n; i++ {
+		authorizeInfo := new(AuthorizeInfo)
+		if err := authorizeInfo.Deserialize(r); err != nil {
+			return fmt.Errorf("deserialize authorizeInfo error: %v", err)
+		}
+		authorizeInfos = append(authorizeInfos, authorizeInfo)
+	}
+	this.AuthorizeInfos = authorizeInfos
+	return nil
+}
+
+type GovernanceViewInfo struct { //query result of current view
+	GovernanceView     *GovernanceView
+	NextElectionHeight uint32 //height from which commitDpos can be triggered without admin
+}
+
+func (this *GovernanceViewInfo) Serialize(w io.Writer) error {
+	if err := this.GovernanceView.Serialize(w); err != nil {
+		return fmt.Errorf("governanceView.Serialize, serialize governanceView error: %v", err)
+	}
+	if err := serialization.WriteUint32(w, this.NextElectionHeight); err != nil {
+		return fmt.Errorf("serialization.WriteUint32, serialize nextElectionHeight error: %v", err)
+	}
+	return nil
+}
+
+func (this *GovernanceViewInfo) Deserialize(r io.Reader) error {
+	governanceView := new(GovernanceView)
+	if err := governanceView.Deserialize(r); err != nil {
+		return fmt.Errorf("governanceView.Deserialize, deserialize governanceView error: %v", err)
+	}
+	nextElectionHeight, err := serialization.ReadUint32(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint32, deserialize nextElectionHeight error: %v", err)
+	}
+	this.GovernanceView = governanceView
+	this.NextElectionHeight = nextElectionHeight
+	return nil
+}
+
+type UnclaimedReward struct { //query result of an address's rewards not withdrawn yet
+	Address    common.Address
+	SplitFee   uint64 //ong split to address, withdrawn by withdrawFee
+	UnboundOng uint64 //estimated ong unbound by total stake, withdrawn by withdrawOng
+}
+
+func (this *UnclaimedReward) Serialize(w io.Writer) error {
+	if err := this.Address.Serialize(w); err != nil {
+		return fmt.Errorf("address.Serialize, serialize address error: %v", err)
+	}
+	if err := serialization.WriteUint64(w, this.SplitFee); err != nil {
+		return fmt.Errorf("serialization.WriteUint64, serialize splitFee error: %v", err)
+	}
+	if err := serialization.WriteUint64(w, this.UnboundOng); err != nil {
+		return fmt.Errorf("serialization.WriteUint64, serialize unboundOng error: %v", err)
+	}
+	return nil
+}
+
+func (this *UnclaimedReward) Deserialize(r io.Reader) error {
+	address := new(common.Address)
+	if err := address.Deserialize(r); err != nil {
+		return fmt.Errorf("address.Deserialize, deserialize address error: %v", err)
+	}
+	splitFee, err := serialization.ReadUint64(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize splitFee error: %v", err)
+	}
+	unboundOng, err := serialization.ReadUint64(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize unboundOng error: %v", err)
+	}
+	this.Address = *address
+	this.SplitFee = splitFee
+	this.UnboundOng = unboundOng
+	return nil
+}
@@ -19,8 +19,10 @@
 package ontfs
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/ontio/ontology/common"
@@ -38,6 +40,18 @@ func FsGetNodeInfoList(native *native.NativeService) ([]byte, error) {
 	if err != nil {
 		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsGetNodeInfoList DecodeVarBytes error!")
 	}
+	//the filter is optional
+	var filter NodeFilter
+	if source.Len() != 0 {
+		if err = filter.Deserialization(source); err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsGetNodeInfoList NodeFilter Deserialization error!")
+		}
+	}
+
+	globalParam, err := getGlobalParam(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsGetNodeInfoList getGlobalParam error!")
+	}
 
 	nodeList := getNodeAddrList(native)
 	if nodeList != nil {
@@ -47,13 +61,29 @@ func FsGetNodeInfoList(native *native.NativeService) ([]byte, error) {
 		})
 	}
 
+	var candidates []FsNodeInfo
 	for _, addr := range nodeList {
 		nodeInfo := getNodeInfo(native, addr)
 		if nodeInfo == nil {
 			fmt.Errorf("[APP SDK] FsGetNodeInfoList getNodeInfo(%v) error", addr)
 			continue
 		}
-		nodesInfoList.NodesInfo = append(nodesInfoList.NodesInfo, *nodeInfo)
+		nodeInfo.Reputation.refresh(uint64(native.Time))
+		nodeInfo.Metadata.refresh(uint64(native.Time))
+		//a slashed node takes no new file until it pledges again
+		if !filter.match(nodeInfo) || nodeUnderPledged(nodeInfo, globalParam) {
+			continue
+		}
+		updateProfitVesting(native, nodeInfo)
+		candidates = append(candidates, *nodeInfo)
+	}
+	//better reputation first, the shuffle above breaks ties
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Reputation.Score > candidates[j].Reputation.Score
+	})
+
+	for _, nodeInfo := range candidates {
+		nodesInfoList.NodesInfo = append(nodesInfoList.NodesInfo, nodeInfo)
 		count--
 		if count <= 0 {
 			break
@@ -116,12 +146,14 @@ func FsCreateSpace(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCreateSpace AppCallTransfer, transfer error!")
 	}
 	addSpaceInfo(native, &spaceInfo)
+
+	funding := &SpaceFunding{SpaceOwner: spaceInfo.SpaceOwner}
+	funding.fund(spaceInfo.SpaceOwner, spaceInfo.PayAmount)
+	addSpaceFunding(native, funding)
 	return utils.BYTE_TRUE, nil
 }
 
 func FsDeleteSpace(native *native.NativeService) ([]byte, error) {
-	contract := native.ContextRef.CurrentContext().ContractAddress
-
 	source := common.NewZeroCopySource(native.Input)
 	spaceOwner, err := utils.DecodeAddress(source)
 	if err != nil {
@@ -140,18 +172,26 @@ func FsDeleteSpace(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsDeleteSpace not allow, check files!")
 	}
 
-	err = appCallTransfer(native, utils.OngContractAddress, contract, space.SpaceOwner, space.RestAmount)
+	funding, err := getSpaceFunding(native, space)
 	if err != nil {
-		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsDeleteSpace AppCallTransfer, transfer error!")
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsDeleteSpace getSpaceFunding error!")
+	}
+	if err = refundSpaceFunding(native, funding, space.RestAmount); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsDeleteSpace refundSpaceFunding error!")
+	}
+
+	if err = refundRenewEscrow(native, spaceOwner, nil); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsDeleteSpace refundRenewEscrow error!")
 	}
 
 	delSpaceInfo(native, spaceOwner)
+	delSpaceFunding(native, spaceOwner)
 	return utils.BYTE_TRUE, nil
 }
 
+// FsUpdateSpace changes the volume and the expiry of a space on behalf of its owner. The payer pays
+// for an increase, a decrease is refunded to the funders of the space.
 func FsUpdateSpace(native *native.NativeService) ([]byte, error) {
-	contract := native.ContextRef.CurrentContext().ContractAddress
-
 	var spaceUpdate SpaceUpdate
 	spaceUpdateSrc := common.NewZeroCopySource(native.Input)
 	spaceInfoData, err := DecodeVarBytes(spaceUpdateSrc)
@@ -168,26 +208,65 @@ func FsUpdateSpace(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace Param error!")
 	}
 
+	if !native.ContextRef.CheckWitness(spaceUpdate.SpaceOwner) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace CheckSpaceOwner failed!")
+	}
+
+	if err = updateSpace(native, &spaceUpdate, false); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace " + err.Error())
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// FsTopUpSpace adds volume or time to a space on behalf of any payer, it never reduces the space
+func FsTopUpSpace(native *native.NativeService) ([]byte, error) {
+	var spaceUpdate SpaceUpdate
+	spaceUpdateSrc := common.NewZeroCopySource(native.Input)
+	spaceInfoData, err := DecodeVarBytes(spaceUpdateSrc)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTopUpSpace DecodeVarBytes error!")
+	}
+
+	source := common.NewZeroCopySource(spaceInfoData)
+	if err := spaceUpdate.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTopUpSpace Deserialization error!")
+	}
+
+	if spaceUpdate.NewTimeExpired == 0 && spaceUpdate.NewVolume == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTopUpSpace Param error!")
+	}
+
 	if !native.ContextRef.CheckWitness(spaceUpdate.Payer) {
-		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace CheckPayer failed!")
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTopUpSpace CheckPayer failed!")
 	}
 
+	if err = updateSpace(native, &spaceUpdate, true); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTopUpSpace " + err.Error())
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// updateSpace applies spaceUpdate to the space of its owner, a top up may only add volume or time.
+// An increase is paid by the payer and recorded in the funding ledger of the space.
+func updateSpace(native *native.NativeService, spaceUpdate *SpaceUpdate, topUp bool) error {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
 	globalParam, err := getGlobalParam(native)
 	if err != nil {
-		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace getGlobalParam error!")
+		return fmt.Errorf("getGlobalParam error!")
 	}
 
 	space := getAndUpdateSpaceInfo(native, spaceUpdate.SpaceOwner)
 	if space == nil {
-		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace getSpaceRawInfo error!")
+		return fmt.Errorf("getSpaceRawInfo error!")
 	}
 
 	if !space.ValidFlag {
-		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace space timeExpired! please create space again")
+		return fmt.Errorf("space timeExpired! please create space again")
 	}
 
 	if spaceUpdate.NewTimeExpired != 0 && uint64(native.Time) >= spaceUpdate.NewTimeExpired {
-		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace NewTimeExpired error!")
+		return fmt.Errorf("NewTimeExpired error!")
 	}
 
 	if spaceUpdate.NewTimeExpired == 0 {
@@ -198,45 +277,44 @@ func FsUpdateSpace(native *native.NativeService) ([]byte, error) {
 		spaceUpdate.NewVolume = space.Volume
 	}
 
-	if space.Volume-space.RestVol >= spaceUpdate.NewVolume {
-		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace NewVolume is not enough!")
+	if topUp {
+		if spaceUpdate.NewVolume < space.Volume || spaceUpdate.NewTimeExpired < space.TimeExpired {
+			return fmt.Errorf("top up can not reduce the space!")
+		}
+		if spaceUpdate.NewVolume == space.Volume && spaceUpdate.NewTimeExpired == space.TimeExpired {
+			return fmt.Errorf("top up adds nothing!")
+		}
 	}
 
-	newSpacePdpNeedCount := (spaceUpdate.NewTimeExpired-space.TimeStart)/space.PdpInterval + 1
-	newPayAmount := newSpacePdpNeedCount * spaceUpdate.NewVolume * space.CopyNumber * globalParam.GasPerKbForSaveWithSpace
+	funding, err := getSpaceFunding(native, space)
+	if err != nil {
+		return fmt.Errorf("getSpaceFunding error!")
+	}
 
-	var newFee uint64
-	var payer, payee common.Address
-	if newPayAmount > space.PayAmount {
-		newFee = newPayAmount - space.PayAmount
-		payer = spaceUpdate.Payer
-		payee = contract
-		space.RestAmount += newFee
-	} else if newPayAmount < space.PayAmount {
-		newFee = space.PayAmount - newPayAmount
-		payee = spaceUpdate.Payer
-		payer = contract
-		if space.RestAmount < newFee {
-			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace space RestAmount < newFee error!")
-		}
-		space.RestAmount -= newFee
-	} else {
-		newFee = 0
+	newFee, refund, err := updateSpaceInfo(space, spaceUpdate.NewVolume, spaceUpdate.NewTimeExpired,
+		globalParam.GasPerKbForSaveWithSpace)
+	if err != nil {
+		return err
 	}
-	space.PayAmount = newPayAmount
-	space.RestVol = spaceUpdate.NewVolume - (space.Volume - space.RestVol)
-	space.Volume = spaceUpdate.NewVolume
-	space.TimeExpired = spaceUpdate.NewTimeExpired
 
-	if newFee != 0 {
-		err = appCallTransfer(native, utils.OngContractAddress, payer, payee, newFee)
+	if refund {
+		if err = refundSpaceFunding(native, funding, newFee); err != nil {
+			return fmt.Errorf("refundSpaceFunding error: %s", err.Error())
+		}
+	} else if newFee != 0 {
+		if !native.ContextRef.CheckWitness(spaceUpdate.Payer) {
+			return fmt.Errorf("CheckPayer failed!")
+		}
+		err = appCallTransfer(native, utils.OngContractAddress, spaceUpdate.Payer, contract, newFee)
 		if err != nil {
-			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateSpace AppCallTransfer, transfer error!")
+			return fmt.Errorf("AppCallTransfer, transfer error!")
 		}
+		funding.fund(spaceUpdate.Payer, newFee)
 	}
 
 	addSpaceInfo(native, space)
-	return utils.BYTE_TRUE, nil
+	addSpaceFunding(native, funding)
+	return nil
 }
 
 func FsGetSpaceInfo(native *native.NativeService) ([]byte, error) {
@@ -254,6 +332,28 @@ func FsGetSpaceInfo(native *native.NativeService) ([]byte, error) {
 	return EncRet(true, spaceInfo), nil
 }
 
+func FsGetSpaceFunding(native *native.NativeService) ([]byte, error) {
+	source := common.NewZeroCopySource(native.Input)
+	spaceOwner, err := utils.DecodeAddress(source)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetSpaceFunding DecodeAddress error!")), nil
+	}
+
+	space := getSpaceInfoFromDb(native, spaceOwner)
+	if space == nil {
+		return EncRet(false, []byte("[APP SDK] FsGetSpaceFunding getSpaceInfoFromDb error!")), nil
+	}
+
+	funding, err := getSpaceFunding(native, space)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetSpaceFunding getSpaceFunding error!")), nil
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	funding.Serialization(sink)
+	return EncRet(true, sink.Bytes()), nil
+}
+
 func FsStoreFiles(native *native.NativeService) ([]byte, error) {
 	contract := native.ContextRef.CurrentContext().ContractAddress
 
@@ -282,6 +382,24 @@ func FsStoreFiles(native *native.NativeService) ([]byte, error) {
 			continue
 		}
 
+		if _, err := ParseFileCid(fileInfo.FileHash); err != nil {
+			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles FileHash is not a valid cid!")
+			log.Errorf("[APP SDK] FsStoreFiles ParseFileCid error: %s", err.Error())
+			continue
+		}
+
+		if owner, err := getFileOwner(native, fileInfo.FileHash); err == nil && owner != fileInfo.FileOwner {
+			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles FileHash stored by another owner!")
+			log.Error("[APP SDK] FsStoreFiles FileHash stored by another owner!")
+			continue
+		}
+
+		if len(fileInfo.PdpParam) == 0 {
+			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles PdpParam is empty!")
+			log.Error("[APP SDK] FsStoreFiles PdpParam is empty!")
+			continue
+		}
+
 		if fileInfo.PdpInterval == 0 {
 			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles PdpInterval equals zero!")
 			log.Error("[APP SDK] FsStoreFiles PdpInterval equals zero!")
@@ -294,6 +412,15 @@ func FsStoreFiles(native *native.NativeService) ([]byte, error) {
 			continue
 		}
 
+		if fileInfo.BlockSize == 0 {
+			fileInfo.BlockSize = DefaultPerBlockSize
+		}
+		if fileInfo.BlockSize < globalParam.MinPerBlockSize || fileInfo.BlockSize > globalParam.MaxPerBlockSize {
+			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles BlockSize out of range!")
+			log.Error("[APP SDK] FsStoreFiles BlockSize out of range!")
+			continue
+		}
+
 		if fileExist := getAndUpdateFileInfo(native, fileInfo.FileOwner, fileInfo.FileHash); fileExist != nil {
 			if !fileExist.ValidFlag {
 				log.Debug("[APP SDK] FsStoreFiles Delete old fileInfo")
@@ -323,11 +450,11 @@ func FsStoreFiles(native *native.NativeService) ([]byte, error) {
 				errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles space timeExpired!")
 				continue
 			}
-			if space.RestVol <= fileInfo.FileBlockCount*DefaultPerBlockSize {
+			if space.RestVol <= fileVolume(&fileInfo) {
 				errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles RestVol is not enough error!")
 				continue
 			}
-			space.RestVol -= fileInfo.FileBlockCount * DefaultPerBlockSize
+			space.RestVol -= fileVolume(&fileInfo)
 			fileInfo.PdpInterval = space.PdpInterval
 			addSpaceInfo(native, space)
 		} else if fileInfo.StorageType == FileStorageTypeUseFile {
@@ -391,22 +518,17 @@ func FsRenewFiles(native *native.NativeService) ([]byte, error) {
 				continue
 			}
 
-			fileInfo.TimeExpired = fileReNew.NewTimeExpired
-			newFee := calcTotalFilePayAmountByFile(fileInfo, globalParam.GasPerKbForSaveWithFile)
-			if newFee < fileInfo.PayAmount {
-				errInfos.AddObjectError(string(fileReNew.FileHash), "[APP SDK] FsRenewFiles newFee < fileInfo.PayAmount")
+			renewFee, err := renewFileInfo(fileInfo, fileReNew.NewTimeExpired, globalParam.GasPerKbForSaveWithFile)
+			if err != nil {
+				errInfos.AddObjectError(string(fileReNew.FileHash), "[APP SDK] FsRenewFiles "+err.Error())
 				continue
 			}
 
-			renewFee := newFee - fileInfo.PayAmount
 			err = appCallTransfer(native, utils.OngContractAddress, fileReNew.Payer, contract, renewFee)
 			if err != nil {
 				errInfos.AddObjectError(string(fileReNew.FileHash), "[APP SDK] FsRenewFiles AppCallTransfer, transfer error!")
 				continue
 			}
-
-			fileInfo.PayAmount = newFee
-			fileInfo.RestAmount = fileInfo.RestAmount + renewFee
 			addFileInfo(native, fileInfo)
 		} else {
 			errInfos.AddObjectError(string(fileReNew.FileHash), "[APP SDK] FsRenewFiles StorageType is not FileStorageTypeUseFile!")
@@ -498,7 +620,9 @@ func deleteFile(native *native.NativeService, fileInfo *FileInfo, errInfos *Erro
 		}
 
 		if !pdpRecord.SettleFlag {
-			nodeInfo.RestVol += fileInfo.FileBlockCount * DefaultPerBlockSize
+			nodeInfo.RestVol += fileVolume(fileInfo)
+			nodeInfo.Reputation.addMissedWindows(uint64(native.Time),
+				countRecordMissedWindows(fileInfo, &pdpRecord, uint64(native.Time)))
 			addNodeInfo(native, nodeInfo)
 			pdpRecord.SettleFlag = true
 		}
@@ -516,19 +640,64 @@ func deleteFile(native *native.NativeService, fileInfo *FileInfo, errInfos *Erro
 			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] DeleteFile getAndUpdateSpaceInfo error!")
 			return false
 		}
-		space.RestVol += fileInfo.FileBlockCount * DefaultPerBlockSize
+		space.RestVol += fileVolume(fileInfo)
 		addSpaceInfo(native, space)
 	} else {
 		errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] DeleteFile file StorageType error")
 		return false
 	}
 
+	if err := refundRenewEscrow(native, fileInfo.FileOwner, fileInfo.FileHash); err != nil {
+		errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] DeleteFile refundRenewEscrow error!")
+		return false
+	}
+
 	delFileInfo(native, fileInfo.FileOwner, fileInfo.FileHash)
 	delFileOwner(native, fileInfo.FileHash)
 	delPdpRecordList(native, fileInfo.FileHash, fileInfo.FileOwner)
+	delKeyEnvelopeList(native, fileInfo.FileOwner, fileInfo.FileHash)
 	return true
 }
 
+// FsClaimFileLoss compensates the owner of a file no node proved for FileLostWindows pdp windows
+// from the insurance pool, after slashing the nodes which lost it, and deletes the file.
+func FsClaimFileLoss(native *native.NativeService) ([]byte, error) {
+	var errInfos Errors
+	source := common.NewZeroCopySource(native.Input)
+	fileHash, err := DecodeVarBytes(source)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsClaimFileLoss DecodeVarBytes error!")
+	}
+
+	fileInfo := getFileInfoByHash(native, fileHash)
+	if fileInfo == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsClaimFileLoss fileInfo is nil")
+	}
+	if !native.ContextRef.CheckWitness(fileInfo.FileOwner) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsClaimFileLoss CheckFileOwner failed!")
+	}
+
+	globalParam, err := getGlobalParam(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsClaimFileLoss getGlobalParam error!")
+	}
+
+	nodes := lostFileNodes(native, fileInfo)
+	if len(nodes) == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsClaimFileLoss file is not lost!")
+	}
+	slashed, compensation, err := claimFileLoss(native, fileInfo, nodes, globalParam)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsClaimFileLoss claimFileLoss " + err.Error())
+	}
+	if !deleteFile(native, fileInfo, &errInfos) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsClaimFileLoss deleteFile error!")
+	}
+
+	addFileLossClaimedEvent(native, fileInfo, slashed, compensation)
+	return utils.BYTE_TRUE, nil
+}
+
 func FsTransferFiles(native *native.NativeService) ([]byte, error) {
 	//Note: May cause storage node not to find PdpInfo, so when an error occurs,
 	//the storage node needs to try to commit more than once
@@ -572,6 +741,14 @@ func FsTransferFiles(native *native.NativeService) ([]byte, error) {
 			continue
 		}
 
+		if err = refundRenewEscrow(native, fileTransfer.OriOwner, fileTransfer.FileHash); err != nil {
+			errInfos.AddObjectError(string(fileTransfer.FileHash), "[APP SDK] FsTransferFiles refundRenewEscrow error!")
+			continue
+		}
+
+		//envelopes were posted by the old owner, the new owner has to share the key again
+		delKeyEnvelopeList(native, fileTransfer.OriOwner, fileTransfer.FileHash)
+
 		fileInfo.FileOwner = fileTransfer.NewOwner
 		delFileInfo(native, fileTransfer.OriOwner, fileTransfer.FileHash)
 		addFileInfo(native, fileInfo)
@@ -597,7 +774,7 @@ func FsGetFileHashList(native *native.NativeService) ([]byte, error) {
 		return EncRet(false, []byte("[APP SDK] FsGetFileHashList DecodeVarBytes error!")), nil
 	}
 
-	walletAddr, err := CheckPassport(uint64(native.Height), passportData)
+	walletAddr, err := CheckPassport(native, FS_GET_FILE_LIST, nil, passportData)
 	if err != nil {
 		errInfo := fmt.Sprintf("[APP SDK] FsGetFileHashList CheckFileListOwner error: %s", err.Error())
 		return EncRet(false, []byte(errInfo)), nil
@@ -626,6 +803,24 @@ func FsGetFileInfo(native *native.NativeService) ([]byte, error) {
 	return EncRet(true, fileRawInfo), nil
 }
 
+func FsGetFileCid(native *native.NativeService) ([]byte, error) {
+	source := common.NewZeroCopySource(native.Input)
+	fileHash, err := DecodeVarBytes(source)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetFileCid DecodeBytes error!")), nil
+	}
+
+	cid, err := ParseFileCid(fileHash)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetFileCid "+err.Error())), nil
+	}
+
+	cidInfo := FileCidInfo{FileHash: fileHash, Cid: *cid}
+	sink := common.NewZeroCopySink(nil)
+	cidInfo.Serialization(sink)
+	return EncRet(true, sink.Bytes()), nil
+}
+
 func FsGetPdpInfoList(native *native.NativeService) ([]byte, error) {
 	source := common.NewZeroCopySource(native.Input)
 	fileHash, err := DecodeVarBytes(source)
@@ -670,6 +865,131 @@ func FsGetWhiteList(native *native.NativeService) ([]byte, error) {
 	return EncRet(true, rawWhiteList), nil
 }
 
+func FsSetKeyEnvelopes(native *native.NativeService) ([]byte, error) {
+	var errInfos Errors
+	var envelopeList KeyEnvelopeList
+	envelopeListSrc := common.NewZeroCopySource(native.Input)
+	envelopeListData, err := DecodeVarBytes(envelopeListSrc)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetKeyEnvelopes DecodeVarBytes error!")
+	}
+
+	source := common.NewZeroCopySource(envelopeListData)
+	if err := envelopeList.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetKeyEnvelopes Deserialization error!")
+	}
+
+	for _, envelope := range envelopeList.Envelopes {
+		if !native.ContextRef.CheckWitness(envelope.FileOwner) {
+			errInfos.AddObjectError(string(envelope.FileHash), "[APP SDK] FsSetKeyEnvelopes CheckFileOwner failed!")
+			continue
+		}
+		if err = checkKeyEnvelope(native, &envelope); err != nil {
+			errInfos.AddObjectError(string(envelope.FileHash), "[APP SDK] FsSetKeyEnvelopes checkKeyEnvelope error: "+err.Error())
+			continue
+		}
+		oldEnvelope := getKeyEnvelope(native, envelope.FileOwner, envelope.FileHash, envelope.Reader)
+		if oldEnvelope != nil && oldEnvelope.KeyVersion > envelope.KeyVersion {
+			errInfos.AddObjectError(string(envelope.FileHash), "[APP SDK] FsSetKeyEnvelopes KeyVersion is out of date!")
+			continue
+		}
+		addKeyEnvelope(native, &envelope)
+	}
+
+	errInfos.AddErrorsEvent(native)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsRevokeKeyEnvelopes(native *native.NativeService) ([]byte, error) {
+	var revoke KeyEnvelopeRevoke
+	revokeSrc := common.NewZeroCopySource(native.Input)
+	revokeData, err := DecodeVarBytes(revokeSrc)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsRevokeKeyEnvelopes DecodeVarBytes error!")
+	}
+
+	source := common.NewZeroCopySource(revokeData)
+	if err := revoke.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsRevokeKeyEnvelopes Deserialization error!")
+	}
+
+	if !native.ContextRef.CheckWitness(revoke.FileOwner) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsRevokeKeyEnvelopes CheckFileOwner failed!")
+	}
+	if getFileInfoFromDb(native, revoke.FileOwner, revoke.FileHash) == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsRevokeKeyEnvelopes getFileInfoFromDb error!")
+	}
+
+	revoked := make(map[common.Address]bool)
+	for _, reader := range revoke.Readers {
+		revoked[reader] = true
+	}
+
+	if len(revoke.NewEnvelopes) == 0 {
+		for _, reader := range revoke.Readers {
+			delKeyEnvelope(native, revoke.FileOwner, revoke.FileHash, reader)
+		}
+		return utils.BYTE_TRUE, nil
+	}
+
+	//rotate the file key, envelopes of the old key are all dropped
+	var keyVersion uint64
+	for _, envelope := range getKeyEnvelopeList(native, revoke.FileOwner, revoke.FileHash) {
+		if envelope.KeyVersion > keyVersion {
+			keyVersion = envelope.KeyVersion
+		}
+	}
+	for _, envelope := range revoke.NewEnvelopes {
+		if envelope.FileOwner != revoke.FileOwner || !bytes.Equal(envelope.FileHash, revoke.FileHash) {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsRevokeKeyEnvelopes NewEnvelopes file not match!")
+		}
+		if envelope.KeyVersion <= keyVersion {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsRevokeKeyEnvelopes NewEnvelopes KeyVersion not rotated!")
+		}
+		if revoked[envelope.Reader] {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsRevokeKeyEnvelopes NewEnvelopes contains revoked reader!")
+		}
+		if err = checkKeyEnvelope(native, &envelope); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("[APP SDK] FsRevokeKeyEnvelopes checkKeyEnvelope error: %s", err.Error())
+		}
+	}
+
+	delKeyEnvelopeList(native, revoke.FileOwner, revoke.FileHash)
+	for _, envelope := range revoke.NewEnvelopes {
+		addKeyEnvelope(native, &envelope)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func FsGetKeyEnvelope(native *native.NativeService) ([]byte, error) {
+	var query KeyEnvelopeQuery
+	source := common.NewZeroCopySource(native.Input)
+	if err := query.Deserialization(source); err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetKeyEnvelope Deserialization error!")), nil
+	}
+
+	reader, err := CheckPassport(native, FS_GET_KEY_ENVELOPE, query.FileHash, query.Passport)
+	if err != nil {
+		errInfo := fmt.Sprintf("[APP SDK] FsGetKeyEnvelope CheckPassport error: %s", err.Error())
+		return EncRet(false, []byte(errInfo)), nil
+	}
+
+	owner, err := getFileOwner(native, query.FileHash)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetKeyEnvelope getFileOwner error!")), nil
+	}
+
+	if reader != owner && !inWhiteList(getWhiteList(native, owner, query.FileHash), reader) {
+		return EncRet(false, []byte("[APP SDK] FsGetKeyEnvelope reader is not in white list!")), nil
+	}
+
+	rawEnvelope := getRawKeyEnvelope(native, owner, query.FileHash, reader)
+	if rawEnvelope == nil {
+		return EncRet(false, []byte("[APP SDK] FsGetKeyEnvelope getRawKeyEnvelope error!")), nil
+	}
+	return EncRet(true, rawEnvelope), nil
+}
+
 func FsReadFilePledge(native *native.NativeService) ([]byte, error) {
 	contract := native.ContextRef.CurrentContext().ContractAddress
 
@@ -715,20 +1035,7 @@ func FsReadFilePledge(native *native.NativeService) ([]byte, error) {
 
 	oriPledge, err := getReadPledge(native, readPledge.Downloader, readPledge.FileHash)
 	if err == nil && oriPledge != nil {
-		for _, oriReadPlan := range oriPledge.ReadPlans {
-			foundSamePlan := false
-			for index, readPlan := range readPledge.ReadPlans {
-				if readPlan.NodeAddr == oriReadPlan.NodeAddr {
-					foundSamePlan = true
-
-					readPledge.ReadPlans[index].MaxReadBlockNum += oriReadPlan.MaxReadBlockNum
-					readPledge.ReadPlans[index].HaveReadBlockNum = oriReadPlan.HaveReadBlockNum
-				}
-			}
-			if !foundSamePlan {
-				readPledge.ReadPlans = append(readPledge.ReadPlans, oriReadPlan)
-			}
-		}
+		readPledge.ReadPlans = mergeReadPlans(readPledge.ReadPlans, oriPledge.ReadPlans)
 		readPledge.RestMoney = oriPledge.RestMoney
 		if uint64(native.Height) >= oriPledge.ExpireHeight {
 			readPledge.BlockHeight = uint64(native.Height)
@@ -739,9 +1046,9 @@ func FsReadFilePledge(native *native.NativeService) ([]byte, error) {
 		readPledge.RestMoney = 0
 		readPledge.BlockHeight = uint64(native.Height)
 	}
-	readPledge.ExpireHeight = uint64(native.Height) + fileInfo.FileBlockCount + 30
+	readPledge.ExpireHeight = uint64(native.Height) + fileInfo.FileBlockCount + DefaultReadPledgeExpireIV
 
-	newPledgeFee := totalAddMaxBlockNumToRead * DefaultPerBlockSize * globalParam.GasPerKbForRead
+	newPledgeFee := totalAddMaxBlockNumToRead * fileInfo.BlockSize * globalParam.GasPerKbForRead
 	readPledge.RestMoney += newPledgeFee
 
 	err = appCallTransfer(native, utils.OngContractAddress, readPledge.Downloader, contract, newPledgeFee)
@@ -799,3 +1106,407 @@ func FsCancelFileRead(native *native.NativeService) ([]byte, error) {
 	delReadPledge(native, getPledge.Downloader, getPledge.FileHash)
 	return utils.BYTE_TRUE, nil
 }
+
+// mergeReadPlans adds the blocks of new plans to the original plans of the same node and keeps the blocks read
+func mergeReadPlans(readPlans []ReadPlan, oriReadPlans []ReadPlan) []ReadPlan {
+	for _, oriReadPlan := range oriReadPlans {
+		foundSamePlan := false
+		for index, readPlan := range readPlans {
+			if readPlan.NodeAddr == oriReadPlan.NodeAddr {
+				foundSamePlan = true
+
+				readPlans[index].MaxReadBlockNum += oriReadPlan.MaxReadBlockNum
+				readPlans[index].HaveReadBlockNum = oriReadPlan.HaveReadBlockNum
+			}
+		}
+		if !foundSamePlan {
+			readPlans = append(readPlans, oriReadPlan)
+		}
+	}
+	return readPlans
+}
+
+func FsReadSessionPledge(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	var session ReadSession
+	sessionSrc := common.NewZeroCopySource(native.Input)
+	sessionData, err := DecodeVarBytes(sessionSrc)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge DecodeVarBytes error!")
+	}
+	if err := session.Deserialization(common.NewZeroCopySource(sessionData)); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge deserialization error!")
+	}
+
+	if !native.ContextRef.CheckWitness(session.Downloader) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge CheckDownloader failed!")
+	}
+	if len(session.SessionId) == 0 || len(session.Files) == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge SessionId or Files empty!")
+	}
+
+	globalParam, err := getGlobalParam(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge getGlobalParam error!")
+	}
+
+	var newPledgeFee, totalBlockCount uint64
+	fileHashes := make(map[string]bool)
+	for i, file := range session.Files {
+		if fileHashes[string(file.FileHash)] {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge duplicate file!")
+		}
+		fileHashes[string(file.FileHash)] = true
+
+		fileInfo := getFileInfoByHash(native, file.FileHash)
+		if fileInfo == nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge getFsFileInfo error!")
+		}
+		if !fileInfo.ValidFlag {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge file out of date!")
+		}
+		for index, readPlan := range file.ReadPlans {
+			newPledgeFee += readPlan.MaxReadBlockNum * fileInfo.BlockSize * globalParam.GasPerKbForRead
+			session.Files[i].ReadPlans[index].HaveReadBlockNum = 0
+		}
+		totalBlockCount += fileInfo.FileBlockCount
+	}
+
+	//the new files and plans are added to the session, the budget and expiry are shared by all files
+	session.ExpireHeight = uint64(native.Height) + totalBlockCount + DefaultReadPledgeExpireIV
+	oriSession, err := getReadSession(native, session.Downloader, session.SessionId)
+	if err == nil && oriSession != nil {
+		for _, oriFile := range oriSession.Files {
+			if file := session.getFile(oriFile.FileHash); file != nil {
+				file.ReadPlans = mergeReadPlans(file.ReadPlans, oriFile.ReadPlans)
+			} else {
+				session.Files = append(session.Files, oriFile)
+			}
+		}
+		session.RestMoney = oriSession.RestMoney
+		if uint64(native.Height) >= oriSession.ExpireHeight {
+			session.BlockHeight = uint64(native.Height)
+		} else {
+			session.BlockHeight = oriSession.BlockHeight
+		}
+		if session.ExpireHeight < oriSession.ExpireHeight {
+			session.ExpireHeight = oriSession.ExpireHeight
+		}
+	} else {
+		session.RestMoney = 0
+		session.BlockHeight = uint64(native.Height)
+	}
+	if len(session.Files) > DefaultMaxSessionFiles {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge too many files!")
+	}
+	session.RestMoney += newPledgeFee
+
+	err = appCallTransfer(native, utils.OngContractAddress, session.Downloader, contract, newPledgeFee)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsReadSessionPledge AppCallTransfer, transfer error!")
+	}
+
+	addReadSession(native, &session)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsGetReadSession(native *native.NativeService) ([]byte, error) {
+	var getSession GetReadSession
+	source := common.NewZeroCopySource(native.Input)
+	if err := getSession.Deserialization(source); err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetReadSession Deserialization error!")), nil
+	}
+
+	rawSession, err := getRawReadSession(native, getSession.Downloader, getSession.SessionId)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetReadSession getRawReadSession error!")), nil
+	}
+	return EncRet(true, rawSession), nil
+}
+
+func FsCancelReadSession(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	var getSession GetReadSession
+	source := common.NewZeroCopySource(native.Input)
+	if err := getSession.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelReadSession GetReadSession Deserialization error!")
+	}
+
+	session, err := getReadSession(native, getSession.Downloader, getSession.SessionId)
+	if err != nil || session == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelReadSession getReadSession error!")
+	}
+
+	if !native.ContextRef.CheckWitness(session.Downloader) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelReadSession CheckDownloader failed!")
+	}
+
+	if uint64(native.Height) < session.ExpireHeight {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelReadSession ReadSession locked!")
+	}
+
+	if session.RestMoney > 0 {
+		err = appCallTransfer(native, utils.OngContractAddress, contract, session.Downloader, session.RestMoney)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelReadSession AppCallTransfer, transfer error!")
+		}
+	}
+
+	delReadSession(native, getSession.Downloader, getSession.SessionId)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsSetRenewEscrow(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	var escrow RenewEscrow
+	escrowSrc := common.NewZeroCopySource(native.Input)
+	escrowData, err := DecodeVarBytes(escrowSrc)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow DecodeVarBytes error!")
+	}
+
+	source := common.NewZeroCopySource(escrowData)
+	if err := escrow.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow Deserialization error!")
+	}
+
+	if !native.ContextRef.CheckWitness(escrow.Owner) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow CheckOwner failed!")
+	}
+
+	if escrow.RenewPeriod == 0 || escrow.MaxPrice == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow RenewPeriod or MaxPrice equals zero!")
+	}
+
+	if len(escrow.FileHash) != 0 {
+		fileInfo := getAndUpdateFileInfo(native, escrow.Owner, escrow.FileHash)
+		if fileInfo == nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow getAndUpdateFileInfo error!")
+		}
+		if !fileInfo.ValidFlag {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow File is expired!")
+		}
+		if fileInfo.StorageType != FileStorageTypeUseFile {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow StorageType is not FileStorageTypeUseFile!")
+		}
+		if escrow.RenewPeriod < renewWindow(fileInfo.PdpInterval) {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow RenewPeriod is shorter than the renew window!")
+		}
+	} else {
+		space := getAndUpdateSpaceInfo(native, escrow.Owner)
+		if space == nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow getAndUpdateSpaceInfo error!")
+		}
+		if !space.ValidFlag {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow space timeExpired!")
+		}
+		if escrow.RenewPeriod < renewWindow(space.PdpInterval) {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow RenewPeriod is shorter than the renew window!")
+		}
+	}
+
+	deposit := escrow.Balance
+	wasLow := false
+	if oriEscrow := getRenewEscrow(native, escrow.Owner, escrow.FileHash); oriEscrow != nil {
+		escrow.Balance += oriEscrow.Balance
+		wasLow = oriEscrow.low()
+	}
+
+	if deposit != 0 {
+		err = appCallTransfer(native, utils.OngContractAddress, escrow.Owner, contract, deposit)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetRenewEscrow AppCallTransfer, transfer error!")
+		}
+	}
+
+	if !wasLow && escrow.low() {
+		addRenewEscrowLowEvent(native, &escrow)
+	}
+	addRenewEscrow(native, &escrow)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsCancelRenewEscrow(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	var target RenewTarget
+	source := common.NewZeroCopySource(native.Input)
+	if err := target.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelRenewEscrow Deserialization error!")
+	}
+
+	if !native.ContextRef.CheckWitness(target.Owner) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelRenewEscrow CheckOwner failed!")
+	}
+
+	escrow := getRenewEscrow(native, target.Owner, target.FileHash)
+	if escrow == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelRenewEscrow getRenewEscrow error!")
+	}
+
+	if escrow.Balance > 0 {
+		err := appCallTransfer(native, utils.OngContractAddress, contract, escrow.Owner, escrow.Balance)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsCancelRenewEscrow AppCallTransfer, transfer error!")
+		}
+	}
+
+	delRenewEscrow(native, target.Owner, target.FileHash)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsGetRenewEscrow(native *native.NativeService) ([]byte, error) {
+	var target RenewTarget
+	source := common.NewZeroCopySource(native.Input)
+	if err := target.Deserialization(source); err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetRenewEscrow Deserialization error!")), nil
+	}
+
+	rawEscrow := getRawRenewEscrow(native, target.Owner, target.FileHash)
+	if rawEscrow == nil {
+		return EncRet(false, []byte("[APP SDK] FsGetRenewEscrow getRawRenewEscrow error!")), nil
+	}
+	return EncRet(true, rawEscrow), nil
+}
+
+func FsTriggerRenew(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	var errInfos Errors
+	var targetList RenewTargetList
+	targetListSrc := common.NewZeroCopySource(native.Input)
+	targetListData, err := DecodeVarBytes(targetListSrc)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTriggerRenew DecodeVarBytes error!")
+	}
+
+	source := common.NewZeroCopySource(targetListData)
+	if err := targetList.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTriggerRenew Deserialization error!")
+	}
+
+	if !native.ContextRef.CheckWitness(targetList.Caller) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTriggerRenew CheckCaller failed!")
+	}
+
+	var totalReward uint64
+	renewed := make(map[string]bool)
+	for _, target := range targetList.Targets {
+		targetKey := string(target.Owner[:]) + string(target.FileHash)
+		if renewed[targetKey] {
+			errInfos.AddObjectError(target.Owner.ToBase58()+string(target.FileHash),
+				"[APP SDK] FsTriggerRenew target is duplicated!")
+			continue
+		}
+		renewed[targetKey] = true
+		if err = autoRenew(native, target.Owner, target.FileHash, DefaultRenewCallerReward); err != nil {
+			errInfos.AddObjectError(target.Owner.ToBase58()+string(target.FileHash),
+				"[APP SDK] FsTriggerRenew autoRenew error: "+err.Error())
+			continue
+		}
+		totalReward += DefaultRenewCallerReward
+	}
+
+	if totalReward != 0 {
+		err = appCallTransfer(native, utils.OngContractAddress, contract, targetList.Caller, totalReward)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsTriggerRenew AppCallTransfer, transfer error!")
+		}
+	}
+
+	errInfos.AddErrorsEvent(native)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsUpdateFiles(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	var errInfos Errors
+	var filesUpdate FileUpdateList
+	filesUpdateSrc := common.NewZeroCopySource(native.Input)
+	filesUpdateData, err := DecodeVarBytes(filesUpdateSrc)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateFiles DecodeVarBytes error!")
+	}
+
+	source := common.NewZeroCopySource(filesUpdateData)
+	if err := filesUpdate.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateFiles Deserialization error!")
+	}
+
+	for _, fileUpdate := range filesUpdate.FilesUpdate {
+		if !native.ContextRef.CheckWitness(fileUpdate.FileOwner) {
+			errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles CheckFileOwner failed!")
+			continue
+		}
+
+		if fileUpdate.NewCopyNumber == 0 && fileUpdate.NewPdpInterval == 0 {
+			errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles Param error!")
+			continue
+		}
+
+		fileInfo := getAndUpdateFileInfo(native, fileUpdate.FileOwner, fileUpdate.FileHash)
+		if fileInfo == nil {
+			errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles getAndUpdateFileInfo error!")
+			continue
+		}
+
+		if !fileInfo.ValidFlag {
+			errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles File is expired!")
+			continue
+		}
+
+		if fileInfo.StorageType != FileStorageTypeUseFile {
+			errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles StorageType is not FileStorageTypeUseFile!")
+			continue
+		}
+
+		newFee, refund, err := updateFileInfo(fileInfo, fileUpdate.NewCopyNumber, fileUpdate.NewPdpInterval,
+			uint64(native.Time))
+		if err != nil {
+			errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles "+err.Error())
+			continue
+		}
+
+		if fileUpdate.NewPdpInterval != 0 {
+			if err = checkNodesPdpInterval(native, fileInfo); err != nil {
+				errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles "+err.Error())
+				continue
+			}
+		}
+
+		if newFee != 0 {
+			if refund {
+				err = appCallTransfer(native, utils.OngContractAddress, contract, fileInfo.FileOwner, newFee)
+			} else {
+				err = appCallTransfer(native, utils.OngContractAddress, fileInfo.FileOwner, contract, newFee)
+			}
+			if err != nil {
+				errInfos.AddObjectError(string(fileUpdate.FileHash), "[APP SDK] FsUpdateFiles AppCallTransfer, transfer error!")
+				continue
+			}
+		}
+
+		if err = releasePdpSlots(native, fileInfo); err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsUpdateFiles releasePdpSlots error!")
+		}
+		addFileInfo(native, fileInfo)
+	}
+
+	errInfos.AddErrorsEvent(native)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsAudit(native *native.NativeService) ([]byte, error) {
+	report, err := auditFs(native)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsAudit auditFs error!")), nil
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	report.Serialization(sink)
+	return EncRet(true, sink.Bytes()), nil
+}
@@ -0,0 +1,357 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+const (
+	AuditBalanceMismatch     = 1 //Expected: total liabilities, Actual: contract ONG balance
+	AuditNodeVolumeMismatch  = 2 //Expected: volume of live pdp records, Actual: Volume - RestVol of the node
+	AuditSpaceVolumeMismatch = 3 //Expected: volume of files stored in the space, Actual: Volume - RestVol of the space
+	AuditOrphanRecord        = 4 //record refers to a file, node or space which does not exist
+	AuditBrokenRecord        = 5 //record can not be deserialized, Records holds its storage key
+	AuditSpaceFundingShort   = 6 //Expected: RestAmount of the space, Actual: total of its funding ledger
+)
+
+// AuditMismatch is one broken accounting invariant with the records involved.
+// Addr is the node, space owner or file owner concerned, or the contract for a balance mismatch.
+// Actual of a volume mismatch is zero when RestVol exceeds Volume.
+type AuditMismatch struct {
+	Kind     uint64
+	Addr     common.Address
+	Expected uint64
+	Actual   uint64
+	Records  [][]byte
+}
+
+// FsAuditReport sums every liability held by the ontfs contract and lists the broken invariants.
+type FsAuditReport struct {
+	ContractBalance uint64
+	FileRestAmount  uint64
+	SpaceRestAmount uint64
+	ReadPledgeMoney uint64
+	NodePledge      uint64
+	NodeProfit      uint64
+	RenewEscrow     uint64
+	TreasuryBalance uint64
+	InsurancePool   uint64
+	Liabilities     uint64
+	Mismatches      []AuditMismatch
+}
+
+func (this *AuditMismatch) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.Kind)
+	utils.EncodeAddress(sink, this.Addr)
+	utils.EncodeVarUint(sink, this.Expected)
+	utils.EncodeVarUint(sink, this.Actual)
+	utils.EncodeVarUint(sink, uint64(len(this.Records)))
+	for _, record := range this.Records {
+		sink.WriteVarBytes(record)
+	}
+}
+
+func (this *AuditMismatch) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Kind, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Addr, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Expected, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Actual, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	recordCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < recordCount; i++ {
+		record, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		this.Records = append(this.Records, record)
+	}
+	return nil
+}
+
+func (this *FsAuditReport) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.ContractBalance)
+	utils.EncodeVarUint(sink, this.FileRestAmount)
+	utils.EncodeVarUint(sink, this.SpaceRestAmount)
+	utils.EncodeVarUint(sink, this.ReadPledgeMoney)
+	utils.EncodeVarUint(sink, this.NodePledge)
+	utils.EncodeVarUint(sink, this.NodeProfit)
+	utils.EncodeVarUint(sink, this.RenewEscrow)
+	utils.EncodeVarUint(sink, this.TreasuryBalance)
+	utils.EncodeVarUint(sink, this.InsurancePool)
+	utils.EncodeVarUint(sink, this.Liabilities)
+	utils.EncodeVarUint(sink, uint64(len(this.Mismatches)))
+	for _, mismatch := range this.Mismatches {
+		sinkTmp := common.NewZeroCopySink(nil)
+		mismatch.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+}
+
+func (this *FsAuditReport) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.ContractBalance, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.FileRestAmount, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.SpaceRestAmount, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.ReadPledgeMoney, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.NodePledge, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.NodeProfit, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.RenewEscrow, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.TreasuryBalance, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.InsurancePool, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Liabilities, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	mismatchCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < mismatchCount; i++ {
+		mismatchData, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		var mismatch AuditMismatch
+		if err = mismatch.Deserialization(common.NewZeroCopySource(mismatchData)); err != nil {
+			return err
+		}
+		this.Mismatches = append(this.Mismatches, mismatch)
+	}
+	return nil
+}
+
+func (this *FsAuditReport) addMismatch(kind uint64, addr common.Address, expected uint64, actual uint64,
+	records ...[]byte) {
+	this.Mismatches = append(this.Mismatches, AuditMismatch{Kind: kind, Addr: addr, Expected: expected,
+		Actual: actual, Records: records})
+}
+
+// auditRecords calls fn for every record stored under prefix, in storage order
+func auditRecords(native *native.NativeService, prefix []byte, fn func(key []byte, value []byte)) {
+	iter := native.CacheDB.NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		item, err := utils.GetStorageItem(native, iter.Key())
+		if err != nil || item == nil || item.Value == nil {
+			continue
+		}
+		key := make([]byte, len(iter.Key()))
+		copy(key, iter.Key())
+		fn(key, item.Value)
+	}
+	iter.Release()
+}
+
+// usedVolume returns Volume - RestVol, and false when RestVol exceeds Volume
+func usedVolume(volume uint64, restVol uint64) (uint64, bool) {
+	if restVol > volume {
+		return 0, false
+	}
+	return volume - restVol, true
+}
+
+// auditFs checks the books of the ontfs contract without changing any state
+func auditFs(native *native.NativeService) (*FsAuditReport, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	report := new(FsAuditReport)
+
+	var nodeList []*FsNodeInfo
+	nodes := make(map[common.Address]*FsNodeInfo)
+	auditRecords(native, GenFsNodeInfoPrefix(contract), func(key []byte, value []byte) {
+		var nodeInfo FsNodeInfo
+		if err := nodeInfo.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		report.NodePledge += nodeInfo.Pledge
+		report.NodeProfit += nodeInfo.Profit
+		nodeList = append(nodeList, &nodeInfo)
+		nodes[nodeInfo.NodeAddr] = &nodeInfo
+	})
+
+	var spaceList []*SpaceInfo
+	spaces := make(map[common.Address]*SpaceInfo)
+	auditRecords(native, append(contract[:], ONTFS_FILE_SPACE...), func(key []byte, value []byte) {
+		var spaceInfo SpaceInfo
+		if err := spaceInfo.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		report.SpaceRestAmount += spaceInfo.RestAmount
+		spaceList = append(spaceList, &spaceInfo)
+		spaces[spaceInfo.SpaceOwner] = &spaceInfo
+	})
+
+	files := make(map[string]*FileInfo)
+	spaceFiles := make(map[common.Address][][]byte)
+	spaceFileVol := make(map[common.Address]uint64)
+	auditRecords(native, append(contract[:], ONTFS_FILE_INFO...), func(key []byte, value []byte) {
+		var fileInfo FileInfo
+		if err := fileInfo.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		files[string(fileInfo.FileOwner[:])+string(fileInfo.FileHash)] = &fileInfo
+		if fileInfo.StorageType == FileStorageTypeUseFile {
+			report.FileRestAmount += fileInfo.RestAmount
+			return
+		}
+		if _, ok := spaces[fileInfo.FileOwner]; !ok {
+			report.addMismatch(AuditOrphanRecord, fileInfo.FileOwner, 0, 0, fileInfo.FileHash)
+			return
+		}
+		spaceFiles[fileInfo.FileOwner] = append(spaceFiles[fileInfo.FileOwner], fileInfo.FileHash)
+		spaceFileVol[fileInfo.FileOwner] += fileVolume(&fileInfo)
+	})
+
+	nodeRecords := make(map[common.Address][][]byte)
+	nodeLiveVol := make(map[common.Address]uint64)
+	auditRecords(native, append(contract[:], ONTFS_FILE_PDP...), func(key []byte, value []byte) {
+		var pdpRecord PdpRecord
+		if err := pdpRecord.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		if pdpRecord.SettleFlag {
+			return
+		}
+		fileInfo, ok := files[string(pdpRecord.FileOwner[:])+string(pdpRecord.FileHash)]
+		if !ok {
+			report.addMismatch(AuditOrphanRecord, pdpRecord.NodeAddr, 0, 0, pdpRecord.FileHash)
+			return
+		}
+		if _, ok = nodes[pdpRecord.NodeAddr]; !ok {
+			report.addMismatch(AuditOrphanRecord, pdpRecord.NodeAddr, 0, 0, pdpRecord.FileHash)
+			return
+		}
+		nodeRecords[pdpRecord.NodeAddr] = append(nodeRecords[pdpRecord.NodeAddr], pdpRecord.FileHash)
+		nodeLiveVol[pdpRecord.NodeAddr] += fileVolume(fileInfo)
+	})
+
+	auditRecords(native, append(contract[:], ONTFS_FILE_READ_PLEDGE...), func(key []byte, value []byte) {
+		var readPledge ReadPledge
+		if err := readPledge.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		report.ReadPledgeMoney += readPledge.RestMoney
+	})
+
+	auditRecords(native, append(contract[:], ONTFS_READ_SESSION...), func(key []byte, value []byte) {
+		var session ReadSession
+		if err := session.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		report.ReadPledgeMoney += session.RestMoney
+	})
+
+	auditRecords(native, append(contract[:], ONTFS_SPACE_FUNDING...), func(key []byte, value []byte) {
+		var funding SpaceFunding
+		if err := funding.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		spaceInfo, ok := spaces[funding.SpaceOwner]
+		if !ok {
+			report.addMismatch(AuditOrphanRecord, funding.SpaceOwner, 0, 0, key)
+			return
+		}
+		if total := funding.Total(); total < spaceInfo.RestAmount {
+			report.addMismatch(AuditSpaceFundingShort, funding.SpaceOwner, spaceInfo.RestAmount, total)
+		}
+	})
+
+	auditRecords(native, append(contract[:], ONTFS_RENEW_ESCROW...), func(key []byte, value []byte) {
+		var escrow RenewEscrow
+		if err := escrow.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			report.addMismatch(AuditBrokenRecord, common.ADDRESS_EMPTY, 0, 0, key)
+			return
+		}
+		report.RenewEscrow += escrow.Balance
+	})
+
+	for _, nodeInfo := range nodeList {
+		used, ok := usedVolume(nodeInfo.Volume, nodeInfo.RestVol)
+		if !ok || used != nodeLiveVol[nodeInfo.NodeAddr] {
+			report.addMismatch(AuditNodeVolumeMismatch, nodeInfo.NodeAddr, nodeLiveVol[nodeInfo.NodeAddr], used,
+				nodeRecords[nodeInfo.NodeAddr]...)
+		}
+	}
+	for _, spaceInfo := range spaceList {
+		used, ok := usedVolume(spaceInfo.Volume, spaceInfo.RestVol)
+		if !ok || used != spaceFileVol[spaceInfo.SpaceOwner] {
+			report.addMismatch(AuditSpaceVolumeMismatch, spaceInfo.SpaceOwner, spaceFileVol[spaceInfo.SpaceOwner],
+				used, spaceFiles[spaceInfo.SpaceOwner]...)
+		}
+	}
+
+	feePool, err := getFeePool(native)
+	if err != nil {
+		report.addMismatch(AuditBrokenRecord, contract, 0, 0, GenFsFeePoolKey(contract))
+	} else {
+		report.TreasuryBalance = feePool.TreasuryBalance
+		report.InsurancePool = feePool.InsuranceBalance
+	}
+
+	report.Liabilities = report.FileRestAmount + report.SpaceRestAmount + report.ReadPledgeMoney +
+		report.NodePledge + report.NodeProfit + report.RenewEscrow + report.TreasuryBalance + report.InsurancePool
+
+	balance, err := getOngBalance(native, contract)
+	if err != nil {
+		return nil, fmt.Errorf("auditFs getOngBalance error: %v", err)
+	}
+	report.ContractBalance = balance
+	if report.ContractBalance != report.Liabilities {
+		report.addMismatch(AuditBalanceMismatch, contract, report.Liabilities, report.ContractBalance)
+	}
+	return report, nil
+}
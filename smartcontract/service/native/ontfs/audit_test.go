@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFsAuditReport_Serialization(t *testing.T) {
+	report := FsAuditReport{
+		ContractBalance: 1010,
+		FileRestAmount:  100,
+		SpaceRestAmount: 200,
+		ReadPledgeMoney: 50,
+		NodePledge:      300,
+		NodeProfit:      250,
+		RenewEscrow:     90,
+		TreasuryBalance: 6,
+		InsurancePool:   4,
+		Liabilities:     1000,
+	}
+	report.addMismatch(AuditBalanceMismatch, common.Address{0x08}, 1000, 1010)
+	report.addMismatch(AuditNodeVolumeMismatch, common.Address{0x01}, 512, 256,
+		[]byte("QmevhnWdtmz89BMXuuX5pSY2uZtqKLz7frJsrCojT5kmb6"), []byte("QmXgqKTbzdh83pQtKFb19SpMCpDDcKR2ujqk3pKph9aCNF"))
+
+	sink := common.NewZeroCopySink(nil)
+	report.Serialization(sink)
+
+	report2 := FsAuditReport{}
+	src := common.NewZeroCopySource(sink.Bytes())
+	if err := report2.Deserialization(src); err != nil {
+		t.Fatal("report2 deserialize fail!", err.Error())
+	}
+	assert.Equal(t, report, report2)
+}
+
+func TestUsedVolume(t *testing.T) {
+	used, ok := usedVolume(1024, 256)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(768), used)
+
+	_, ok = usedVolume(256, 1024)
+	assert.False(t, ok)
+}
+
+func TestFsAudit_PreExecOnly(t *testing.T) {
+	service := &native.NativeService{ServiceMap: make(map[string]native.Handler)}
+	RegisterFsContract(service)
+	_, ok := service.ServiceMap[FS_AUDIT]
+	assert.False(t, ok)
+
+	service = &native.NativeService{ServiceMap: make(map[string]native.Handler), PreExec: true}
+	RegisterFsContract(service)
+	_, ok = service.ServiceMap[FS_AUDIT]
+	assert.True(t, ok)
+}
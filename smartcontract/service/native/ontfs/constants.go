@@ -19,7 +19,8 @@
 package ontfs
 
 const (
-	DefaultPassportExpire = 9 //block count. passport expire for GetFileHashList
+	DefaultPassportExpire       = 9  //block count. a passport is valid for this count of blocks after its height
+	DefaultMaxPassportDelegates = 16 //max count of ONT IDs a wallet delegates its passports to
 
 	DefaultNodeMinVolume   = 1024 * 1024 //kb. min total volume with fsNode
 	DefaultNodePerKbPledge = 1           //fsNode's pledge for participant
@@ -29,7 +30,40 @@ const (
 	DefaultGasPerKbForSaveWithFile  = 1 //cost for ontfs-sdk save from fsNode*
 	DefaultGasPerKbForSaveWithSpace = 1 //cost for ontfs-sdk save from fsNode*
 
-	DefaultPdpHeightIV  = 8   //pdp challenge height IV
-	DefaultPerBlockSize = 256 //kb.
-	DefaultPdpBlockNum  = 32
+	DefaultPdpHeightIV     = 8   //pdp challenge height IV
+	DefaultPdpHeightJitter = 8   //random extra blocks added to the pdp challenge height IV
+	DefaultPerBlockSize    = 256 //kb.
+	DefaultPdpBlockNum     = 32
+
+	DefaultMinPerBlockSize = 4        //kb. min block size of a file
+	DefaultMaxPerBlockSize = 4 * 1024 //kb. max block size of a file
+
+	DefaultReadPledgeExpireIV = 30   //block count. a read pledge is locked for the blocks to read and this IV
+	DefaultMaxSessionFiles    = 1000 //max count of files in a read session
+
+	DefaultRenewWindow       = 24 * 60 * 60 //second. auto renew is allowed when expiring within this window
+	DefaultRenewCallerReward = 100000       //paid from renew escrow to whoever triggers the renewal
+	DefaultRenewLowCount     = 2            //renew escrow is low when it can't pay this count of renewals
+
+	DefaultReputationHalfLife      = 30 * 24 * 60 * 60 //second. reputation counters are halved after this time
+	DefaultReputationUptimeUnit    = 24 * 60 * 60      //second. uptime earns one score point per unit
+	DefaultReputationMaxScore      = 1000
+	DefaultReputationServiceScore  = 900 //part of the score earned by services, the rest by uptime
+	DefaultReputationNeutralWeight = 10  //services assumed for a new node, half good and half bad
+	DefaultReputationMissWeight    = 4   //a missed pdp window weighs as many late proves
+
+	DefaultProfitLockRate   = 50               //percent of every node profit locked after it is earned
+	DefaultProfitLockPeriod = 7 * 24 * 60 * 60 //second. time a locked node profit takes to vest
+	DefaultProfitLockBucket = 60 * 60          //second. profits unlocking within the same bucket share one lock
+
+	DefaultProtocolFeeRateBase = 10000 //the protocol fee rate is counted per ten thousand
+	DefaultInsuranceRate       = 50    //percent of the protocol fee put into the insurance pool
+	DefaultFileLostWindows     = 3     //a file is lost when every node holding it missed this count of pdp windows
+
+	DefaultMaxNodeEndpoints   = 8
+	DefaultMaxProtocolLen     = 16
+	DefaultMaxEndpointAddrLen = 256
+	DefaultMaxNodeVersionLen  = 64
+	DefaultEndpointStaleTime  = 24 * 60 * 60 //second. an endpoint no client saw reachable for this time is stale
+	DefaultAttestationExpire  = 60 * 60      //second. a liveness attestation is accepted within this time
 )
@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native"
+)
+
+const (
+	EVENT_RENEW_ESCROW_LOW  = "renewEscrowLow"
+	EVENT_FILE_LOSS_CLAIMED = "fileLossClaimed"
+)
+
+func addFsEvent(native *native.NativeService, states []interface{}) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          states,
+		})
+}
+
+func addRenewEscrowLowEvent(native *native.NativeService, escrow *RenewEscrow) {
+	addFsEvent(native, []interface{}{EVENT_RENEW_ESCROW_LOW, escrow.Owner.ToBase58(), string(escrow.FileHash),
+		escrow.Balance})
+}
+
+func addFileLossClaimedEvent(native *native.NativeService, fileInfo *FileInfo, slashed uint64, compensation uint64) {
+	addFsEvent(native, []interface{}{EVENT_FILE_LOSS_CLAIMED, fileInfo.FileOwner.ToBase58(), string(fileInfo.FileHash),
+		slashed, compensation})
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/errors"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/global_params"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+func FsSetFeeConfig(native *native.NativeService) ([]byte, error) {
+	var feeConfig FsFeeConfig
+	source := common.NewZeroCopySource(native.Input)
+	if err := feeConfig.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsSetFeeConfig Deserialization error!")
+	}
+
+	adminAddress, err := global_params.GetStorageRole(native,
+		global_params.GenerateOperatorKey(utils.ParamContractAddress))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsSetFeeConfig get admin error!")
+	}
+	if err = utils.ValidateOwner(native, adminAddress); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsSetFeeConfig checkWitness error!")
+	}
+
+	if err = feeConfig.check(); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsSetFeeConfig " + err.Error())
+	}
+	setFeeConfig(native, &feeConfig)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsGetFeeConfig(native *native.NativeService) ([]byte, error) {
+	feeConfig, err := getFeeConfig(native)
+	if err != nil {
+		return EncRet(false, []byte("[Fee Govern] FsGetFeeConfig getFeeConfig error!")), nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	feeConfig.Serialization(sink)
+	return EncRet(true, sink.Bytes()), nil
+}
+
+func FsGetFeePool(native *native.NativeService) ([]byte, error) {
+	feePool, err := getFeePool(native)
+	if err != nil {
+		return EncRet(false, []byte("[Fee Govern] FsGetFeePool getFeePool error!")), nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	feePool.Serialization(sink)
+	return EncRet(true, sink.Bytes()), nil
+}
+
+// FsWithdrawTreasury pays the treasury part of the protocol fees to the configured treasury
+func FsWithdrawTreasury(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	feeConfig, err := getFeeConfig(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsWithdrawTreasury getFeeConfig error!")
+	}
+	if feeConfig.Treasury == common.ADDRESS_EMPTY {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsWithdrawTreasury treasury not set!")
+	}
+	if !native.ContextRef.CheckWitness(feeConfig.Treasury) {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsWithdrawTreasury CheckTreasury failed!")
+	}
+
+	feePool, err := getFeePool(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsWithdrawTreasury getFeePool error!")
+	}
+	if feePool.TreasuryBalance == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsWithdrawTreasury treasury balance = 0 error!")
+	}
+	err = appCallTransfer(native, utils.OngContractAddress, contract, feeConfig.Treasury, feePool.TreasuryBalance)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Fee Govern] FsWithdrawTreasury appCallTransfer, transfer error!")
+	}
+	feePool.TreasuryBalance = 0
+	setFeePool(native, feePool)
+	return utils.BYTE_TRUE, nil
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/itchyny/base58-go"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// multicodec of the file content
+const (
+	CidCodecRaw   = 0x55
+	CidCodecDagPb = 0x70
+)
+
+// multihash code of the file digest
+const (
+	CidHashSha2_256    = 0x12
+	CidHashSha2_512    = 0x13
+	CidHashSha3_256    = 0x16
+	CidHashKeccak256   = 0x1b
+	CidHashBlake2b_256 = 0xb220
+)
+
+const (
+	cidV0Prefix    = "Qm" //base58 of the sha2-256 multihash prefix
+	cidV1Multibase = 'b'  //base32, lower case and no padding
+	cidMaxLen      = 128
+)
+
+var cidDigestLen = map[uint64]int{
+	CidHashSha2_256:    32,
+	CidHashSha2_512:    64,
+	CidHashSha3_256:    32,
+	CidHashKeccak256:   32,
+	CidHashBlake2b_256: 32,
+}
+
+var cidBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// FileCid is the parsed form of a FileHash. A FileHash is a CID: version 0 is the base58 sha2-256
+// multihash of a dag-pb file ("Qm..."), version 1 is base32 multibase ("b...") of the version, the
+// content codec and the multihash.
+type FileCid struct {
+	Version uint64
+	Codec   uint64
+	HashAlg uint64
+	Digest  []byte
+}
+
+// FileCidInfo is a FileHash and its parsed form
+type FileCidInfo struct {
+	FileHash []byte
+	Cid      FileCid
+}
+
+func (this *FileCid) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.Version)
+	utils.EncodeVarUint(sink, this.Codec)
+	utils.EncodeVarUint(sink, this.HashAlg)
+	sink.WriteVarBytes(this.Digest)
+}
+
+func (this *FileCid) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Version, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Codec, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.HashAlg, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Digest, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *FileCidInfo) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.FileHash)
+	this.Cid.Serialization(sink)
+}
+
+func (this *FileCidInfo) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.FileHash, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if err = this.Cid.Deserialization(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+// multihash returns the self describing digest, <hash code><digest length><digest> in uvarints
+func (this *FileCid) multihash() []byte {
+	buf := appendUvarint(nil, this.HashAlg)
+	buf = appendUvarint(buf, uint64(len(this.Digest)))
+	return append(buf, this.Digest...)
+}
+
+// String returns the canonical text of the cid
+func (this *FileCid) String() string {
+	if this.Version == 0 {
+		x := new(big.Int).SetBytes(this.multihash())
+		encoded, _ := base58.BitcoinEncoding.Encode([]byte(x.String()))
+		return string(encoded)
+	}
+	buf := appendUvarint(nil, this.Version)
+	buf = appendUvarint(buf, this.Codec)
+	buf = append(buf, this.multihash()...)
+	return string(cidV1Multibase) + strings.ToLower(cidBase32.EncodeToString(buf))
+}
+
+// ParseFileCid parses and validates fileHash. Only the canonical text of a cid is accepted, so every
+// file has exactly one FileHash.
+func ParseFileCid(fileHash []byte) (*FileCid, error) {
+	if len(fileHash) == 0 || len(fileHash) > cidMaxLen {
+		return nil, fmt.Errorf("ParseFileCid length %d out of range", len(fileHash))
+	}
+
+	var cid *FileCid
+	var err error
+	text := string(fileHash)
+	if strings.HasPrefix(text, cidV0Prefix) {
+		cid, err = parseCidV0(text)
+	} else if text[0] == cidV1Multibase {
+		cid, err = parseCidV1(text[1:])
+	} else {
+		return nil, fmt.Errorf("ParseFileCid unknown cid format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cid.String() != text {
+		return nil, fmt.Errorf("ParseFileCid cid is not canonical")
+	}
+	return cid, nil
+}
+
+func parseCidV0(text string) (*FileCid, error) {
+	decoded, err := base58.BitcoinEncoding.Decode([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("parseCidV0 base58 decode error: %s", err)
+	}
+	x, ok := new(big.Int).SetString(string(decoded), 10)
+	if !ok {
+		return nil, fmt.Errorf("parseCidV0 base58 decode error")
+	}
+
+	cid := &FileCid{Version: 0, Codec: CidCodecDagPb}
+	if err = cid.parseMultihash(x.Bytes()); err != nil {
+		return nil, err
+	}
+	if cid.HashAlg != CidHashSha2_256 {
+		return nil, fmt.Errorf("parseCidV0 hash algorithm %x not sha2-256", cid.HashAlg)
+	}
+	return cid, nil
+}
+
+func parseCidV1(text string) (*FileCid, error) {
+	buf, err := cidBase32.DecodeString(strings.ToUpper(text))
+	if err != nil {
+		return nil, fmt.Errorf("parseCidV1 base32 decode error: %s", err)
+	}
+
+	cid := new(FileCid)
+	if cid.Version, buf, err = readUvarint(buf); err != nil {
+		return nil, err
+	}
+	if cid.Version != 1 {
+		return nil, fmt.Errorf("parseCidV1 unsupported version %d", cid.Version)
+	}
+	if cid.Codec, buf, err = readUvarint(buf); err != nil {
+		return nil, err
+	}
+	if cid.Codec != CidCodecRaw && cid.Codec != CidCodecDagPb {
+		return nil, fmt.Errorf("parseCidV1 unsupported codec %x", cid.Codec)
+	}
+	if err = cid.parseMultihash(buf); err != nil {
+		return nil, err
+	}
+	return cid, nil
+}
+
+func (this *FileCid) parseMultihash(buf []byte) error {
+	var digestLen uint64
+	var err error
+	if this.HashAlg, buf, err = readUvarint(buf); err != nil {
+		return err
+	}
+	if digestLen, buf, err = readUvarint(buf); err != nil {
+		return err
+	}
+
+	expected, ok := cidDigestLen[this.HashAlg]
+	if !ok {
+		return fmt.Errorf("parseMultihash unsupported hash algorithm %x", this.HashAlg)
+	}
+	if digestLen != uint64(expected) || len(buf) != expected {
+		return fmt.Errorf("parseMultihash digest length error")
+	}
+	this.Digest = buf
+	return nil
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	tmp := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(tmp, x)
+	return append(buf, tmp[:n]...)
+}
+
+func readUvarint(buf []byte) (uint64, []byte, error) {
+	x, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("readUvarint error")
+	}
+	return x, buf[n:], nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// testFileCid returns the cid v0 FileHash of data
+func testFileCid(data string) []byte {
+	digest := sha256.Sum256([]byte(data))
+	cid := FileCid{Version: 0, Codec: CidCodecDagPb, HashAlg: CidHashSha2_256, Digest: digest[:]}
+	return []byte(cid.String())
+}
+
+func TestParseFileCid(t *testing.T) {
+	cid, err := ParseFileCid([]byte("QmevhnWdtmz89BMXuuX5pSY2uZtqKLz7frJsrCojT5kmb6"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), cid.Version)
+	assert.Equal(t, uint64(CidCodecDagPb), cid.Codec)
+	assert.Equal(t, uint64(CidHashSha2_256), cid.HashAlg)
+	assert.Equal(t, 32, len(cid.Digest))
+
+	cid, err = ParseFileCid([]byte("bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), cid.Version)
+	assert.Equal(t, uint64(CidCodecDagPb), cid.Codec)
+	assert.Equal(t, uint64(CidHashSha2_256), cid.HashAlg)
+
+	digest := make([]byte, 64)
+	raw := FileCid{Version: 1, Codec: CidCodecRaw, HashAlg: CidHashSha2_512, Digest: digest}
+	cid, err = ParseFileCid([]byte(raw.String()))
+	assert.Nil(t, err)
+	assert.Equal(t, raw, *cid)
+}
+
+func TestParseFileCid_Malformed(t *testing.T) {
+	v0 := string(testFileCid("file"))
+	v1 := (&FileCid{Version: 1, Codec: CidCodecRaw, HashAlg: CidHashSha2_256, Digest: make([]byte, 32)}).String()
+	for _, fileHash := range []string{
+		"",
+		"QmSimSpaceFileHashxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+		v0[:len(v0)-1],
+		v0 + "1",
+		"B" + v1[1:],
+		v1[:len(v1)-2],
+		(&FileCid{Version: 1, Codec: 0x71, HashAlg: CidHashSha2_256, Digest: make([]byte, 32)}).String(),
+		(&FileCid{Version: 1, Codec: CidCodecRaw, HashAlg: 0x11, Digest: make([]byte, 20)}).String(),
+		(&FileCid{Version: 1, Codec: CidCodecRaw, HashAlg: CidHashSha2_256, Digest: make([]byte, 31)}).String(),
+		(&FileCid{Version: 2, Codec: CidCodecRaw, HashAlg: CidHashSha2_256, Digest: make([]byte, 32)}).String(),
+		"zb2rhe5P4gXftAwvA4eXQ5HJwsER2owDyS9sKaQRRVQPn93bA",
+	} {
+		_, err := ParseFileCid([]byte(fileHash))
+		assert.Error(t, err, fileHash)
+	}
+}
+
+func TestFileCidInfo_Serialization(t *testing.T) {
+	fileHash := testFileCid("file")
+	cid, err := ParseFileCid(fileHash)
+	assert.Nil(t, err)
+	info := FileCidInfo{FileHash: fileHash, Cid: *cid}
+
+	sink := common.NewZeroCopySink(nil)
+	info.Serialization(sink)
+
+	var info2 FileCidInfo
+	assert.Nil(t, info2.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, info, info2)
+}
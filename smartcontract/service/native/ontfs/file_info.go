@@ -50,6 +50,7 @@ type FileInfo struct {
 	PdpParam       []byte
 	ValidFlag      bool
 	StorageType    uint64
+	BlockSize      uint64 //kb. size of every pdp block of the file
 }
 
 type FileInfoList struct {
@@ -61,6 +62,7 @@ type FileHashList struct {
 }
 
 func (this *FileInfo) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
 	sink.WriteVarBytes(this.FileHash)
 	utils.EncodeAddress(sink, this.FileOwner)
 	sink.WriteVarBytes(this.FileDesc)
@@ -77,10 +79,14 @@ func (this *FileInfo) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteVarBytes(this.PdpParam)
 	sink.WriteBool(this.ValidFlag)
 	utils.EncodeVarUint(sink, this.StorageType)
+	utils.EncodeVarUint(sink, this.BlockSize)
 }
 
 func (this *FileInfo) Deserialization(source *common.ZeroCopySource) error {
-	var err error
+	version, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
 	this.FileHash, err = DecodeVarBytes(source)
 	if err != nil {
 		return err
@@ -145,7 +151,15 @@ func (this *FileInfo) Deserialization(source *common.ZeroCopySource) error {
 	if err != nil {
 		return err
 	}
-
+	//files stored before BlockSize was introduced use the default block size
+	if !hasRecordField(source, version, RecordVersion2) {
+		this.BlockSize = DefaultPerBlockSize
+		return nil
+	}
+	this.BlockSize, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -214,6 +228,11 @@ func (this *FileHashList) Deserialization(source *common.ZeroCopySource) error {
 	return nil
 }
 
+// fileVolume returns the volume in kb taken by a single copy of the file
+func fileVolume(fileInfo *FileInfo) uint64 {
+	return fileInfo.FileBlockCount * fileInfo.BlockSize
+}
+
 func addFileInfo(native *native.NativeService, fileInfo *FileInfo) {
 	contract := native.ContextRef.CurrentContext().ContractAddress
 	fileInfoKey := GenFsFileInfoKey(contract, fileInfo.FileOwner, fileInfo.FileHash)
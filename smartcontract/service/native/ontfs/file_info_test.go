@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileInfo_Serialization(t *testing.T) {
+	fileInfo := FileInfo{
+		FileHash:       []byte("QmevhnWdtmz89BMXuuX5pSY2uZtqKLz7frJsrCojT5kmb6"),
+		FileOwner:      common.Address{0x01, 0x02, 0x03},
+		FileDesc:       []byte("test file"),
+		FileBlockCount: 10,
+		RealFileSize:   1000,
+		CopyNumber:     2,
+		PdpInterval:    600,
+		TimeStart:      100,
+		TimeExpired:    1000,
+		PdpParam:       []byte("pdp param"),
+		ValidFlag:      true,
+		StorageType:    FileStorageTypeUseFile,
+		BlockSize:      64,
+	}
+	sink := common.NewZeroCopySink(nil)
+	fileInfo.Serialization(sink)
+
+	fileInfo2 := FileInfo{}
+	src := common.NewZeroCopySource(sink.Bytes())
+	if err := fileInfo2.Deserialization(src); err != nil {
+		t.Fatal("fileInfo2 deserialize fail!", err.Error())
+	}
+	assert.Equal(t, fileInfo, fileInfo2)
+	assert.Equal(t, uint64(640), fileVolume(&fileInfo2))
+}
+
+func TestFileInfo_DeserializationWithoutBlockSize(t *testing.T) {
+	fileInfo := FileInfo{
+		FileHash:       []byte("QmevhnWdtmz89BMXuuX5pSY2uZtqKLz7frJsrCojT5kmb6"),
+		FileBlockCount: 10,
+		CopyNumber:     1,
+		StorageType:    FileStorageTypeUseSpace,
+	}
+	sink := common.NewZeroCopySink(nil)
+	fileInfo.Serialization(sink)
+	//drop the version prefix and the trailing BlockSize, as records stored before they were introduced
+	oldSize := len(sink.Bytes()) - int(utils.EncodeVarUint(common.NewZeroCopySink(nil), 0))
+
+	fileInfo2 := FileInfo{}
+	src := common.NewZeroCopySource(sink.Bytes()[2:oldSize])
+	if err := fileInfo2.Deserialization(src); err != nil {
+		t.Fatal("fileInfo2 deserialize fail!", err.Error())
+	}
+	assert.Equal(t, uint64(DefaultPerBlockSize), fileInfo2.BlockSize)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/ontio/ontology-crypto/ec"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
+)
+
+// EncryptFileKey encrypts the file key to the reader's public key and returns the envelope.
+// An ephemeral key on the reader's curve is agreed with the reader's key by ECDH, the shared secret
+// is hashed with SHA256 into an AES-GCM key. Only ECDSA and SM2 keys can be used.
+func EncryptFileKey(pubKey keypair.PublicKey, fileKey []byte) ([]byte, error) {
+	ecPubKey, ok := pubKey.(*ec.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("EncryptFileKey unsupported public key type")
+	}
+	curve := ecPubKey.Curve
+
+	ephemeralPri, x, y, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("EncryptFileKey GenerateKey error: %s", err.Error())
+	}
+	ephemeralPub := elliptic.Marshal(curve, x, y)
+
+	sharedX, _ := curve.ScalarMult(ecPubKey.X, ecPubKey.Y, ephemeralPri)
+	aead, err := newEnvelopeCipher(curve, sharedX, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("EncryptFileKey nonce error: %s", err.Error())
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(ephemeralPub)
+	sink.WriteVarBytes(nonce)
+	sink.WriteVarBytes(aead.Seal(nil, nonce, fileKey, ephemeralPub))
+	return sink.Bytes(), nil
+}
+
+// DecryptFileKey opens an envelope made by EncryptFileKey with the reader's private key.
+func DecryptFileKey(priKey keypair.PrivateKey, envelope []byte) ([]byte, error) {
+	ecPriKey, ok := priKey.(*ec.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("DecryptFileKey unsupported private key type")
+	}
+	curve := ecPriKey.Curve
+
+	source := common.NewZeroCopySource(envelope)
+	ephemeralPub, err := DecodeVarBytes(source)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptFileKey decode ephemeral key error")
+	}
+	nonce, err := DecodeVarBytes(source)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptFileKey decode nonce error")
+	}
+	sealed, err := DecodeVarBytes(source)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptFileKey decode sealed key error")
+	}
+
+	x, y := elliptic.Unmarshal(curve, ephemeralPub)
+	if x == nil {
+		return nil, fmt.Errorf("DecryptFileKey invalid ephemeral key")
+	}
+	sharedX, _ := curve.ScalarMult(x, y, ecPriKey.D.Bytes())
+	aead, err := newEnvelopeCipher(curve, sharedX, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("DecryptFileKey invalid nonce")
+	}
+	fileKey, err := aead.Open(nil, nonce, sealed, ephemeralPub)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptFileKey open envelope error: %s", err.Error())
+	}
+	return fileKey, nil
+}
+
+func newEnvelopeCipher(curve elliptic.Curve, sharedX *big.Int, ephemeralPub []byte) (cipher.AEAD, error) {
+	secret := make([]byte, (curve.Params().BitSize+7)/8)
+	sharedBytes := sharedX.Bytes()
+	copy(secret[len(secret)-len(sharedBytes):], sharedBytes)
+
+	key := sha256.Sum256(append(secret, ephemeralPub...))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("newEnvelopeCipher NewCipher error: %s", err.Error())
+	}
+	return cipher.NewGCM(block)
+}
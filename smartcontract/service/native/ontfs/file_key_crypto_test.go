@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileKeyEnvelope(t *testing.T) {
+	fileKey := []byte("0123456789abcdef0123456789abcdef")
+	for _, keyType := range []struct {
+		t     keypair.KeyType
+		curve byte
+	}{
+		{keypair.PK_ECDSA, keypair.P256},
+		{keypair.PK_SM2, keypair.SM2P256V1},
+	} {
+		priKey, pubKey, err := keypair.GenerateKeyPair(keyType.t, keyType.curve)
+		if err != nil {
+			t.Fatal("GenerateKeyPair error", err.Error())
+		}
+		envelope, err := EncryptFileKey(pubKey, fileKey)
+		if err != nil {
+			t.Fatal("EncryptFileKey error", err.Error())
+		}
+		key, err := DecryptFileKey(priKey, envelope)
+		if err != nil {
+			t.Fatal("DecryptFileKey error", err.Error())
+		}
+		assert.Equal(t, fileKey, key)
+
+		otherPriKey, _, _ := keypair.GenerateKeyPair(keyType.t, keyType.curve)
+		_, err = DecryptFileKey(otherPriKey, envelope)
+		assert.NotNil(t, err)
+	}
+
+	_, edPubKey, _ := keypair.GenerateKeyPair(keypair.PK_EDDSA, keypair.ED25519)
+	_, err := EncryptFileKey(edPubKey, fileKey)
+	assert.NotNil(t, err)
+}
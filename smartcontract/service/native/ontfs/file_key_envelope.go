@@ -0,0 +1,271 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// KeyEnvelope is the decryption key of a private file encrypted to one whitelisted reader.
+type KeyEnvelope struct {
+	FileHash   []byte
+	FileOwner  common.Address
+	Reader     common.Address
+	PublicKey  []byte //reader public key the file key is encrypted to
+	KeyVersion uint64 //bumped by the owner every time the file key is rotated
+	Envelope   []byte //file key encrypted by EncryptFileKey
+}
+
+type KeyEnvelopeList struct {
+	Envelopes []KeyEnvelope
+}
+
+// KeyEnvelopeRevoke deletes the envelopes of Readers. A non-empty NewEnvelopes rotates the file key:
+// every envelope of the old key is deleted and NewEnvelopes are stored for the remaining readers.
+type KeyEnvelopeRevoke struct {
+	FileHash     []byte
+	FileOwner    common.Address
+	Readers      []common.Address
+	NewEnvelopes []KeyEnvelope
+}
+
+type KeyEnvelopeQuery struct {
+	FileHash []byte
+	Passport []byte
+}
+
+func (this *KeyEnvelope) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.FileHash)
+	utils.EncodeAddress(sink, this.FileOwner)
+	utils.EncodeAddress(sink, this.Reader)
+	sink.WriteVarBytes(this.PublicKey)
+	utils.EncodeVarUint(sink, this.KeyVersion)
+	sink.WriteVarBytes(this.Envelope)
+}
+
+func (this *KeyEnvelope) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.FileHash, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.FileOwner, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Reader, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.PublicKey, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.KeyVersion, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Envelope, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *KeyEnvelopeList) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, uint64(len(this.Envelopes)))
+	for _, envelope := range this.Envelopes {
+		sinkTmp := common.NewZeroCopySink(nil)
+		envelope.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+}
+
+func (this *KeyEnvelopeList) Deserialization(source *common.ZeroCopySource) error {
+	envelopeCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < envelopeCount; i++ {
+		envelopeData, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		var envelope KeyEnvelope
+		if err = envelope.Deserialization(common.NewZeroCopySource(envelopeData)); err != nil {
+			return err
+		}
+		this.Envelopes = append(this.Envelopes, envelope)
+	}
+	return nil
+}
+
+func (this *KeyEnvelopeRevoke) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.FileHash)
+	utils.EncodeAddress(sink, this.FileOwner)
+	utils.EncodeVarUint(sink, uint64(len(this.Readers)))
+	for _, reader := range this.Readers {
+		utils.EncodeAddress(sink, reader)
+	}
+	envelopeList := KeyEnvelopeList{Envelopes: this.NewEnvelopes}
+	envelopeList.Serialization(sink)
+}
+
+func (this *KeyEnvelopeRevoke) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.FileHash, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.FileOwner, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	readerCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < readerCount; i++ {
+		reader, err := utils.DecodeAddress(source)
+		if err != nil {
+			return err
+		}
+		this.Readers = append(this.Readers, reader)
+	}
+	var envelopeList KeyEnvelopeList
+	if err = envelopeList.Deserialization(source); err != nil {
+		return err
+	}
+	this.NewEnvelopes = envelopeList.Envelopes
+	return nil
+}
+
+func (this *KeyEnvelopeQuery) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.FileHash)
+	sink.WriteVarBytes(this.Passport)
+}
+
+func (this *KeyEnvelopeQuery) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.FileHash, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Passport, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+func addKeyEnvelope(native *native.NativeService, envelope *KeyEnvelope) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	envelopeKey := GenFsKeyEnvelopeKey(contract, envelope.FileOwner, envelope.FileHash, envelope.Reader)
+
+	sink := common.NewZeroCopySink(nil)
+	envelope.Serialization(sink)
+
+	utils.PutBytes(native, envelopeKey, sink.Bytes())
+}
+
+func delKeyEnvelope(native *native.NativeService, fileOwner common.Address, fileHash []byte, reader common.Address) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	envelopeKey := GenFsKeyEnvelopeKey(contract, fileOwner, fileHash, reader)
+	native.CacheDB.Delete(envelopeKey)
+}
+
+func getRawKeyEnvelope(native *native.NativeService, fileOwner common.Address, fileHash []byte,
+	reader common.Address) []byte {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	envelopeKey := GenFsKeyEnvelopeKey(contract, fileOwner, fileHash, reader)
+
+	item, err := utils.GetStorageItem(native, envelopeKey)
+	if err != nil || item == nil || item.Value == nil {
+		return nil
+	}
+	return item.Value
+}
+
+func getKeyEnvelope(native *native.NativeService, fileOwner common.Address, fileHash []byte,
+	reader common.Address) *KeyEnvelope {
+	rawEnvelope := getRawKeyEnvelope(native, fileOwner, fileHash, reader)
+	if rawEnvelope == nil {
+		return nil
+	}
+
+	var envelope KeyEnvelope
+	source := common.NewZeroCopySource(rawEnvelope)
+	if err := envelope.Deserialization(source); err != nil {
+		return nil
+	}
+	return &envelope
+}
+
+// getKeyEnvelopeList returns every envelope of the file, in storage order
+func getKeyEnvelopeList(native *native.NativeService, fileOwner common.Address, fileHash []byte) []KeyEnvelope {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	envelopePrefix := GenFsKeyEnvelopePrefix(contract, fileOwner, fileHash)
+
+	var envelopes []KeyEnvelope
+	iter := native.CacheDB.NewIterator(envelopePrefix[:])
+	for has := iter.First(); has; has = iter.Next() {
+		item, err := utils.GetStorageItem(native, iter.Key())
+		if err != nil || item == nil || item.Value == nil {
+			continue
+		}
+		var envelope KeyEnvelope
+		source := common.NewZeroCopySource(item.Value)
+		if err := envelope.Deserialization(source); err != nil {
+			continue
+		}
+		if envelope.FileOwner != fileOwner || string(envelope.FileHash) != string(fileHash) {
+			continue
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	iter.Release()
+	return envelopes
+}
+
+func delKeyEnvelopeList(native *native.NativeService, fileOwner common.Address, fileHash []byte) {
+	for _, envelope := range getKeyEnvelopeList(native, fileOwner, fileHash) {
+		delKeyEnvelope(native, fileOwner, fileHash, envelope.Reader)
+	}
+}
+
+func inWhiteList(whiteList *WhiteList, addr common.Address) bool {
+	if whiteList == nil {
+		return false
+	}
+	for _, userAddr := range whiteList.UsersAddr {
+		if userAddr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// checkKeyEnvelope checks the envelope is for an existing file and a reader allowed to read it
+func checkKeyEnvelope(native *native.NativeService, envelope *KeyEnvelope) error {
+	if len(envelope.Envelope) == 0 {
+		return fmt.Errorf("envelope is empty")
+	}
+	if getFileInfoFromDb(native, envelope.FileOwner, envelope.FileHash) == nil {
+		return fmt.Errorf("file not found")
+	}
+	if envelope.Reader != envelope.FileOwner &&
+		!inWhiteList(getWhiteList(native, envelope.FileOwner, envelope.FileHash), envelope.Reader) {
+		return fmt.Errorf("reader is not in white list")
+	}
+	return nil
+}
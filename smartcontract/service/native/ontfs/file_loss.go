@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// lostFileNodes returns the nodes holding the file when none of them proved it for FileLostWindows
+// pdp windows, and nil when the file is not lost. A file no node has proved yet is not lost.
+func lostFileNodes(native *native.NativeService, fileInfo *FileInfo) []common.Address {
+	var nodes []common.Address
+	pdpRecordList := getPdpRecordList(native, fileInfo.FileHash, fileInfo.FileOwner)
+	for _, pdpRecord := range pdpRecordList.PdpRecords {
+		if pdpRecord.SettleFlag {
+			continue
+		}
+		if countRecordMissedWindows(fileInfo, &pdpRecord, uint64(native.Time)) < DefaultFileLostWindows {
+			return nil
+		}
+		nodes = append(nodes, pdpRecord.NodeAddr)
+	}
+	return nodes
+}
+
+// claimFileLoss slashes the pledge backing the file from every node which lost it into the insurance
+// pool, taking the node's locked profit first, then pays the file owner back what the file cost so far,
+// as far as the insurance pool allows. It returns the slashed amount and the compensation, the file
+// itself is left to the caller.
+func claimFileLoss(native *native.NativeService, fileInfo *FileInfo, nodes []common.Address,
+	globalParam *FsGlobalParam) (uint64, uint64, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	feePool, err := getFeePool(native)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var slashed uint64
+	for _, nodeAddr := range nodes {
+		nodeInfo := getNodeInfo(native, nodeAddr)
+		if nodeInfo == nil {
+			return 0, 0, fmt.Errorf("getNodeInfo %s error", nodeAddr.ToBase58())
+		}
+		slash := fileVolume(fileInfo) * globalParam.NodePerKbPledge
+		slashed += slashNode(native, nodeInfo, slash)
+		addNodeInfo(native, nodeInfo)
+	}
+	feePool.InsuranceBalance += slashed
+
+	compensation := fileInfo.FileCost
+	if compensation > feePool.InsuranceBalance {
+		compensation = feePool.InsuranceBalance
+	}
+	if compensation > 0 {
+		err = appCallTransfer(native, utils.OngContractAddress, contract, fileInfo.FileOwner, compensation)
+		if err != nil {
+			return 0, 0, fmt.Errorf("compensation transfer error")
+		}
+		feePool.InsuranceBalance -= compensation
+	}
+	setFeePool(native, feePool)
+	return slashed, compensation, nil
+}
+
+// slashNode takes up to amount from the locked profit of the node, then from its pledge, and returns
+// the amount taken
+func slashNode(native *native.NativeService, nodeInfo *FsNodeInfo, amount uint64) uint64 {
+	updateProfitVesting(native, nodeInfo)
+	fromLocked := amount
+	if fromLocked > nodeInfo.LockedProfit {
+		fromLocked = nodeInfo.LockedProfit
+	}
+	if fromLocked > 0 {
+		vesting := getProfitVesting(native, nodeInfo.NodeAddr)
+		vesting.release(uint64(native.Time))
+		fromLocked = vesting.slash(fromLocked, uint64(native.Time))
+		setProfitVesting(native, vesting)
+		nodeInfo.Profit -= fromLocked
+		updateProfitVesting(native, nodeInfo)
+	}
+
+	fromPledge := amount - fromLocked
+	if fromPledge > nodeInfo.Pledge {
+		fromPledge = nodeInfo.Pledge
+	}
+	nodeInfo.Pledge -= fromPledge
+	return fromLocked + fromPledge
+}
+
+// nodeUnderPledged reports whether the node has less pledge than its volume needs, after a slash.
+// Such a node takes no new file until it pledges again with FsNodeUpdate.
+func nodeUnderPledged(nodeInfo *FsNodeInfo, globalParam *FsGlobalParam) bool {
+	return nodeInfo.Pledge < nodeInfo.Volume*globalParam.NodePerKbPledge
+}
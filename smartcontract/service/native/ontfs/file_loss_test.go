@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimFileLoss_SlashLockedProfitFirst(t *testing.T) {
+	chain, err := testsuite.NewChain(1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalParam := &FsGlobalParam{NodePerKbPledge: 2}
+	fileInfo := &FileInfo{FileHash: []byte("file"), FileBlockCount: 1, BlockSize: 100}
+	slash := fileVolume(fileInfo) * globalParam.NodePerKbPledge
+
+	locked := &FsNodeInfo{NodeAddr: common.Address{0x01}, Volume: 1000, RestVol: 1000,
+		Pledge: 1000 * globalParam.NodePerKbPledge, Profit: slash + 50}
+	short := &FsNodeInfo{NodeAddr: common.Address{0x02}, Volume: 1000, RestVol: 1000,
+		Pledge: 1000 * globalParam.NodePerKbPledge, Profit: slash / 2}
+
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		for _, nodeInfo := range []*FsNodeInfo{locked, short} {
+			vesting := &ProfitVesting{NodeAddr: nodeInfo.NodeAddr}
+			vesting.lock(nodeInfo.Profit, 2000)
+			setProfitVesting(native, vesting)
+			assert.False(t, nodeUnderPledged(nodeInfo, globalParam))
+			addNodeInfo(native, nodeInfo)
+		}
+
+		slashed, compensation, err := claimFileLoss(native, fileInfo,
+			[]common.Address{locked.NodeAddr, short.NodeAddr}, globalParam)
+		assert.Nil(t, err)
+		assert.Equal(t, 2*slash, slashed)
+		assert.Equal(t, uint64(0), compensation)
+
+		//the locked profit covers the slash, the pledge is left whole
+		nodeInfo := getNodeInfo(native, locked.NodeAddr)
+		updateProfitVesting(native, nodeInfo)
+		assert.Equal(t, uint64(50), nodeInfo.Profit)
+		assert.Equal(t, uint64(50), nodeInfo.LockedProfit)
+		assert.Equal(t, locked.Pledge, nodeInfo.Pledge)
+		assert.False(t, nodeUnderPledged(nodeInfo, globalParam))
+
+		//the pledge pays the rest, the node takes no new file until it pledges again
+		nodeInfo = getNodeInfo(native, short.NodeAddr)
+		updateProfitVesting(native, nodeInfo)
+		assert.Equal(t, uint64(0), nodeInfo.Profit)
+		assert.Equal(t, uint64(0), nodeInfo.LockedProfit)
+		assert.Empty(t, getProfitVesting(native, short.NodeAddr).Locks)
+		assert.Equal(t, short.Pledge-(slash-slash/2), nodeInfo.Pledge)
+		assert.True(t, nodeUnderPledged(nodeInfo, globalParam))
+
+		feePool, err := getFeePool(native)
+		assert.Nil(t, err)
+		assert.Equal(t, 2*slash, feePool.InsuranceBalance)
+	})
+}
@@ -64,6 +64,7 @@ func (this *ReadPlan) Deserialization(source *common.ZeroCopySource) error {
 }
 
 func (this *ReadPledge) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
 	sink.WriteVarBytes(this.FileHash)
 	utils.EncodeAddress(sink, this.Downloader)
 	utils.EncodeVarUint(sink, this.BlockHeight)
@@ -81,7 +82,10 @@ func (this *ReadPledge) Serialization(sink *common.ZeroCopySink) {
 }
 
 func (this *ReadPledge) Deserialization(source *common.ZeroCopySource) error {
-	var err error
+	_, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
 	this.FileHash, err = DecodeVarBytes(source)
 	if err != nil {
 		return err
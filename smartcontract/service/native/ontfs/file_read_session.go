@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/errors"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// ReadSessionFile is a file of a read session and the read plans of the nodes serving it
+type ReadSessionFile struct {
+	FileHash  []byte
+	ReadPlans []ReadPlan
+}
+
+// ReadSession pledges a shared budget and expiry for reading a list of files, so a bulk download
+// needs one pledge and one cancel instead of one ReadPledge for every file.
+type ReadSession struct {
+	SessionId    []byte
+	Downloader   common.Address
+	BlockHeight  uint64
+	ExpireHeight uint64
+	RestMoney    uint64
+	Files        []ReadSessionFile
+}
+
+type GetReadSession struct {
+	SessionId  []byte
+	Downloader common.Address
+}
+
+func (this *ReadSessionFile) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.FileHash)
+	utils.EncodeVarUint(sink, uint64(len(this.ReadPlans)))
+	for _, readPlan := range this.ReadPlans {
+		sinkTmp := common.NewZeroCopySink(nil)
+		readPlan.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+}
+
+func (this *ReadSessionFile) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.FileHash, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	planCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < planCount; i++ {
+		readPlanTmp, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		var readPlan ReadPlan
+		if err = readPlan.Deserialization(common.NewZeroCopySource(readPlanTmp)); err != nil {
+			return err
+		}
+		this.ReadPlans = append(this.ReadPlans, readPlan)
+	}
+	return nil
+}
+
+func (this *ReadSession) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
+	sink.WriteVarBytes(this.SessionId)
+	utils.EncodeAddress(sink, this.Downloader)
+	utils.EncodeVarUint(sink, this.BlockHeight)
+	utils.EncodeVarUint(sink, this.ExpireHeight)
+	utils.EncodeVarUint(sink, this.RestMoney)
+	utils.EncodeVarUint(sink, uint64(len(this.Files)))
+	for _, file := range this.Files {
+		sinkTmp := common.NewZeroCopySink(nil)
+		file.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+}
+
+func (this *ReadSession) Deserialization(source *common.ZeroCopySource) error {
+	_, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
+	this.SessionId, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	this.Downloader, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	this.BlockHeight, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.ExpireHeight, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.RestMoney, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	fileCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < fileCount; i++ {
+		fileTmp, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		var file ReadSessionFile
+		if err = file.Deserialization(common.NewZeroCopySource(fileTmp)); err != nil {
+			return err
+		}
+		this.Files = append(this.Files, file)
+	}
+	return nil
+}
+
+func (this *ReadSession) getFile(fileHash []byte) *ReadSessionFile {
+	for i := range this.Files {
+		if string(this.Files[i].FileHash) == string(fileHash) {
+			return &this.Files[i]
+		}
+	}
+	return nil
+}
+
+func (this *GetReadSession) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.SessionId)
+	utils.EncodeAddress(sink, this.Downloader)
+}
+
+func (this *GetReadSession) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.SessionId, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	this.Downloader, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func addReadSession(native *native.NativeService, session *ReadSession) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	key := GenFsReadSessionKey(contract, session.Downloader, session.SessionId)
+	sink := common.NewZeroCopySink(nil)
+	session.Serialization(sink)
+	utils.PutBytes(native, key, sink.Bytes())
+}
+
+func getRawReadSession(native *native.NativeService, downloader common.Address, sessionId []byte) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	key := GenFsReadSessionKey(contract, downloader, sessionId)
+	item, err := utils.GetStorageItem(native, key)
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "getRawReadSession GetStorageItem error!")
+	}
+	if item == nil {
+		return nil, errors.NewErr("getRawReadSession not found!")
+	}
+	return item.Value, nil
+}
+
+func getReadSession(native *native.NativeService, downloader common.Address, sessionId []byte) (*ReadSession, error) {
+	data, err := getRawReadSession(native, downloader, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var session ReadSession
+	if err = session.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "getReadSession Deserialization error!")
+	}
+	return &session, nil
+}
+
+func delReadSession(native *native.NativeService, downloader common.Address, sessionId []byte) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	key := GenFsReadSessionKey(contract, downloader, sessionId)
+	native.CacheDB.Delete(key)
+}
@@ -43,6 +43,21 @@ func (this *FileReadSettleSlice) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteVarBytes(this.PubKey)
 }
 
+// SignData returns the data PayFrom signs, the slice serialized without its signature and public key
+func (this *FileReadSettleSlice) SignData() []byte {
+	settleSliceTmp := FileReadSettleSlice{
+		FileHash:     this.FileHash,
+		PayFrom:      this.PayFrom,
+		PayTo:        this.PayTo,
+		SliceId:      this.SliceId,
+		PledgeHeight: this.PledgeHeight,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	settleSliceTmp.Serialization(sink)
+	return sink.Bytes()
+}
+
 func (this *FileReadSettleSlice) Deserialization(source *common.ZeroCopySource) error {
 	var err error
 	this.FileHash, err = DecodeVarBytes(source)
@@ -75,3 +90,64 @@ func (this *FileReadSettleSlice) Deserialization(source *common.ZeroCopySource)
 	}
 	return nil
 }
+
+// ReadSessionSettleSlice settles the reading of a file inside a read session, SliceId counts the
+// blocks of that file read from PayTo
+type ReadSessionSettleSlice struct {
+	SessionId    []byte
+	FileHash     []byte
+	PayFrom      common.Address
+	PayTo        common.Address
+	SliceId      uint64
+	PledgeHeight uint64
+	Sig          []byte
+	PubKey       []byte
+}
+
+func (this *ReadSessionSettleSlice) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.SessionId)
+	sink.WriteVarBytes(this.FileHash)
+	utils.EncodeAddress(sink, this.PayFrom)
+	utils.EncodeAddress(sink, this.PayTo)
+	utils.EncodeVarUint(sink, this.SliceId)
+	utils.EncodeVarUint(sink, this.PledgeHeight)
+	sink.WriteVarBytes(this.Sig)
+	sink.WriteVarBytes(this.PubKey)
+}
+
+func (this *ReadSessionSettleSlice) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.SessionId, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	this.FileHash, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	this.PayFrom, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	this.PayTo, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	this.SliceId, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.PledgeHeight, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.Sig, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	this.PubKey, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
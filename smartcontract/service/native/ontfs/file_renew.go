@@ -19,6 +19,8 @@
 package ontfs
 
 import (
+	"fmt"
+
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 )
@@ -94,3 +96,23 @@ func (this *FileReNewList) Deserialization(source *common.ZeroCopySource) error
 	}
 	return nil
 }
+
+// renewFileInfo extends fileInfo to newTimeExpired and returns the fee to be paid for it
+func renewFileInfo(fileInfo *FileInfo, newTimeExpired uint64, gasPerKbForSaveWithFile uint64) (uint64, error) {
+	if newTimeExpired <= fileInfo.TimeStart {
+		return 0, fmt.Errorf("newTimeExpired <= fileInfo.TimeStart")
+	}
+
+	oriTimeExpired := fileInfo.TimeExpired
+	fileInfo.TimeExpired = newTimeExpired
+	newFee := calcTotalFilePayAmountByFile(fileInfo, gasPerKbForSaveWithFile)
+	if newFee < fileInfo.PayAmount {
+		fileInfo.TimeExpired = oriTimeExpired
+		return 0, fmt.Errorf("newFee < fileInfo.PayAmount")
+	}
+
+	renewFee := newFee - fileInfo.PayAmount
+	fileInfo.PayAmount = newFee
+	fileInfo.RestAmount += renewFee
+	return renewFee, nil
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+type FileUpdate struct {
+	FileHash       []byte
+	FileOwner      common.Address
+	NewCopyNumber  uint64 //zero means unchanged
+	NewPdpInterval uint64 //zero means unchanged
+}
+
+type FileUpdateList struct {
+	FilesUpdate []FileUpdate
+}
+
+func (this *FileUpdate) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.FileHash)
+	utils.EncodeAddress(sink, this.FileOwner)
+	utils.EncodeVarUint(sink, this.NewCopyNumber)
+	utils.EncodeVarUint(sink, this.NewPdpInterval)
+}
+
+func (this *FileUpdate) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.FileHash, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	this.FileOwner, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	this.NewCopyNumber, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.NewPdpInterval, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *FileUpdateList) Serialization(sink *common.ZeroCopySink) {
+	fileUpdateCount := uint64(len(this.FilesUpdate))
+	utils.EncodeVarUint(sink, fileUpdateCount)
+
+	for _, fileUpdate := range this.FilesUpdate {
+		sinkTmp := common.NewZeroCopySink(nil)
+		fileUpdate.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+}
+
+func (this *FileUpdateList) Deserialization(source *common.ZeroCopySource) error {
+	fileUpdateCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < fileUpdateCount; i++ {
+		fileUpdateTmp, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+
+		var fileUpdate FileUpdate
+		src := common.NewZeroCopySource(fileUpdateTmp)
+		if err = fileUpdate.Deserialization(src); err != nil {
+			return err
+		}
+		this.FilesUpdate = append(this.FilesUpdate, fileUpdate)
+	}
+	return nil
+}
+
+// updateFileInfo applies the new copy number and pdp interval to fileInfo. Only the pdp windows left
+// until the file expires are priced again, at the profit a node gets for every pdp of the file, so the
+// windows already proved are neither charged nor refunded. It returns the fee difference and whether
+// that difference has to be refunded to the file owner
+func updateFileInfo(fileInfo *FileInfo, newCopyNumber uint64, newPdpInterval uint64, currTime uint64) (uint64, bool, error) {
+	if currTime >= fileInfo.TimeExpired {
+		return 0, false, fmt.Errorf("file is expired")
+	}
+	oncePdpProfit := calcPerFileOncePdpProfitByFile(fileInfo)
+	oldRestPayAmount := calcRestFilePdpCount(fileInfo, currTime) * fileInfo.CopyNumber * oncePdpProfit
+
+	if newCopyNumber != 0 {
+		fileInfo.CopyNumber = newCopyNumber
+	}
+	if newPdpInterval != 0 {
+		fileInfo.PdpInterval = newPdpInterval
+	}
+	newRestPayAmount := calcRestFilePdpCount(fileInfo, currTime) * fileInfo.CopyNumber * oncePdpProfit
+
+	var newFee uint64
+	var refund bool
+	if newRestPayAmount > oldRestPayAmount {
+		newFee = newRestPayAmount - oldRestPayAmount
+		fileInfo.RestAmount += newFee
+	} else if newRestPayAmount < oldRestPayAmount {
+		//what is left has to pay the remaining windows of the remaining copies
+		newFee = oldRestPayAmount - newRestPayAmount
+		if fileInfo.RestAmount < newRestPayAmount+newFee {
+			if fileInfo.RestAmount > newRestPayAmount {
+				newFee = fileInfo.RestAmount - newRestPayAmount
+			} else {
+				newFee = 0
+			}
+		}
+		refund = newFee != 0
+		fileInfo.RestAmount -= newFee
+	}
+	//keeps the profit of every pdp for the nodes already storing the file
+	fileInfo.PayAmount = calcFilePdpNeedCount(fileInfo) * fileInfo.CopyNumber * oncePdpProfit
+	return newFee, refund, nil
+}
+
+// releasePdpSlots drops the pdp records exceeding the file's copy number and gives their volume back to
+// the nodes. The nodes which proved the file the most times keep storing it.
+func releasePdpSlots(native *native.NativeService, fileInfo *FileInfo) error {
+	pdpRecordList := getPdpRecordList(native, fileInfo.FileHash, fileInfo.FileOwner)
+
+	var activeRecords []PdpRecord
+	for _, pdpRecord := range pdpRecordList.PdpRecords {
+		if !pdpRecord.SettleFlag {
+			activeRecords = append(activeRecords, pdpRecord)
+		}
+	}
+	if uint64(len(activeRecords)) <= fileInfo.CopyNumber {
+		return nil
+	}
+	sort.SliceStable(activeRecords, func(i, j int) bool {
+		if activeRecords[i].PdpCount != activeRecords[j].PdpCount {
+			return activeRecords[i].PdpCount > activeRecords[j].PdpCount
+		}
+		if activeRecords[i].LastPdpTime != activeRecords[j].LastPdpTime {
+			return activeRecords[i].LastPdpTime > activeRecords[j].LastPdpTime
+		}
+		return bytes.Compare(activeRecords[i].NodeAddr[:], activeRecords[j].NodeAddr[:]) < 0
+	})
+
+	for _, pdpRecord := range activeRecords[fileInfo.CopyNumber:] {
+		nodeInfo := getNodeInfo(native, pdpRecord.NodeAddr)
+		if nodeInfo == nil {
+			return fmt.Errorf("getNodeInfo error")
+		}
+		nodeInfo.RestVol += fileVolume(fileInfo)
+		nodeInfo.Reputation.addMissedWindows(uint64(native.Time),
+			countRecordMissedWindows(fileInfo, &pdpRecord, uint64(native.Time)))
+		addNodeInfo(native, nodeInfo)
+		delPdpRecord(native, pdpRecord.FileHash, pdpRecord.FileOwner, pdpRecord.NodeAddr)
+	}
+	return nil
+}
+
+// checkNodesPdpInterval checks that every node storing the file accepts the file's pdp interval
+func checkNodesPdpInterval(native *native.NativeService, fileInfo *FileInfo) error {
+	pdpRecordList := getPdpRecordList(native, fileInfo.FileHash, fileInfo.FileOwner)
+	for _, pdpRecord := range pdpRecordList.PdpRecords {
+		if pdpRecord.SettleFlag {
+			continue
+		}
+		nodeInfo := getNodeInfo(native, pdpRecord.NodeAddr)
+		if nodeInfo == nil {
+			return fmt.Errorf("getNodeInfo error")
+		}
+		if fileInfo.PdpInterval < nodeInfo.MinPdpInterval {
+			return fmt.Errorf("PdpInterval less than node %s MinPdpInterval", nodeInfo.NodeAddr.ToBase58())
+		}
+	}
+	return nil
+}
+
+func activePdpRecordCount(native *native.NativeService, fileHash []byte, fileOwner common.Address) uint64 {
+	var count uint64
+	pdpRecordList := getPdpRecordList(native, fileHash, fileOwner)
+	for _, pdpRecord := range pdpRecordList.PdpRecords {
+		if !pdpRecord.SettleFlag {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	updatePdpInterval = 100
+	updateGasPerKb    = 10
+)
+
+// updateTestFile returns a file of 10 pdp intervals stored in two copies, both proved for the first
+// half of its life, and the profit of every pdp
+func updateTestFile() (*FileInfo, uint64) {
+	fileInfo := &FileInfo{
+		FileBlockCount: 8,
+		BlockSize:      DefaultPerBlockSize,
+		CopyNumber:     2,
+		PdpInterval:    updatePdpInterval,
+		TimeStart:      0,
+		TimeExpired:    10 * updatePdpInterval,
+		StorageType:    FileStorageTypeUseFile,
+	}
+	fileInfo.PayAmount = calcTotalFilePayAmountByFile(fileInfo, updateGasPerKb)
+	oncePdpProfit := calcPerFileOncePdpProfitByFile(fileInfo)
+	fileInfo.FileCost = 2 * 5 * oncePdpProfit
+	fileInfo.RestAmount = fileInfo.PayAmount - fileInfo.FileCost
+	return fileInfo, oncePdpProfit
+}
+
+func TestUpdateFileInfo(t *testing.T) {
+	currTime := uint64(5 * updatePdpInterval)
+	restPdpCount := uint64(6)
+
+	//lowering the copy number refunds the remaining windows of the dropped copy only
+	fileInfo, oncePdpProfit := updateTestFile()
+	assert.Equal(t, fileVolume(fileInfo)*updateGasPerKb, oncePdpProfit)
+	restAmount := fileInfo.RestAmount
+	newFee, refund, err := updateFileInfo(fileInfo, 1, 0, currTime)
+	assert.Nil(t, err)
+	assert.True(t, refund)
+	assert.Equal(t, restPdpCount*oncePdpProfit, newFee)
+	assert.Equal(t, restAmount-newFee, fileInfo.RestAmount)
+	assert.Equal(t, restPdpCount*oncePdpProfit, fileInfo.RestAmount)
+	assert.Equal(t, oncePdpProfit, calcPerFileOncePdpProfitByFile(fileInfo))
+
+	//raising it charges the remaining windows of the new copy only
+	fileInfo, oncePdpProfit = updateTestFile()
+	restAmount = fileInfo.RestAmount
+	newFee, refund, err = updateFileInfo(fileInfo, 3, 0, currTime)
+	assert.Nil(t, err)
+	assert.False(t, refund)
+	assert.Equal(t, restPdpCount*oncePdpProfit, newFee)
+	assert.Equal(t, restAmount+newFee, fileInfo.RestAmount)
+	assert.Equal(t, 3*restPdpCount*oncePdpProfit, fileInfo.RestAmount)
+	assert.Equal(t, oncePdpProfit, calcPerFileOncePdpProfitByFile(fileInfo))
+
+	//a longer interval leaves fewer windows to pay, every pdp keeps its profit
+	fileInfo, oncePdpProfit = updateTestFile()
+	restAmount = fileInfo.RestAmount
+	newFee, refund, err = updateFileInfo(fileInfo, 0, 2*updatePdpInterval, currTime)
+	assert.Nil(t, err)
+	assert.True(t, refund)
+	assert.Equal(t, 2*(restPdpCount-3)*oncePdpProfit, newFee)
+	assert.Equal(t, restAmount-newFee, fileInfo.RestAmount)
+	assert.Equal(t, 2*3*oncePdpProfit, fileInfo.RestAmount)
+	assert.Equal(t, oncePdpProfit, calcPerFileOncePdpProfitByFile(fileInfo))
+
+	//a shorter one charges the windows added
+	fileInfo, oncePdpProfit = updateTestFile()
+	restAmount = fileInfo.RestAmount
+	newFee, refund, err = updateFileInfo(fileInfo, 0, updatePdpInterval/2, currTime)
+	assert.Nil(t, err)
+	assert.False(t, refund)
+	assert.Equal(t, 2*(11-restPdpCount)*oncePdpProfit, newFee)
+	assert.Equal(t, restAmount+newFee, fileInfo.RestAmount)
+	assert.Equal(t, oncePdpProfit, calcPerFileOncePdpProfitByFile(fileInfo))
+
+	//a refund never leaves the remaining copies unpaid
+	fileInfo, oncePdpProfit = updateTestFile()
+	fileInfo.RestAmount = 7 * oncePdpProfit
+	newFee, refund, err = updateFileInfo(fileInfo, 1, 0, currTime)
+	assert.Nil(t, err)
+	assert.True(t, refund)
+	assert.Equal(t, oncePdpProfit, newFee)
+	assert.Equal(t, restPdpCount*oncePdpProfit, fileInfo.RestAmount)
+
+	fileInfo, _ = updateTestFile()
+	_, _, err = updateFileInfo(fileInfo, 1, 0, fileInfo.TimeExpired)
+	assert.NotNil(t, err)
+}
+
+func TestReleasePdpSlots(t *testing.T) {
+	chain, err := testsuite.NewChain(1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileInfo, _ := updateTestFile()
+	fileInfo.FileHash = []byte("file")
+	fileInfo.FileOwner = common.Address{0x01}
+	fileInfo.CopyNumber = 2
+
+	records := []PdpRecord{
+		{NodeAddr: common.Address{0x10}, PdpCount: 1, LastPdpTime: 300},
+		{NodeAddr: common.Address{0x11}, PdpCount: 5, LastPdpTime: 500},
+		{NodeAddr: common.Address{0x12}, PdpCount: 9, SettleFlag: true},
+		{NodeAddr: common.Address{0x13}, PdpCount: 5, LastPdpTime: 400},
+		{NodeAddr: common.Address{0x14}, PdpCount: 3, LastPdpTime: 500},
+	}
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		for i := range records {
+			records[i].FileHash = fileInfo.FileHash
+			records[i].FileOwner = fileInfo.FileOwner
+			addPdpRecord(native, &records[i])
+			addNodeInfo(native, &FsNodeInfo{NodeAddr: records[i].NodeAddr})
+		}
+		assert.Nil(t, releasePdpSlots(native, fileInfo))
+
+		//the nodes with the most pdps keep the file, the settled record is left alone
+		kept := make(map[common.Address]bool)
+		for _, pdpRecord := range getPdpRecordList(native, fileInfo.FileHash, fileInfo.FileOwner).PdpRecords {
+			kept[pdpRecord.NodeAddr] = true
+		}
+		assert.Equal(t, map[common.Address]bool{{0x11}: true, {0x12}: true, {0x13}: true}, kept)
+		for _, nodeAddr := range []common.Address{{0x10}, {0x14}} {
+			assert.Equal(t, fileVolume(fileInfo), getNodeInfo(native, nodeAddr).RestVol)
+		}
+		//the windows missed by a dropped node count against it
+		assert.Equal(t, uint64(6), getNodeInfo(native, common.Address{0x10}).Reputation.MissedWindows)
+		assert.Equal(t, uint64(4), getNodeInfo(native, common.Address{0x14}).Reputation.MissedWindows)
+		assert.Equal(t, uint64(0), getNodeInfo(native, common.Address{0x13}).RestVol)
+	})
+}
@@ -19,6 +19,8 @@
 package ontfs
 
 import (
+	"fmt"
+
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/smartcontract/service/native"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
@@ -42,6 +44,24 @@ func (this *WhiteList) Serialization(sink *common.ZeroCopySink) {
 	}
 }
 
+// decodeStoredWhiteList decodes the white list as setWhiteList stores it, with raw addresses
+func decodeStoredWhiteList(data []byte) (*WhiteList, error) {
+	source := common.NewZeroCopySource(data)
+	ruleCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return nil, err
+	}
+	whiteList := new(WhiteList)
+	for index := uint64(0); index < ruleCount; index++ {
+		userAddr, eof := source.NextAddress()
+		if eof {
+			return nil, fmt.Errorf("decodeStoredWhiteList error: eof")
+		}
+		whiteList.UsersAddr = append(whiteList.UsersAddr, userAddr)
+	}
+	return whiteList, nil
+}
+
 func (this *WhiteList) Deserialization(source *common.ZeroCopySource) error {
 	ruleCount, err := utils.DecodeVarUint(source)
 	if err != nil {
@@ -113,12 +133,11 @@ func getWhiteList(native *native.NativeService, fileOwner common.Address, fileHa
 		return nil
 	}
 
-	var whiteList WhiteList
-	source := common.NewZeroCopySource(item.Value)
-	if err := whiteList.Deserialization(source); err != nil {
+	whiteList, err := decodeStoredWhiteList(item.Value)
+	if err != nil {
 		return nil
 	}
-	return &whiteList
+	return whiteList
 }
 
 func delWhiteList(native *native.NativeService, fileOwner common.Address, fileHash []byte) {
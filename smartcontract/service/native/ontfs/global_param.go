@@ -32,6 +32,11 @@ type FsGlobalParam struct {
 	GasPerKbForRead          uint64 //cost for ontfs-sdk read from fsNode
 	GasPerKbForSaveWithFile  uint64 //cost for ontfs-sdk save from fsNode
 	GasPerKbForSaveWithSpace uint64 //cost for ontfs-sdk save from fsNode
+	MinPerBlockSize          uint64 //kb. min block size of a file
+	MaxPerBlockSize          uint64 //kb. max block size of a file
+	ProfitLockRate           uint64 //percent of every node profit locked after it is earned
+	ProfitLockPeriod         uint64 //second. time a locked node profit takes to vest
+	PassportExpire           uint64 //block count. a passport is valid for this count of blocks after its height
 }
 
 func (this *FsGlobalParam) Serialization(sink *common.ZeroCopySink) {
@@ -41,6 +46,11 @@ func (this *FsGlobalParam) Serialization(sink *common.ZeroCopySink) {
 	utils.EncodeVarUint(sink, this.GasPerKbForRead)
 	utils.EncodeVarUint(sink, this.GasPerKbForSaveWithFile)
 	utils.EncodeVarUint(sink, this.GasPerKbForSaveWithSpace)
+	utils.EncodeVarUint(sink, this.MinPerBlockSize)
+	utils.EncodeVarUint(sink, this.MaxPerBlockSize)
+	utils.EncodeVarUint(sink, this.ProfitLockRate)
+	utils.EncodeVarUint(sink, this.ProfitLockPeriod)
+	utils.EncodeVarUint(sink, this.PassportExpire)
 }
 
 func (this *FsGlobalParam) Deserialization(source *common.ZeroCopySource) error {
@@ -69,6 +79,44 @@ func (this *FsGlobalParam) Deserialization(source *common.ZeroCopySource) error
 	if err != nil {
 		return err
 	}
+	if source.Len() == 0 {
+		this.MinPerBlockSize = DefaultMinPerBlockSize
+		this.MaxPerBlockSize = DefaultMaxPerBlockSize
+		this.ProfitLockRate = DefaultProfitLockRate
+		this.ProfitLockPeriod = DefaultProfitLockPeriod
+		this.PassportExpire = DefaultPassportExpire
+		return nil
+	}
+	this.MinPerBlockSize, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.MaxPerBlockSize, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	if source.Len() == 0 {
+		this.ProfitLockRate = DefaultProfitLockRate
+		this.ProfitLockPeriod = DefaultProfitLockPeriod
+		this.PassportExpire = DefaultPassportExpire
+		return nil
+	}
+	this.ProfitLockRate, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.ProfitLockPeriod, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	if source.Len() == 0 {
+		this.PassportExpire = DefaultPassportExpire
+		return nil
+	}
+	this.PassportExpire, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
 	return err
 }
 
@@ -95,6 +143,11 @@ func getGlobalParam(native *native.NativeService) (*FsGlobalParam, error) {
 			GasPerKbForRead:          DefaultGasPerKbForRead,
 			GasPerKbForSaveWithFile:  DefaultGasPerKbForSaveWithFile,
 			GasPerKbForSaveWithSpace: DefaultGasPerKbForSaveWithSpace,
+			MinPerBlockSize:          DefaultMinPerBlockSize,
+			MaxPerBlockSize:          DefaultMaxPerBlockSize,
+			ProfitLockRate:           DefaultProfitLockRate,
+			ProfitLockPeriod:         DefaultProfitLockPeriod,
+			PassportExpire:           DefaultPassportExpire,
 		}
 		return &globalParam, nil
 	}
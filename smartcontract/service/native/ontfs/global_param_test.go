@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFsSetGlobalParam_BlockSizeRange(t *testing.T) {
+	chain, err := testsuite.NewChain(100, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setParam := func(minPerBlockSize uint64, maxPerBlockSize uint64) error {
+		var err error
+		chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+			globalParam, _ := getGlobalParam(native)
+			globalParam.MinPerBlockSize = minPerBlockSize
+			globalParam.MaxPerBlockSize = maxPerBlockSize
+			sink := common.NewZeroCopySink(nil)
+			globalParam.Serialization(sink)
+			native.Input = sink.Bytes()
+			_, err = FsSetGlobalParam(native)
+		})
+		return err
+	}
+
+	assert.Nil(t, setParam(DefaultPerBlockSize, DefaultPerBlockSize))
+	assert.Nil(t, setParam(DefaultMinPerBlockSize, DefaultMaxPerBlockSize))
+	assert.Error(t, setParam(DefaultMaxPerBlockSize, DefaultMinPerBlockSize))
+}
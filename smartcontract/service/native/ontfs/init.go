@@ -22,6 +22,7 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/errors"
 	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/global_params"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 )
 
@@ -32,6 +33,7 @@ func InitFs() {
 func RegisterFsContract(native *native.NativeService) {
 	//native.Register(FS_SET_GLOBAL_PARAM, FsSetGlobalParam)
 	native.Register(FS_GET_GLOBAL_PARAM, FsGetGlobalParam)
+	native.Register(FS_SET_PASSPORT_EXPIRE, FsSetPassportExpire)
 
 	native.Register(FS_NODE_REGISTER, FsNodeRegister)
 	native.Register(FS_NODE_QUERY, FsNodeQuery)
@@ -39,6 +41,7 @@ func RegisterFsContract(native *native.NativeService) {
 	native.Register(FS_NODE_CANCEL, FsNodeCancel)
 	native.Register(FS_FILE_PROVE, FsFileProve)
 	native.Register(FS_NODE_WITH_DRAW_PROFIT, FsNodeWithDrawProfit)
+	native.Register(FS_NODE_ATTEST, FsNodeAttest)
 
 	native.Register(FS_GET_NODE_LIST, FsGetNodeInfoList)
 	native.Register(FS_GET_PDP_INFO_LIST, FsGetPdpInfoList)
@@ -46,15 +49,21 @@ func RegisterFsContract(native *native.NativeService) {
 	native.Register(FS_STORE_FILES, FsStoreFiles)
 	native.Register(FS_RENEW_FILES, FsRenewFiles)
 	native.Register(FS_DELETE_FILES, FsDeleteFiles)
+	native.Register(FS_UPDATE_FILES, FsUpdateFiles)
 	native.Register(FS_TRANSFER_FILES, FsTransferFiles)
 
 	native.Register(FS_GET_FILE_INFO, FsGetFileInfo)
 	native.Register(FS_GET_FILE_LIST, FsGetFileHashList)
+	native.Register(FS_GET_FILE_CID, FsGetFileCid)
 
 	native.Register(FS_READ_FILE_PLEDGE, FsReadFilePledge)
 	native.Register(FS_READ_FILE_SETTLE, FsReadFileSettle)
 	native.Register(FS_GET_READ_PLEDGE, FsGetReadPledge)
 	native.Register(FS_CANCEL_FILE_READ, FsCancelFileRead)
+	native.Register(FS_READ_SESSION_PLEDGE, FsReadSessionPledge)
+	native.Register(FS_READ_SESSION_SETTLE, FsReadSessionSettle)
+	native.Register(FS_GET_READ_SESSION, FsGetReadSession)
+	native.Register(FS_CANCEL_READ_SESSION, FsCancelReadSession)
 
 	native.Register(FS_SET_WHITE_LIST, FsSetWhiteList)
 	native.Register(FS_GET_WHITE_LIST, FsGetWhiteList)
@@ -62,7 +71,29 @@ func RegisterFsContract(native *native.NativeService) {
 	native.Register(FS_CREATE_SPACE, FsCreateSpace)
 	native.Register(FS_DELETE_SPACE, FsDeleteSpace)
 	native.Register(FS_UPDATE_SPACE, FsUpdateSpace)
+	native.Register(FS_TOP_UP_SPACE, FsTopUpSpace)
+	native.Register(FS_GET_SPACE_FUNDING, FsGetSpaceFunding)
 	native.Register(FS_GET_SPACE_INFO, FsGetSpaceInfo)
+
+	native.Register(FS_SET_RENEW_ESCROW, FsSetRenewEscrow)
+	native.Register(FS_CANCEL_RENEW_ESCROW, FsCancelRenewEscrow)
+	native.Register(FS_GET_RENEW_ESCROW, FsGetRenewEscrow)
+	native.Register(FS_TRIGGER_RENEW, FsTriggerRenew)
+	//the audit scans every record of the contract, it is only served to pre-executed queries
+	if native.PreExec {
+		native.Register(FS_AUDIT, FsAudit)
+	}
+	native.Register(FS_SET_KEY_ENVELOPES, FsSetKeyEnvelopes)
+	native.Register(FS_REVOKE_KEY_ENVELOPES, FsRevokeKeyEnvelopes)
+	native.Register(FS_GET_KEY_ENVELOPE, FsGetKeyEnvelope)
+
+	native.Register(FS_SET_FEE_CONFIG, FsSetFeeConfig)
+	native.Register(FS_GET_FEE_CONFIG, FsGetFeeConfig)
+	native.Register(FS_GET_FEE_POOL, FsGetFeePool)
+	native.Register(FS_WITHDRAW_TREASURY, FsWithdrawTreasury)
+	native.Register(FS_CLAIM_FILE_LOSS, FsClaimFileLoss)
+	native.Register(FS_SET_PASSPORT_DELEGATES, FsSetPassportDelegates)
+	native.Register(FS_GET_PASSPORT_DELEGATES, FsGetPassportDelegates)
 }
 
 func FsSetGlobalParam(native *native.NativeService) ([]byte, error) {
@@ -71,10 +102,48 @@ func FsSetGlobalParam(native *native.NativeService) ([]byte, error) {
 	if err := globalParam.Deserialization(infoSource); err != nil {
 		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[FS Init] FsSetGlobalParam Deserialization error!")
 	}
+	if globalParam.ProfitLockRate > 100 {
+		return utils.BYTE_FALSE, errors.NewErr("[FS Init] FsSetGlobalParam ProfitLockRate > 100!")
+	}
+	if globalParam.PassportExpire == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[FS Init] FsSetGlobalParam PassportExpire is 0!")
+	}
+	if globalParam.MinPerBlockSize > globalParam.MaxPerBlockSize {
+		return utils.BYTE_FALSE, errors.NewErr("[FS Init] FsSetGlobalParam MinPerBlockSize > MaxPerBlockSize!")
+	}
 	setGlobalParam(native, &globalParam)
 	return utils.BYTE_TRUE, nil
 }
 
+// FsSetPassportExpire lets governance change the validity window of passports
+func FsSetPassportExpire(native *native.NativeService) ([]byte, error) {
+	source := common.NewZeroCopySource(native.Input)
+	passportExpire, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[FS Init] FsSetPassportExpire DecodeVarUint error!")
+	}
+	if passportExpire == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[FS Init] FsSetPassportExpire PassportExpire is 0!")
+	}
+
+	adminAddress, err := global_params.GetStorageRole(native,
+		global_params.GenerateOperatorKey(utils.ParamContractAddress))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[FS Init] FsSetPassportExpire get admin error!")
+	}
+	if err = utils.ValidateOwner(native, adminAddress); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[FS Init] FsSetPassportExpire checkWitness error!")
+	}
+
+	globalParam, err := getGlobalParam(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[FS Init] FsSetPassportExpire getGlobalParam error!")
+	}
+	globalParam.PassportExpire = passportExpire
+	setGlobalParam(native, globalParam)
+	return utils.BYTE_TRUE, nil
+}
+
 func FsGetGlobalParam(native *native.NativeService) ([]byte, error) {
 	globalParam, err := getGlobalParam(native)
 	if err != nil || globalParam == nil {
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/context"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// PdpStatus summarizes the proofs the storage nodes of a file have submitted
+type PdpStatus struct {
+	CopyNumber   uint64
+	NodeCount    uint64 // nodes which hold a pdp record of the file
+	ProvedCount  uint64 // nodes which have proved the file at least once
+	SettledCount uint64
+	LastPdpTime  uint64
+	NextHeight   uint64 // earliest base challenge height of the unsettled nodes
+}
+
+func (this *PdpStatus) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.CopyNumber)
+	utils.EncodeVarUint(sink, this.NodeCount)
+	utils.EncodeVarUint(sink, this.ProvedCount)
+	utils.EncodeVarUint(sink, this.SettledCount)
+	utils.EncodeVarUint(sink, this.LastPdpTime)
+	utils.EncodeVarUint(sink, this.NextHeight)
+}
+
+// InteropCall runs a read only query of the vm interop services under the ontfs contract context
+func InteropCall(native *native.NativeService, query func(native *native.NativeService)) {
+	native.ContextRef.PushContext(&context.Context{ContractAddress: utils.OntFSContractAddress})
+	query(native)
+	native.ContextRef.PopContext()
+}
+
+// InteropGetFileInfo returns the file info with ValidFlag reflecting expiry, without writing it back
+func InteropGetFileInfo(native *native.NativeService, fileHash []byte) *FileInfo {
+	fileOwner, err := getFileOwner(native, fileHash)
+	if err != nil {
+		return nil
+	}
+	fileInfo := getFileInfoFromDb(native, fileOwner, fileHash)
+	if fileInfo == nil {
+		return nil
+	}
+	if uint64(native.Time) > fileInfo.TimeExpired {
+		fileInfo.ValidFlag = false
+	}
+	return fileInfo
+}
+
+// InteropGetFileOwner returns the owner of the file
+func InteropGetFileOwner(native *native.NativeService, fileHash []byte) (common.Address, bool) {
+	fileOwner, err := getFileOwner(native, fileHash)
+	if err != nil {
+		return common.ADDRESS_EMPTY, false
+	}
+	return fileOwner, true
+}
+
+// InteropGetPdpStatus returns the pdp status of the file, nil if the file is not stored
+func InteropGetPdpStatus(native *native.NativeService, fileHash []byte) *PdpStatus {
+	fileInfo := InteropGetFileInfo(native, fileHash)
+	if fileInfo == nil {
+		return nil
+	}
+
+	status := &PdpStatus{CopyNumber: fileInfo.CopyNumber}
+	pdpRecordList := getPdpRecordList(native, fileHash, fileInfo.FileOwner)
+	for _, pdpRecord := range pdpRecordList.PdpRecords {
+		status.NodeCount++
+		if pdpRecord.PdpCount > 0 {
+			status.ProvedCount++
+		}
+		if pdpRecord.LastPdpTime > status.LastPdpTime {
+			status.LastPdpTime = pdpRecord.LastPdpTime
+		}
+		if pdpRecord.SettleFlag {
+			status.SettledCount++
+			continue
+		}
+		if status.NextHeight == 0 || pdpRecord.NextHeight < status.NextHeight {
+			status.NextHeight = pdpRecord.NextHeight
+		}
+	}
+	return status
+}
+
+// InteropIsFileValid reports whether the file is unexpired and proved by at least one node
+func InteropIsFileValid(native *native.NativeService, fileHash []byte) bool {
+	fileInfo := InteropGetFileInfo(native, fileHash)
+	if fileInfo == nil || !fileInfo.ValidFlag {
+		return false
+	}
+	status := InteropGetPdpStatus(native, fileHash)
+	return status != nil && status.ProvedCount > 0
+}
+
+// InteropIsWhitelisted reports whether addr may read the file, the owner always may
+func InteropIsWhitelisted(native *native.NativeService, fileHash []byte, addr common.Address) bool {
+	fileOwner, err := getFileOwner(native, fileHash)
+	if err != nil {
+		return false
+	}
+	if fileOwner == addr {
+		return true
+	}
+	return inWhiteList(getWhiteList(native, fileOwner, fileHash), addr)
+}
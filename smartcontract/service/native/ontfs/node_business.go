@@ -46,6 +46,8 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve getGlobalParam error!")
 	}
 
+	tryAutoRenew(native, pdpData.FileHash)
+
 	fileInfo := getFileInfoByHash(native, pdpData.FileHash)
 	if fileInfo == nil {
 		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve getFileInfoByHash error!")
@@ -72,15 +74,23 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 			log.Info("[Node Business] FsFileProve FirstPdp is false, checkPdpData skip.")
 		}
 
+		if activePdpRecordCount(native, fileInfo.FileHash, fileInfo.FileOwner) >= fileInfo.CopyNumber {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve file CopyNumber reached!")
+		}
+
 		pdpRecord = &PdpRecord{NodeAddr: pdpData.NodeAddr, FileHash: pdpData.FileHash,
 			FileOwner: fileInfo.FileOwner, PdpCount: 0, LastPdpTime: currPdpEndPoint,
-			NextHeight: uint64(native.Height) + DefaultPdpHeightIV, SettleFlag: false}
+			SettleFlag: false}
+		pdpRecord.NextHeight = nextChallengeHeight(native)
 
-		if nodeInfo.RestVol < fileInfo.FileBlockCount*DefaultPerBlockSize {
+		if nodeInfo.RestVol < fileVolume(fileInfo) {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve space RestVol not enough error!")
 		}
+		if nodeUnderPledged(nodeInfo, globalParam) {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve node pledge slashed, pledge again!")
+		}
 
-		nodeInfo.RestVol -= fileInfo.FileBlockCount * DefaultPerBlockSize
+		nodeInfo.RestVol -= fileVolume(fileInfo)
 	} else {
 		if pdpRecord.SettleFlag {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve pdp finished!")
@@ -88,7 +98,11 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 		if uint64(native.Time) <= pdpRecord.LastPdpTime {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve already FileProve!")
 		}
-		if pdpData.ChallengeHeight != pdpRecord.NextHeight {
+		challenge, err := challengeHeight(native, pdpRecord)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("[Node Business] FsFileProve challengeHeight error: %s", err.Error())
+		}
+		if pdpData.ChallengeHeight != challenge {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve pdpData ChallengeHeight error!")
 		}
 		if err = checkPdpData(native, &pdpData, fileInfo); err != nil {
@@ -96,9 +110,13 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 				err.Error())
 		}
 
+		onTime := uint64(native.Height) <= challenge+DefaultPdpHeightIV
+		nodeInfo.Reputation.addProve(uint64(native.Time), onTime,
+			countMissedWindows(pdpRecord.LastPdpTime, currPdpEndPoint, fileInfo.PdpInterval))
+
 		pdpRecord.PdpCount += 1
 		pdpRecord.LastPdpTime = currPdpEndPoint
-		pdpRecord.NextHeight = uint64(native.Height) + DefaultPdpHeightIV
+		pdpRecord.NextHeight = nextChallengeHeight(native)
 
 		var oncePdpProfit uint64
 		if fileInfo.StorageType == FileStorageTypeUseFile {
@@ -123,12 +141,14 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 		} else {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve file storage type error!")
 		}
-		nodeInfo.Profit += oncePdpProfit
+		if err = addNodeProfit(native, nodeInfo, oncePdpProfit, globalParam); err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve addNodeProfit error: " + err.Error())
+		}
 	}
 
 	//file become due, start settlement
 	if !fileInfo.ValidFlag {
-		nodeInfo.RestVol += fileInfo.FileBlockCount * DefaultPerBlockSize
+		nodeInfo.RestVol += fileVolume(fileInfo)
 		pdpRecord.SettleFlag = true
 	}
 
@@ -138,6 +158,27 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_TRUE, nil
 }
 
+//tryAutoRenew renews the file (or its space) from the owner's renew escrow when it is near expiry
+func tryAutoRenew(native *native.NativeService, fileHash []byte) {
+	fileInfo := getFileInfoByHash(native, fileHash)
+	if fileInfo == nil || !fileInfo.ValidFlag {
+		return
+	}
+
+	var escrowFileHash []byte
+	if fileInfo.StorageType == FileStorageTypeUseFile {
+		escrowFileHash = fileInfo.FileHash
+	}
+	if getRawRenewEscrow(native, fileInfo.FileOwner, escrowFileHash) == nil ||
+		!inRenewWindow(uint64(native.Time), fileInfo.TimeExpired, fileInfo.PdpInterval) {
+		return
+	}
+
+	if err := autoRenew(native, fileInfo.FileOwner, escrowFileHash, 0); err != nil {
+		log.Debugf("[Node Business] tryAutoRenew autoRenew error: %s", err.Error())
+	}
+}
+
 func calcPdpEndPoint(fileTimeStart uint64, pdpInterval uint64, currTime uint64) uint64 {
 	fileSaveTime := currTime - fileTimeStart
 	return currTime + pdpInterval - fileSaveTime%pdpInterval
@@ -148,16 +189,18 @@ func checkPdpData(native *native.NativeService, pdpData *PdpData, fileInfo *File
 	if err != nil || blockHeader == nil {
 		return errors.NewErr("[Node Business] checkPdpData GetHeaderByHeight error!")
 	}
-	blockHash := blockHeader.Hash()
-	hexBlockHash := blockHash.ToArray()
+	challengeSeed := challengeSeed(native, blockHeader, pdpData)
 
-	log.Debugf("ChallengeHeight: %d, blockCount: %d, blockHash: %v\n", pdpData.ChallengeHeight,
-		fileInfo.FileBlockCount, hexBlockHash)
-	return CheckPdpProve(pdpData.NodeAddr, hexBlockHash, fileInfo.FileBlockCount, fileInfo.PdpParam, pdpData.ProveData)
+	log.Debugf("ChallengeHeight: %d, blockCount: %d, challengeSeed: %v\n", pdpData.ChallengeHeight,
+		fileInfo.FileBlockCount, challengeSeed)
+	return checkPdpProve(pdpData.NodeAddr, challengeSeed, fileInfo.FileBlockCount, fileInfo.PdpParam, pdpData.ProveData)
 }
 
+//checkPdpProve verifies the prove of a challenge, only the tests of this package replace it
+var checkPdpProve = CheckPdpProve
+
 //export this function for ontfs
-func CheckPdpProve(nodeAddr common.Address, blockHash []byte, fileBlockCount uint64, pdpParamData []byte,
+func CheckPdpProve(nodeAddr common.Address, challengeSeed []byte, fileBlockCount uint64, pdpParamData []byte,
 	proveData []byte) error {
 	var err error
 
@@ -167,10 +210,10 @@ func CheckPdpProve(nodeAddr common.Address, blockHash []byte, fileBlockCount uin
 	}
 
 	var pdpObj = pdp.NewPdp(filePdpHashSt.Version)
-	blockIndexes := pdpObj.GenChallenge(nodeAddr, blockHash, fileBlockCount)
+	blockIndexes := pdpObj.GenChallenge(nodeAddr, challengeSeed, fileBlockCount)
 
 	for _, blockIndex := range blockIndexes {
-		ret := pdpObj.VerifyProofWithPerBlock(vkData, proveData, blockHash, filePdpHashSt.BlockPdpHashes[blockIndex])
+		ret := pdpObj.VerifyProofWithPerBlock(vkData, proveData, challengeSeed, filePdpHashSt.BlockPdpHashes[blockIndex])
 		if !ret {
 			return errors.NewErr("[Node Business] checkPdpData ProveData Verify failed!")
 		}
@@ -226,7 +269,7 @@ func FsReadFileSettle(native *native.NativeService) ([]byte, error) {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadFileSettle checkSettleSig failed!")
 		}
 
-		readFee := (settleSlice.SliceId - readPledge.ReadPlans[i].HaveReadBlockNum) * DefaultPerBlockSize *
+		readFee := (settleSlice.SliceId - readPledge.ReadPlans[i].HaveReadBlockNum) * fileInfo.BlockSize *
 			globalParam.GasPerKbForRead
 		if readPledge.RestMoney < readFee {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadFileSettle RestMoney < readFee ")
@@ -252,7 +295,10 @@ func FsReadFileSettle(native *native.NativeService) ([]byte, error) {
 		if nodeInfo == nil {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadFileSettle getNodeInfo error!")
 		}
-		nodeInfo.Profit += readFee
+		if err = addNodeProfit(native, nodeInfo, readFee, globalParam); err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadFileSettle addNodeProfit error: " + err.Error())
+		}
+		nodeInfo.Reputation.addReadSettled(uint64(native.Time))
 
 		addNodeInfo(native, nodeInfo)
 		addReadPledge(native, readPledge)
@@ -262,8 +308,84 @@ func FsReadFileSettle(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadFileSettle settleSlice PayTo error!")
 }
 
+func FsReadSessionSettle(native *native.NativeService) ([]byte, error) {
+	var settleSlice ReadSessionSettleSlice
+	source := common.NewZeroCopySource(native.Input)
+	if err := settleSlice.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle Deserialization error!")
+	}
+
+	if !native.ContextRef.CheckWitness(settleSlice.PayTo) {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle Check Slice owner failed!")
+	}
+
+	globalParam, err := getGlobalParam(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle getGlobalParam error!")
+	}
+
+	fileInfo := getFileInfoByHash(native, settleSlice.FileHash)
+	if fileInfo == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle getFileInfoByHash error!")
+	}
+
+	session, err := getReadSession(native, settleSlice.PayFrom, settleSlice.SessionId)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle getReadSession error!")
+	}
+	if settleSlice.PledgeHeight != session.BlockHeight {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle PledgeHeight failed!")
+	}
+
+	file := session.getFile(settleSlice.FileHash)
+	if file == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle file not in session!")
+	}
+
+	for i := 0; i < len(file.ReadPlans); i++ {
+		readPlan := &file.ReadPlans[i]
+		if readPlan.NodeAddr != settleSlice.PayTo {
+			continue
+		}
+		if readPlan.HaveReadBlockNum >= settleSlice.SliceId || readPlan.MaxReadBlockNum < settleSlice.SliceId {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle SliceId error!")
+		}
+
+		ret, err := checkSessionSettleSig(settleSlice)
+		if err != nil || !ret {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle checkSessionSettleSig failed!")
+		}
+
+		readFee := (settleSlice.SliceId - readPlan.HaveReadBlockNum) * fileInfo.BlockSize * globalParam.GasPerKbForRead
+		if session.RestMoney < readFee {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle RestMoney < readFee error!")
+		}
+		readPlan.HaveReadBlockNum = settleSlice.SliceId
+		session.RestMoney -= readFee
+
+		nodeInfo := getNodeInfo(native, settleSlice.PayTo)
+		if nodeInfo == nil {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle getNodeInfo error!")
+		}
+		if err = addNodeProfit(native, nodeInfo, readFee, globalParam); err != nil {
+			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle addNodeProfit error: " + err.Error())
+		}
+		nodeInfo.Reputation.addReadSettled(uint64(native.Time))
+
+		addNodeInfo(native, nodeInfo)
+		addReadSession(native, session)
+		return utils.BYTE_TRUE, nil
+	}
+	return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsReadSessionSettle settleSlice PayTo error!")
+}
+
 func checkSettleSig(settleSlice FileReadSettleSlice) (bool, error) {
-	settleSliceTmp := FileReadSettleSlice{
+	return checkSliceSig(settleSlice.PayFrom, settleSlice.SignData(), settleSlice.Sig, settleSlice.PubKey)
+}
+
+func checkSessionSettleSig(settleSlice ReadSessionSettleSlice) (bool, error) {
+	settleSliceTmp := ReadSessionSettleSlice{
+		SessionId:    settleSlice.SessionId,
 		FileHash:     settleSlice.FileHash,
 		PayFrom:      settleSlice.PayFrom,
 		PayTo:        settleSlice.PayTo,
@@ -273,35 +395,51 @@ func checkSettleSig(settleSlice FileReadSettleSlice) (bool, error) {
 
 	sink := common.NewZeroCopySink(nil)
 	settleSliceTmp.Serialization(sink)
+	return checkSliceSig(settleSlice.PayFrom, sink.Bytes(), settleSlice.Sig, settleSlice.PubKey)
+}
 
-	pubKey, err := keypair.DeserializePublicKey(settleSlice.PubKey)
+//checkSliceSig checks the slice data is signed by payFrom
+func checkSliceSig(payFrom common.Address, data []byte, sig []byte, pubKeyData []byte) (bool, error) {
+	pubKey, err := keypair.DeserializePublicKey(pubKeyData)
 	if err != nil {
-		return false, fmt.Errorf("checkSettleSig DeserializePublicKey error: %s", err.Error())
+		return false, fmt.Errorf("checkSliceSig DeserializePublicKey error: %s", err.Error())
 	}
 	addr := types.AddressFromPubKey(pubKey)
-	if addr != settleSlice.PayFrom {
-		return false, fmt.Errorf("checkSettleSig Pubkey not match walletAddr ")
+	if addr != payFrom {
+		return false, fmt.Errorf("checkSliceSig Pubkey not match walletAddr ")
 	}
-	signValue, err := signature.Deserialize(settleSlice.Sig)
+	signValue, err := signature.Deserialize(sig)
 	if err != nil {
-		return false, fmt.Errorf("checkSettleSig signature Deserialize error: %s", err.Error())
+		return false, fmt.Errorf("checkSliceSig signature Deserialize error: %s", err.Error())
 	}
 
-	result := signature.Verify(pubKey, sink.Bytes(), signValue)
+	result := signature.Verify(pubKey, data, signValue)
 	return result, nil
 }
 
+func calcFilePdpNeedCount(fileInfo *FileInfo) uint64 {
+	return (fileInfo.TimeExpired-fileInfo.TimeStart)/fileInfo.PdpInterval + 1
+}
+
+//calcRestFilePdpCount counts the pdp windows of a copy of the file from currTime until it expires
+func calcRestFilePdpCount(fileInfo *FileInfo, currTime uint64) uint64 {
+	if currTime < fileInfo.TimeStart {
+		currTime = fileInfo.TimeStart
+	}
+	if currTime >= fileInfo.TimeExpired {
+		return 0
+	}
+	return (fileInfo.TimeExpired-currTime)/fileInfo.PdpInterval + 1
+}
+
 func calcPerFileOncePdpProfitByFile(fileInfo *FileInfo) uint64 {
-	filePdpNeedCount := (fileInfo.TimeExpired-fileInfo.TimeStart)/fileInfo.PdpInterval + 1
-	return (fileInfo.PayAmount / fileInfo.CopyNumber) / filePdpNeedCount
+	return (fileInfo.PayAmount / fileInfo.CopyNumber) / calcFilePdpNeedCount(fileInfo)
 }
 
 func calcTotalFilePayAmountByFile(fileInfo *FileInfo, gasPerKbForSaveWithFile uint64) uint64 {
-	filePdpNeedCount := (fileInfo.TimeExpired-fileInfo.TimeStart)/fileInfo.PdpInterval + 1
-	return filePdpNeedCount * fileInfo.CopyNumber * fileInfo.FileBlockCount *
-		DefaultPerBlockSize * gasPerKbForSaveWithFile
+	return calcFilePdpNeedCount(fileInfo) * fileInfo.CopyNumber * fileVolume(fileInfo) * gasPerKbForSaveWithFile
 }
 
 func calcPerFileOncePdpProfitBySpace(fileInfo *FileInfo, space *SpaceInfo, gasPerKbForSaveWithSpace uint64) uint64 {
-	return fileInfo.FileBlockCount * DefaultPerBlockSize * gasPerKbForSaveWithSpace
+	return fileVolume(fileInfo) * gasPerKbForSaveWithSpace
 }
@@ -56,6 +56,10 @@ func FsNodeRegister(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeRegister Volume < MinVolume!")
 	}
 
+	if err = nodeInfo.Metadata.check(); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeRegister Metadata " + err.Error())
+	}
+
 	nodePledge := globalParam.NodePerKbPledge * nodeInfo.Volume
 	err = appCallTransfer(native, utils.OngContractAddress, nodeInfo.NodeAddr, contract, nodePledge)
 	if err != nil {
@@ -63,8 +67,16 @@ func FsNodeRegister(native *native.NativeService) ([]byte, error) {
 	}
 
 	nodeInfo.Profit = 0
+	nodeInfo.LockedProfit = 0
+	nodeInfo.VestedProfit = 0
 	nodeInfo.Pledge = nodePledge
 	nodeInfo.RestVol = nodeInfo.Volume
+	nodeInfo.Reputation = NodeReputation{RegisterTime: uint64(native.Time), DecayTime: uint64(native.Time)}
+	nodeInfo.Reputation.refresh(uint64(native.Time))
+	nodeInfo.Metadata.declare(nil, uint64(native.Time))
+	if len(nodeInfo.Metadata.Endpoints) != 0 {
+		nodeInfo.NodeNetAddr = nodeInfo.Metadata.Endpoints[0].NetAddr()
+	}
 
 	addNodeInfo(native, &nodeInfo)
 	return utils.BYTE_TRUE, nil
@@ -77,11 +89,17 @@ func FsNodeQuery(native *native.NativeService) ([]byte, error) {
 		return EncRet(false, []byte("[Node Govern] FsNodeQuery DecodeAddress error!")), nil
 	}
 
-	nodeRawInfo := getNodeRawInfo(native, nodeAddr)
-	if nodeRawInfo == nil {
-		return EncRet(false, []byte("[Node Govern] FsNodeQuery getNodeRawInfo error!")), nil
+	nodeInfo := getNodeInfo(native, nodeAddr)
+	if nodeInfo == nil {
+		return EncRet(false, []byte("[Node Govern] FsNodeQuery getNodeInfo error!")), nil
 	}
-	return EncRet(true, nodeRawInfo), nil
+	nodeInfo.Reputation.refresh(uint64(native.Time))
+	nodeInfo.Metadata.refresh(uint64(native.Time))
+	updateProfitVesting(native, nodeInfo)
+
+	sink := common.NewZeroCopySink(nil)
+	nodeInfo.Serialization(sink)
+	return EncRet(true, sink.Bytes()), nil
 }
 
 func FsNodeUpdate(native *native.NativeService) ([]byte, error) {
@@ -110,6 +128,10 @@ func FsNodeUpdate(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeUpdate Volume < MinVolume!")
 	}
 
+	if err = newNodeInfo.Metadata.check(); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeUpdate Metadata " + err.Error())
+	}
+
 	oldNodeInfo := getNodeInfo(native, newNodeInfo.NodeAddr)
 	if oldNodeInfo == nil {
 		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeUpdate getNodeInfo error!")
@@ -140,6 +162,13 @@ func FsNodeUpdate(native *native.NativeService) ([]byte, error) {
 	newNodeInfo.Pledge = newNodePledge
 	newNodeInfo.Profit = oldNodeInfo.Profit
 	newNodeInfo.RestVol = oldNodeInfo.RestVol + newNodeInfo.Volume - oldNodeInfo.Volume
+	newNodeInfo.Reputation = oldNodeInfo.Reputation
+	updateProfitVesting(native, &newNodeInfo)
+	newNodeInfo.Reputation.refresh(uint64(native.Time))
+	newNodeInfo.Metadata.declare(&oldNodeInfo.Metadata, uint64(native.Time))
+	if len(newNodeInfo.Metadata.Endpoints) != 0 {
+		newNodeInfo.NodeNetAddr = newNodeInfo.Metadata.Endpoints[0].NetAddr()
+	}
 
 	addNodeInfo(native, &newNodeInfo)
 	return utils.BYTE_TRUE, nil
@@ -167,6 +196,11 @@ func FsNodeCancel(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeCancel ServiceTime not due!")
 	}
 
+	updateProfitVesting(native, nodeInfo)
+	if nodeInfo.LockedProfit > 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeCancel profit still locked!")
+	}
+
 	if nodeInfo.Pledge+nodeInfo.Profit > 0 {
 		err = appCallTransfer(native, utils.OngContractAddress, contract, nodeInfo.NodeAddr, nodeInfo.Pledge+nodeInfo.Profit)
 		if err != nil {
@@ -175,6 +209,7 @@ func FsNodeCancel(native *native.NativeService) ([]byte, error) {
 	}
 
 	delNodeInfo(native, nodeAddr)
+	setProfitVesting(native, &ProfitVesting{NodeAddr: nodeAddr})
 	return utils.BYTE_TRUE, nil
 }
 
@@ -196,16 +231,90 @@ func FsNodeWithDrawProfit(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeWithDrawProfit getFsNodeInfo error!")
 	}
 
-	if nodeInfo.Profit > 0 {
-		err = appCallTransfer(native, utils.OngContractAddress, contract, nodeInfo.NodeAddr, nodeInfo.Profit)
+	vesting := getProfitVesting(native, nodeAddr)
+	vesting.release(uint64(native.Time))
+	setProfitVesting(native, vesting)
+	updateProfitVesting(native, nodeInfo)
+
+	if nodeInfo.VestedProfit > 0 {
+		err = appCallTransfer(native, utils.OngContractAddress, contract, nodeInfo.NodeAddr, nodeInfo.VestedProfit)
 		if err != nil {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeWithDrawProfit appCallTransfer,  transfer error!")
 		}
-		nodeInfo.Profit = 0
+		nodeInfo.Profit -= nodeInfo.VestedProfit
+		nodeInfo.VestedProfit = 0
 	} else {
-		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeWithDrawProfit profit = 0 error! ")
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeWithDrawProfit vested profit = 0 error! ")
 	}
 
 	addNodeInfo(native, nodeInfo)
 	return utils.BYTE_TRUE, nil
 }
+
+// FsNodeAttest records a liveness attestation signed by a client, anyone may submit it
+func FsNodeAttest(native *native.NativeService) ([]byte, error) {
+	var attestation NodeAttestation
+	source := common.NewZeroCopySource(native.Input)
+	if err := attestation.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeAttest Deserialization error!")
+	}
+
+	ret, err := checkAttestationSig(&attestation)
+	if err != nil || !ret {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeAttest checkAttestationSig failed!")
+	}
+	if attestation.Client == attestation.NodeAddr {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeAttest node attests itself!")
+	}
+	if attestation.Time > uint64(native.Time) || attestation.Time+DefaultAttestationExpire < uint64(native.Time) {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeAttest attestation Time error!")
+	}
+	if !attestationRelated(native, &attestation) {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeAttest client doesn't use the node!")
+	}
+
+	nodeInfo := getNodeInfo(native, attestation.NodeAddr)
+	if nodeInfo == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeAttest getNodeInfo error!")
+	}
+	if err = nodeInfo.Metadata.attest(&attestation); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Node Govern] FsNodeAttest " + err.Error())
+	}
+	nodeInfo.Metadata.refresh(uint64(native.Time))
+
+	addNodeInfo(native, nodeInfo)
+	return utils.BYTE_TRUE, nil
+}
+
+// attestationRelated tells whether the client of the attestation uses the node: it owns the file the
+// node holds a pdp record of, or reads the file from the node by an unexpired read pledge or read session
+func attestationRelated(native *native.NativeService, attestation *NodeAttestation) bool {
+	if getPdpRecord(native, attestation.FileHash, attestation.Client, attestation.NodeAddr) != nil {
+		return true
+	}
+
+	var readPlans []ReadPlan
+	if len(attestation.SessionId) == 0 {
+		readPledge, err := getReadPledge(native, attestation.Client, attestation.FileHash)
+		if err != nil || uint64(native.Height) >= readPledge.ExpireHeight {
+			return false
+		}
+		readPlans = readPledge.ReadPlans
+	} else {
+		session, err := getReadSession(native, attestation.Client, attestation.SessionId)
+		if err != nil || uint64(native.Height) >= session.ExpireHeight {
+			return false
+		}
+		sessionFile := session.getFile(attestation.FileHash)
+		if sessionFile == nil {
+			return false
+		}
+		readPlans = sessionFile.ReadPlans
+	}
+	for _, readPlan := range readPlans {
+		if readPlan.NodeAddr == attestation.NodeAddr {
+			return true
+		}
+	}
+	return false
+}
@@ -34,6 +34,10 @@ type FsNodeInfo struct {
 	MinPdpInterval uint64
 	NodeAddr       common.Address
 	NodeNetAddr    []byte
+	Reputation     NodeReputation //kept by the contract, ignored in FsNodeRegister and FsNodeUpdate
+	LockedProfit   uint64         //part of Profit not vested yet, kept by the contract
+	VestedProfit   uint64         //part of Profit which can be withdrawn, kept by the contract
+	Metadata       NodeMetadata   //NodeNetAddr is the first endpoint when it declares any
 }
 
 type FsNodeInfoList struct {
@@ -41,6 +45,7 @@ type FsNodeInfoList struct {
 }
 
 func (this *FsNodeInfo) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
 	utils.EncodeVarUint(sink, this.Pledge)
 	utils.EncodeVarUint(sink, this.Profit)
 	utils.EncodeVarUint(sink, this.Volume)
@@ -49,10 +54,17 @@ func (this *FsNodeInfo) Serialization(sink *common.ZeroCopySink) {
 	utils.EncodeVarUint(sink, this.MinPdpInterval)
 	utils.EncodeAddress(sink, this.NodeAddr)
 	sink.WriteVarBytes(this.NodeNetAddr)
+	this.Reputation.Serialization(sink)
+	utils.EncodeVarUint(sink, this.LockedProfit)
+	utils.EncodeVarUint(sink, this.VestedProfit)
+	this.Metadata.Serialization(sink)
 }
 
 func (this *FsNodeInfo) Deserialization(source *common.ZeroCopySource) error {
-	var err error
+	version, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
 	this.Pledge, err = utils.DecodeVarUint(source)
 	if err != nil {
 		return err
@@ -85,6 +97,32 @@ func (this *FsNodeInfo) Deserialization(source *common.ZeroCopySource) error {
 	if err != nil {
 		return err
 	}
+	//node info stored before reputation was kept
+	if !hasRecordField(source, version, RecordVersion3) {
+		this.VestedProfit = this.Profit
+		return nil
+	}
+	if err = this.Reputation.Deserialization(source); err != nil {
+		return err
+	}
+	//node info stored before profit vesting
+	if !hasRecordField(source, version, RecordVersion4) {
+		this.VestedProfit = this.Profit
+		return nil
+	}
+	if this.LockedProfit, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.VestedProfit, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	//node info stored before the node metadata
+	if !hasRecordField(source, version, RecordVersion5) {
+		return nil
+	}
+	if err = this.Metadata.Deserialization(source); err != nil {
+		return err
+	}
 	return nil
 }
 
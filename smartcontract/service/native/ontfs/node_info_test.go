@@ -36,6 +36,17 @@ func TestFsNodeInfo_Serialization(t *testing.T) {
 		NodeAddr: common.Address{0x01, 0x02, 0x03, 0x04, 0x05, 0x01, 0x02, 0x03, 0x04, 0x05,
 			0x01, 0x02, 0x03, 0x04, 0x05, 0x01, 0x02, 0x03, 0x04, 0x05},
 		NodeNetAddr: []byte("111.111.111.111：111"),
+		Reputation: NodeReputation{ProveOnTime: 5, ProveLate: 1, MissedWindows: 2, ReadsSettled: 3,
+			RegisterTime: 100, DecayTime: 200, Score: 500},
+		LockedProfit: 5,
+		VestedProfit: 15,
+		Metadata: NodeMetadata{
+			Endpoints: []NodeEndpoint{{Protocol: []byte("tcp"), Addr: []byte("111.111.111.111:111"),
+				LastSeen: 100, LastFailed: 200, Stale: true}},
+			Region:    []byte("SG"),
+			Bandwidth: 1024,
+			Version:   []byte("v1.0.0"),
+		},
 	}
 	sink := common.NewZeroCopySink(nil)
 	nodeInfo.Serialization(sink)
@@ -50,3 +61,62 @@ func TestFsNodeInfo_Serialization(t *testing.T) {
 
 	assert.Equal(t, nodeInfo, nodeInfo2)
 }
+
+func TestNodeReputation_Decay(t *testing.T) {
+	reputation := NodeReputation{RegisterTime: 1000, DecayTime: 1000}
+	reputation.refresh(1000)
+	newScore := reputation.Score
+
+	for i := 0; i < 8; i++ {
+		reputation.addProve(1000, false, 1)
+	}
+	assert.True(t, reputation.Score < newScore)
+	faultScore := reputation.Score
+
+	reputation.refresh(1000 + 2*DefaultReputationHalfLife)
+	assert.Equal(t, uint64(2), reputation.ProveLate)
+	assert.Equal(t, uint64(2), reputation.MissedWindows)
+	assert.Equal(t, uint64(1000+2*DefaultReputationHalfLife), reputation.DecayTime)
+	assert.True(t, reputation.Score > faultScore)
+}
+
+func TestCountMissedWindows(t *testing.T) {
+	assert.Equal(t, uint64(0), countMissedWindows(100, 200, 100))
+	assert.Equal(t, uint64(2), countMissedWindows(100, 400, 100))
+	assert.Equal(t, uint64(0), countMissedWindows(100, 100, 100))
+
+	fileInfo := &FileInfo{TimeStart: 0, TimeExpired: 1000, PdpInterval: 100}
+	pdpRecord := &PdpRecord{LastPdpTime: 300}
+	assert.Equal(t, uint64(2), countRecordMissedWindows(fileInfo, pdpRecord, 550))
+	assert.Equal(t, uint64(6), countRecordMissedWindows(fileInfo, pdpRecord, 5000))
+
+	reputation := NodeReputation{RegisterTime: 1000, DecayTime: 1000}
+	reputation.refresh(1000)
+	newScore := reputation.Score
+	reputation.addMissedWindows(1000, 2)
+	assert.Equal(t, uint64(2), reputation.MissedWindows)
+	assert.True(t, reputation.Score < newScore)
+}
+
+func TestProfitVesting(t *testing.T) {
+	vesting := ProfitVesting{NodeAddr: common.Address{0x01}}
+	vesting.lock(100, 10)
+	vesting.lock(50, 20)
+	vesting.lock(30, DefaultProfitLockBucket+10)
+	assert.Equal(t, 2, len(vesting.Locks))
+	assert.Equal(t, uint64(150), vesting.Locks[0].Amount)
+	assert.Equal(t, uint64(180), vesting.lockedAmount(0))
+	assert.Equal(t, uint64(30), vesting.lockedAmount(DefaultProfitLockBucket))
+
+	sink := common.NewZeroCopySink(nil)
+	vesting.Serialization(sink)
+	vesting2 := ProfitVesting{}
+	if err := vesting2.Deserialization(common.NewZeroCopySource(sink.Bytes())); err != nil {
+		t.Fatal("vesting2 deserialize fail!", err.Error())
+	}
+	assert.Equal(t, vesting, vesting2)
+
+	vesting.release(DefaultProfitLockBucket)
+	assert.Equal(t, 1, len(vesting.Locks))
+	assert.Equal(t, uint64(30), vesting.Locks[0].Amount)
+}
@@ -0,0 +1,374 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// NodeEndpoint is an address a node serves on. LastSeen, LastFailed and Stale are kept by the contract
+// from the liveness attestations of clients, and ignored in FsNodeRegister and FsNodeUpdate.
+type NodeEndpoint struct {
+	Protocol   []byte //protocol tag, such as tcp, udp or http
+	Addr       []byte //host:port
+	LastSeen   uint64 //second. last time a client attested it reachable, or the node declared it
+	LastFailed uint64 //second. last time a client attested it unreachable
+	Stale      bool   //no reachable attestation within DefaultEndpointStaleTime, or failed since
+}
+
+// NodeMetadata is what a node declares about its service, beside its volume and pledge
+type NodeMetadata struct {
+	Endpoints []NodeEndpoint
+	Region    []byte //ISO 3166-1 alpha-2 code, empty when not declared
+	Bandwidth uint64 //kb per second
+	Version   []byte //software version
+}
+
+// NodeFilter selects nodes of FsGetNodeList, empty fields match every node
+type NodeFilter struct {
+	Protocol     []byte //the node serves on this protocol
+	Region       []byte
+	MinBandwidth uint64
+	LiveOnly     bool //with Protocol, the endpoint must not be stale, else any endpoint must not be
+}
+
+// NodeAttestation is signed by a client which tried an endpoint of a node at Time. The client owns
+// FileHash stored on the node, or reads it from the node by its read pledge or by the read session
+// SessionId.
+type NodeAttestation struct {
+	NodeAddr  common.Address
+	Protocol  []byte
+	Addr      []byte
+	Client    common.Address
+	FileHash  []byte
+	SessionId []byte
+	Reachable bool
+	Time      uint64
+	Sig       []byte
+	PubKey    []byte
+}
+
+func (this *NodeEndpoint) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Protocol)
+	sink.WriteVarBytes(this.Addr)
+	utils.EncodeVarUint(sink, this.LastSeen)
+	utils.EncodeVarUint(sink, this.LastFailed)
+	sink.WriteBool(this.Stale)
+}
+
+func (this *NodeEndpoint) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Protocol, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Addr, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.LastSeen, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.LastFailed, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Stale, err = DecodeBool(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *NodeMetadata) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, uint64(len(this.Endpoints)))
+	for _, endpoint := range this.Endpoints {
+		sinkTmp := common.NewZeroCopySink(nil)
+		endpoint.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+	sink.WriteVarBytes(this.Region)
+	utils.EncodeVarUint(sink, this.Bandwidth)
+	sink.WriteVarBytes(this.Version)
+}
+
+func (this *NodeMetadata) Deserialization(source *common.ZeroCopySource) error {
+	endpointCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	if endpointCount > DefaultMaxNodeEndpoints {
+		return fmt.Errorf("endpoint count %d > %d", endpointCount, DefaultMaxNodeEndpoints)
+	}
+	for i := uint64(0); i < endpointCount; i++ {
+		endpointTmp, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		var endpoint NodeEndpoint
+		if err = endpoint.Deserialization(common.NewZeroCopySource(endpointTmp)); err != nil {
+			return err
+		}
+		this.Endpoints = append(this.Endpoints, endpoint)
+	}
+	if this.Region, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Bandwidth, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Version, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *NodeFilter) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Protocol)
+	sink.WriteVarBytes(this.Region)
+	utils.EncodeVarUint(sink, this.MinBandwidth)
+	sink.WriteBool(this.LiveOnly)
+}
+
+func (this *NodeFilter) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Protocol, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Region, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.MinBandwidth, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.LiveOnly, err = DecodeBool(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *NodeAttestation) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.NodeAddr)
+	sink.WriteVarBytes(this.Protocol)
+	sink.WriteVarBytes(this.Addr)
+	utils.EncodeAddress(sink, this.Client)
+	sink.WriteVarBytes(this.FileHash)
+	sink.WriteVarBytes(this.SessionId)
+	sink.WriteBool(this.Reachable)
+	utils.EncodeVarUint(sink, this.Time)
+	sink.WriteVarBytes(this.Sig)
+	sink.WriteVarBytes(this.PubKey)
+}
+
+func (this *NodeAttestation) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.NodeAddr, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Protocol, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Addr, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Client, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.FileHash, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.SessionId, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Reachable, err = DecodeBool(source); err != nil {
+		return err
+	}
+	if this.Time, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Sig, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.PubKey, err = DecodeVarBytes(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NetAddr returns the endpoint as protocol://host:port
+func (this *NodeEndpoint) NetAddr() []byte {
+	return []byte(string(this.Protocol) + "://" + string(this.Addr))
+}
+
+func (this *NodeEndpoint) check() error {
+	if len(this.Protocol) == 0 || len(this.Protocol) > DefaultMaxProtocolLen {
+		return fmt.Errorf("protocol length should be 1 to %d", DefaultMaxProtocolLen)
+	}
+	for _, c := range this.Protocol {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.') {
+			return fmt.Errorf("protocol %s has invalid character", this.Protocol)
+		}
+	}
+	if len(this.Addr) > DefaultMaxEndpointAddrLen {
+		return fmt.Errorf("addr length > %d", DefaultMaxEndpointAddrLen)
+	}
+	host, port, err := net.SplitHostPort(string(this.Addr))
+	if err != nil {
+		return fmt.Errorf("addr %s error: %s", this.Addr, err.Error())
+	}
+	if len(host) == 0 {
+		return fmt.Errorf("addr %s has no host", this.Addr)
+	}
+	if portNum, err := strconv.ParseUint(port, 10, 16); err != nil || portNum == 0 {
+		return fmt.Errorf("addr %s has invalid port", this.Addr)
+	}
+	return nil
+}
+
+// refresh flags the endpoint stale when no client saw it for DefaultEndpointStaleTime, or one
+// failed to reach it since it was last seen
+func (this *NodeEndpoint) refresh(currTime uint64) {
+	this.Stale = this.LastFailed > this.LastSeen || currTime > this.LastSeen+DefaultEndpointStaleTime
+}
+
+// check validates the metadata a node declares
+func (this *NodeMetadata) check() error {
+	if len(this.Endpoints) > DefaultMaxNodeEndpoints {
+		return fmt.Errorf("endpoint count > %d", DefaultMaxNodeEndpoints)
+	}
+	for i := range this.Endpoints {
+		if err := this.Endpoints[i].check(); err != nil {
+			return err
+		}
+		for j := 0; j < i; j++ {
+			if this.Endpoints[j].sameAs(this.Endpoints[i].Protocol, this.Endpoints[i].Addr) {
+				return fmt.Errorf("endpoint %s duplicated", this.Endpoints[i].NetAddr())
+			}
+		}
+	}
+	if len(this.Region) != 0 {
+		if len(this.Region) != 2 || !isUpperLetter(this.Region[0]) || !isUpperLetter(this.Region[1]) {
+			return fmt.Errorf("region %s is not an ISO 3166-1 alpha-2 code", this.Region)
+		}
+	}
+	if len(this.Version) > DefaultMaxNodeVersionLen {
+		return fmt.Errorf("version length > %d", DefaultMaxNodeVersionLen)
+	}
+	for _, c := range this.Version {
+		if c < 0x20 || c > 0x7E {
+			return fmt.Errorf("version has invalid character")
+		}
+	}
+	return nil
+}
+
+// declare resets the attested status of the endpoints, an endpoint the node declared before keeps its status
+func (this *NodeMetadata) declare(old *NodeMetadata, currTime uint64) {
+	for i := range this.Endpoints {
+		endpoint := &this.Endpoints[i]
+		endpoint.LastSeen, endpoint.LastFailed = currTime, 0
+		if old != nil {
+			if oldEndpoint := old.endpoint(endpoint.Protocol, endpoint.Addr); oldEndpoint != nil {
+				endpoint.LastSeen, endpoint.LastFailed = oldEndpoint.LastSeen, oldEndpoint.LastFailed
+			}
+		}
+		endpoint.refresh(currTime)
+	}
+}
+
+func (this *NodeMetadata) refresh(currTime uint64) {
+	for i := range this.Endpoints {
+		this.Endpoints[i].refresh(currTime)
+	}
+}
+
+func (this *NodeMetadata) endpoint(protocol []byte, addr []byte) *NodeEndpoint {
+	for i := range this.Endpoints {
+		if this.Endpoints[i].sameAs(protocol, addr) {
+			return &this.Endpoints[i]
+		}
+	}
+	return nil
+}
+
+func (this *NodeEndpoint) sameAs(protocol []byte, addr []byte) bool {
+	return string(this.Protocol) == string(protocol) && string(this.Addr) == string(addr)
+}
+
+// match checks the node against the filter, the endpoints must be refreshed
+func (this *NodeFilter) match(nodeInfo *FsNodeInfo) bool {
+	metadata := &nodeInfo.Metadata
+	if len(this.Region) != 0 && string(this.Region) != string(metadata.Region) {
+		return false
+	}
+	if metadata.Bandwidth < this.MinBandwidth {
+		return false
+	}
+	if len(this.Protocol) == 0 && !this.LiveOnly {
+		return true
+	}
+	for _, endpoint := range metadata.Endpoints {
+		if len(this.Protocol) != 0 && string(endpoint.Protocol) != string(this.Protocol) {
+			continue
+		}
+		if this.LiveOnly && endpoint.Stale {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// attest records the attestation in the endpoint it is about, older attestations change nothing
+func (this *NodeMetadata) attest(attestation *NodeAttestation) error {
+	endpoint := this.endpoint(attestation.Protocol, attestation.Addr)
+	if endpoint == nil {
+		return fmt.Errorf("endpoint %s://%s not found", attestation.Protocol, attestation.Addr)
+	}
+	if attestation.Reachable {
+		if attestation.Time > endpoint.LastSeen {
+			endpoint.LastSeen = attestation.Time
+		}
+	} else if attestation.Time > endpoint.LastFailed {
+		endpoint.LastFailed = attestation.Time
+	}
+	return nil
+}
+
+func checkAttestationSig(attestation *NodeAttestation) (bool, error) {
+	attestationTmp := NodeAttestation{
+		NodeAddr:  attestation.NodeAddr,
+		Protocol:  attestation.Protocol,
+		Addr:      attestation.Addr,
+		Client:    attestation.Client,
+		FileHash:  attestation.FileHash,
+		SessionId: attestation.SessionId,
+		Reachable: attestation.Reachable,
+		Time:      attestation.Time,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	attestationTmp.Serialization(sink)
+	return checkSliceSig(attestation.Client, sink.Bytes(), attestation.Sig, attestation.PubKey)
+}
+
+func isUpperLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeMetadata_Check(t *testing.T) {
+	metadata := NodeMetadata{
+		Endpoints: []NodeEndpoint{
+			{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:20338")},
+			{Protocol: []byte("https"), Addr: []byte("[::1]:443")},
+		},
+		Region:    []byte("SG"),
+		Bandwidth: 1024,
+		Version:   []byte("v1.0.0"),
+	}
+	assert.Nil(t, metadata.check())
+	assert.Nil(t, (&NodeMetadata{}).check())
+
+	for _, endpoint := range []NodeEndpoint{
+		{Protocol: []byte("TCP"), Addr: []byte("127.0.0.1:20338")},
+		{Protocol: []byte(""), Addr: []byte("127.0.0.1:20338")},
+		{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1")},
+		{Protocol: []byte("tcp"), Addr: []byte(":20338")},
+		{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:0")},
+		{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:65536")},
+		{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:20338")},
+	} {
+		bad := metadata
+		bad.Endpoints = append([]NodeEndpoint{endpoint}, metadata.Endpoints...)
+		assert.Error(t, bad.check(), string(endpoint.NetAddr()))
+	}
+
+	bad := metadata
+	bad.Region = []byte("sg")
+	assert.Error(t, bad.check())
+	bad = metadata
+	bad.Version = []byte("v1\n")
+	assert.Error(t, bad.check())
+}
+
+func TestNodeMetadata_Attest(t *testing.T) {
+	old := NodeMetadata{Endpoints: []NodeEndpoint{{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:20338")}}}
+	old.declare(nil, 100)
+	assert.Equal(t, uint64(100), old.Endpoints[0].LastSeen)
+	assert.False(t, old.Endpoints[0].Stale)
+
+	attestation := NodeAttestation{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:20338"), Time: 150}
+	assert.Nil(t, old.attest(&attestation))
+	assert.Equal(t, uint64(150), old.Endpoints[0].LastFailed)
+	old.refresh(150)
+	assert.True(t, old.Endpoints[0].Stale)
+
+	//a reachable attestation older than the failure keeps the flag, a newer one clears it
+	attestation.Reachable = true
+	attestation.Time = 140
+	assert.Nil(t, old.attest(&attestation))
+	old.refresh(150)
+	assert.True(t, old.Endpoints[0].Stale)
+	attestation.Time = 160
+	assert.Nil(t, old.attest(&attestation))
+	old.refresh(160)
+	assert.False(t, old.Endpoints[0].Stale)
+	old.refresh(160 + DefaultEndpointStaleTime + 1)
+	assert.True(t, old.Endpoints[0].Stale)
+
+	attestation.Addr = []byte("127.0.0.1:20339")
+	assert.Error(t, old.attest(&attestation))
+
+	//a declared endpoint keeps its status on update, a new one starts seen
+	metadata := NodeMetadata{Endpoints: []NodeEndpoint{
+		{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:20338")},
+		{Protocol: []byte("udp"), Addr: []byte("127.0.0.1:20338")},
+	}}
+	metadata.declare(&old, 200)
+	assert.Equal(t, uint64(160), metadata.Endpoints[0].LastSeen)
+	assert.Equal(t, uint64(150), metadata.Endpoints[0].LastFailed)
+	assert.Equal(t, uint64(200), metadata.Endpoints[1].LastSeen)
+}
+
+func TestNodeFilter_Match(t *testing.T) {
+	nodeInfo := FsNodeInfo{Metadata: NodeMetadata{
+		Endpoints: []NodeEndpoint{
+			{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:20338"), Stale: true},
+			{Protocol: []byte("udp"), Addr: []byte("127.0.0.1:20338")},
+		},
+		Region:    []byte("SG"),
+		Bandwidth: 1024,
+	}}
+	assert.True(t, (&NodeFilter{}).match(&nodeInfo))
+	assert.True(t, (&NodeFilter{Region: []byte("SG"), MinBandwidth: 1024}).match(&nodeInfo))
+	assert.False(t, (&NodeFilter{Region: []byte("US")}).match(&nodeInfo))
+	assert.False(t, (&NodeFilter{MinBandwidth: 2048}).match(&nodeInfo))
+	assert.True(t, (&NodeFilter{Protocol: []byte("tcp")}).match(&nodeInfo))
+	assert.False(t, (&NodeFilter{Protocol: []byte("tcp"), LiveOnly: true}).match(&nodeInfo))
+	assert.True(t, (&NodeFilter{LiveOnly: true}).match(&nodeInfo))
+	assert.False(t, (&NodeFilter{LiveOnly: true}).match(&FsNodeInfo{}))
+
+	filter := NodeFilter{Protocol: []byte("tcp"), Region: []byte("SG"), MinBandwidth: 1024, LiveOnly: true}
+	sink := common.NewZeroCopySink(nil)
+	filter.Serialization(sink)
+	var filter2 NodeFilter
+	assert.Nil(t, filter2.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, filter, filter2)
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// ProfitLock is a part of the node profit which can't be withdrawn before UnlockTime.
+type ProfitLock struct {
+	Amount     uint64
+	UnlockTime uint64
+}
+
+// ProfitVesting holds the locks of a node profit, ordered by UnlockTime.
+// Locked profit stays in FsNodeInfo.Profit, so it backs the node like its pledge until it vests.
+type ProfitVesting struct {
+	NodeAddr common.Address
+	Locks    []ProfitLock
+}
+
+func (this *ProfitVesting) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.NodeAddr)
+	utils.EncodeVarUint(sink, uint64(len(this.Locks)))
+	for _, lock := range this.Locks {
+		utils.EncodeVarUint(sink, lock.Amount)
+		utils.EncodeVarUint(sink, lock.UnlockTime)
+	}
+}
+
+func (this *ProfitVesting) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.NodeAddr, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	lockCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < lockCount; i++ {
+		var lock ProfitLock
+		if lock.Amount, err = utils.DecodeVarUint(source); err != nil {
+			return err
+		}
+		if lock.UnlockTime, err = utils.DecodeVarUint(source); err != nil {
+			return err
+		}
+		this.Locks = append(this.Locks, lock)
+	}
+	return nil
+}
+
+// lockedAmount returns the profit still locked at currTime
+func (this *ProfitVesting) lockedAmount(currTime uint64) uint64 {
+	var locked uint64
+	for _, lock := range this.Locks {
+		if lock.UnlockTime > currTime {
+			locked += lock.Amount
+		}
+	}
+	return locked
+}
+
+// release drops the locks vested at currTime
+func (this *ProfitVesting) release(currTime uint64) {
+	var locks []ProfitLock
+	for _, lock := range this.Locks {
+		if lock.UnlockTime > currTime {
+			locks = append(locks, lock)
+		}
+	}
+	this.Locks = locks
+}
+
+// slash takes up to amount from the profit still locked at currTime, the locks vesting last first,
+// and returns the amount taken
+func (this *ProfitVesting) slash(amount uint64, currTime uint64) uint64 {
+	var slashed uint64
+	for i := len(this.Locks) - 1; i >= 0 && slashed < amount; i-- {
+		if this.Locks[i].UnlockTime <= currTime {
+			break
+		}
+		take := amount - slashed
+		if take > this.Locks[i].Amount {
+			take = this.Locks[i].Amount
+		}
+		this.Locks[i].Amount -= take
+		slashed += take
+	}
+
+	var locks []ProfitLock
+	for _, lock := range this.Locks {
+		if lock.Amount > 0 {
+			locks = append(locks, lock)
+		}
+	}
+	this.Locks = locks
+	return slashed
+}
+
+func (this *ProfitVesting) lock(amount uint64, unlockTime uint64) {
+	//round up to the bucket, so the count of locks is bounded by ProfitLockPeriod / DefaultProfitLockBucket
+	unlockTime = (unlockTime/DefaultProfitLockBucket + 1) * DefaultProfitLockBucket
+	count := len(this.Locks)
+	if count != 0 && this.Locks[count-1].UnlockTime == unlockTime {
+		this.Locks[count-1].Amount += amount
+		return
+	}
+	this.Locks = append(this.Locks, ProfitLock{Amount: amount, UnlockTime: unlockTime})
+}
+
+func setProfitVesting(native *native.NativeService, vesting *ProfitVesting) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	vestingKey := GenFsProfitVestingKey(contract, vesting.NodeAddr)
+	if len(vesting.Locks) == 0 {
+		native.CacheDB.Delete(vestingKey)
+		return
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	vesting.Serialization(sink)
+	utils.PutBytes(native, vestingKey, sink.Bytes())
+}
+
+func getProfitVesting(native *native.NativeService, nodeAddr common.Address) *ProfitVesting {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	vestingKey := GenFsProfitVestingKey(contract, nodeAddr)
+
+	vesting := &ProfitVesting{NodeAddr: nodeAddr}
+	item, err := utils.GetStorageItem(native, vestingKey)
+	if err != nil || item == nil || item.Value == nil {
+		return vesting
+	}
+	source := common.NewZeroCopySource(item.Value)
+	if err := vesting.Deserialization(source); err != nil {
+		return &ProfitVesting{NodeAddr: nodeAddr}
+	}
+	return vesting
+}
+
+// addNodeProfit takes the protocol fee from a payment, credits the node with the rest and
+// locks ProfitLockRate percent of its profit for ProfitLockPeriod
+func addNodeProfit(native *native.NativeService, nodeInfo *FsNodeInfo, payment uint64, globalParam *FsGlobalParam) error {
+	profit, err := takeProtocolFee(native, payment)
+	if err != nil {
+		return err
+	}
+	nodeInfo.Profit += profit
+
+	vesting := getProfitVesting(native, nodeInfo.NodeAddr)
+	vesting.release(uint64(native.Time))
+	if lockAmount := profit * globalParam.ProfitLockRate / 100; lockAmount > 0 {
+		vesting.lock(lockAmount, uint64(native.Time)+globalParam.ProfitLockPeriod)
+	}
+	setProfitVesting(native, vesting)
+	updateProfitVesting(native, nodeInfo)
+	return nil
+}
+
+// updateProfitVesting refreshes the vested and locked profit shown in the node info
+func updateProfitVesting(native *native.NativeService, nodeInfo *FsNodeInfo) {
+	locked := getProfitVesting(native, nodeInfo.NodeAddr).lockedAmount(uint64(native.Time))
+	if locked > nodeInfo.Profit {
+		locked = nodeInfo.Profit
+	}
+	nodeInfo.LockedProfit = locked
+	nodeInfo.VestedProfit = nodeInfo.Profit - locked
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// NodeReputation is the service history of a node kept by the contract. The counters are halved every
+// DefaultReputationHalfLife, so old faults fade.
+type NodeReputation struct {
+	ProveOnTime   uint64
+	ProveLate     uint64
+	MissedWindows uint64 //pdp intervals passed without a prove
+	ReadsSettled  uint64
+	RegisterTime  uint64
+	DecayTime     uint64 //last time the counters were decayed
+	Score         uint64 //derived from the counters and the uptime, refreshed with them
+}
+
+func (this *NodeReputation) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.ProveOnTime)
+	utils.EncodeVarUint(sink, this.ProveLate)
+	utils.EncodeVarUint(sink, this.MissedWindows)
+	utils.EncodeVarUint(sink, this.ReadsSettled)
+	utils.EncodeVarUint(sink, this.RegisterTime)
+	utils.EncodeVarUint(sink, this.DecayTime)
+	utils.EncodeVarUint(sink, this.Score)
+}
+
+func (this *NodeReputation) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.ProveOnTime, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.ProveLate, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.MissedWindows, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.ReadsSettled, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.RegisterTime, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.DecayTime, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Score, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+// refresh decays the counters up to currTime and recomputes the score
+func (this *NodeReputation) refresh(currTime uint64) {
+	if this.DecayTime == 0 {
+		//node registered before reputation was kept
+		this.RegisterTime = currTime
+		this.DecayTime = currTime
+	}
+	if currTime > this.DecayTime {
+		halvings := (currTime - this.DecayTime) / DefaultReputationHalfLife
+		if halvings > 0 {
+			if halvings > 63 {
+				halvings = 63
+			}
+			this.ProveOnTime >>= halvings
+			this.ProveLate >>= halvings
+			this.MissedWindows >>= halvings
+			this.ReadsSettled >>= halvings
+			this.DecayTime += halvings * DefaultReputationHalfLife
+		}
+	}
+	this.Score = calcReputationScore(this, currTime)
+}
+
+func (this *NodeReputation) addProve(currTime uint64, onTime bool, missedWindows uint64) {
+	this.refresh(currTime)
+	if onTime {
+		this.ProveOnTime++
+	} else {
+		this.ProveLate++
+	}
+	this.MissedWindows += missedWindows
+	this.Score = calcReputationScore(this, currTime)
+}
+
+func (this *NodeReputation) addReadSettled(currTime uint64) {
+	this.refresh(currTime)
+	this.ReadsSettled++
+	this.Score = calcReputationScore(this, currTime)
+}
+
+// addMissedWindows counts the pdp windows a node missed before its pdp record ended without a prove
+func (this *NodeReputation) addMissedWindows(currTime uint64, missedWindows uint64) {
+	this.refresh(currTime)
+	this.MissedWindows += missedWindows
+	this.Score = calcReputationScore(this, currTime)
+}
+
+// calcReputationScore gives a score up to DefaultReputationMaxScore. A new node is in the middle of the
+// service part of the score, faults weigh more than good services, and uptime adds a small bonus.
+func calcReputationScore(reputation *NodeReputation, currTime uint64) uint64 {
+	good := reputation.ProveOnTime + reputation.ReadsSettled
+	bad := reputation.ProveLate + reputation.MissedWindows*DefaultReputationMissWeight
+
+	serviceScore := DefaultReputationServiceScore * (good + DefaultReputationNeutralWeight) /
+		(good + bad + 2*DefaultReputationNeutralWeight)
+
+	var uptimeBonus uint64
+	if currTime > reputation.RegisterTime {
+		uptimeBonus = (currTime - reputation.RegisterTime) / DefaultReputationUptimeUnit
+	}
+	if uptimeBonus > DefaultReputationMaxScore-DefaultReputationServiceScore {
+		uptimeBonus = DefaultReputationMaxScore - DefaultReputationServiceScore
+	}
+	return serviceScore + uptimeBonus
+}
+
+// countMissedWindows returns the pdp intervals between the last prove and this one which got no prove
+func countMissedWindows(lastPdpTime uint64, currPdpEndPoint uint64, pdpInterval uint64) uint64 {
+	if pdpInterval == 0 || currPdpEndPoint <= lastPdpTime {
+		return 0
+	}
+	windows := (currPdpEndPoint - lastPdpTime) / pdpInterval
+	if windows == 0 {
+		return 0
+	}
+	return windows - 1
+}
+
+// countRecordMissedWindows returns the pdp windows of the file the node missed since its last prove, up to
+// the current one or the file expiry
+func countRecordMissedWindows(fileInfo *FileInfo, pdpRecord *PdpRecord, currTime uint64) uint64 {
+	currPdpEndPoint := calcPdpEndPoint(fileInfo.TimeStart, fileInfo.PdpInterval, currTime)
+	if currPdpEndPoint > fileInfo.TimeExpired {
+		currPdpEndPoint = fileInfo.TimeExpired
+	}
+	return countMissedWindows(pdpRecord.LastPdpTime, currPdpEndPoint, fileInfo.PdpInterval)
+}
@@ -19,33 +19,55 @@
 package ontfs
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology-crypto/signature"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 )
 
+// A passport authorizes one query of WalletAddr's data, it is bound to the query method and
+// the digest of its other parameters. It is signed by the wallet, or by a key of the ONT ID
+// in Delegate when the wallet delegates its read-only listings to that ONT ID.
 type Passport struct {
 	BlockHeight uint64
 	BlockHash   []byte
 	WalletAddr  common.Address
+	Method      []byte
+	ParamDigest []byte
+	Delegate    []byte //ONT ID signing for WalletAddr, empty when the wallet signs
 	PublicKey   []byte
 	Signature   []byte
 }
 
+// passportDelegateMethods are the read-only listings a delegated passport may authorize
+var passportDelegateMethods = map[string]bool{
+	FS_GET_FILE_LIST: true,
+}
+
 func (this *Passport) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
 	utils.EncodeVarUint(sink, this.BlockHeight)
 	sink.WriteVarBytes(this.BlockHash)
 	utils.EncodeAddress(sink, this.WalletAddr)
+	sink.WriteVarBytes(this.Method)
+	sink.WriteVarBytes(this.ParamDigest)
+	sink.WriteVarBytes(this.Delegate)
 	sink.WriteVarBytes(this.PublicKey)
 	sink.WriteVarBytes(this.Signature)
 }
 
 func (this *Passport) Deserialization(source *common.ZeroCopySource) error {
-	var err error
+	version, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
 	this.BlockHeight, err = utils.DecodeVarUint(source)
 	if err != nil {
 		return err
@@ -58,6 +80,17 @@ func (this *Passport) Deserialization(source *common.ZeroCopySource) error {
 	if err != nil {
 		return err
 	}
+	if version != RecordVersionLegacy {
+		if this.Method, err = DecodeVarBytes(source); err != nil {
+			return err
+		}
+		if this.ParamDigest, err = DecodeVarBytes(source); err != nil {
+			return err
+		}
+		if this.Delegate, err = DecodeVarBytes(source); err != nil {
+			return err
+		}
+	}
 	this.PublicKey, err = DecodeVarBytes(source)
 	if err != nil {
 		return err
@@ -69,7 +102,32 @@ func (this *Passport) Deserialization(source *common.ZeroCopySource) error {
 	return nil
 }
 
-func CheckPassport(currBlockHeight uint64, passportData []byte) (common.Address, error) {
+// SignData returns the data the wallet signs, the passport serialized without its signature
+func (this *Passport) SignData() []byte {
+	passportTmp := Passport{
+		BlockHeight: this.BlockHeight,
+		BlockHash:   this.BlockHash,
+		WalletAddr:  this.WalletAddr,
+		Method:      this.Method,
+		ParamDigest: this.ParamDigest,
+		Delegate:    this.Delegate,
+		PublicKey:   this.PublicKey,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	passportTmp.Serialization(sink)
+	return sink.Bytes()
+}
+
+// PassportParamDigest returns the digest of the query parameters a passport is bound to
+func PassportParamDigest(param []byte) []byte {
+	digest := sha256.Sum256(param)
+	return digest[:]
+}
+
+// CheckPassport checks the passport authorizes the query of method with param, and returns
+// the wallet whose data is queried
+func CheckPassport(native *native.NativeService, method string, param []byte, passportData []byte) (common.Address, error) {
 	var err error
 	var passport Passport
 	src := common.NewZeroCopySource(passportData)
@@ -77,38 +135,61 @@ func CheckPassport(currBlockHeight uint64, passportData []byte) (common.Address,
 		return common.ADDRESS_EMPTY, fmt.Errorf("CheckPassport Deserialization error")
 	}
 
-	if passport.BlockHeight > currBlockHeight || passport.BlockHeight+DefaultPassportExpire < currBlockHeight {
+	globalParam, err := getGlobalParam(native)
+	if err != nil {
+		return passport.WalletAddr, fmt.Errorf("CheckPassport getGlobalParam error: %s", err.Error())
+	}
+	currBlockHeight := uint64(native.Height)
+	if passport.BlockHeight > currBlockHeight || passport.BlockHeight+globalParam.PassportExpire < currBlockHeight {
 		return passport.WalletAddr, fmt.Errorf("CheckPassport passport expired")
 	}
 
-	pubKey, err := keypair.DeserializePublicKey(passport.PublicKey)
-	if err != nil {
-		return passport.WalletAddr, fmt.Errorf("CheckPassport DeserializePublicKey error: %s", err.Error())
+	header, err := native.Store.GetHeaderByHeight(uint32(passport.BlockHeight))
+	if err != nil || header == nil {
+		return passport.WalletAddr, fmt.Errorf("CheckPassport GetHeaderByHeight error")
+	}
+	blockHash := header.Hash()
+	if !bytes.Equal(passport.BlockHash, blockHash[:]) {
+		return passport.WalletAddr, fmt.Errorf("CheckPassport BlockHash not match the block of BlockHeight")
 	}
 
-	addr := types.AddressFromPubKey(pubKey)
-	if addr != passport.WalletAddr {
-		return passport.WalletAddr, fmt.Errorf("CheckPassport Pubkey not match walletAddr ")
+	if string(passport.Method) != method {
+		return passport.WalletAddr, fmt.Errorf("CheckPassport passport is not for method %s", method)
+	}
+	if !bytes.Equal(passport.ParamDigest, PassportParamDigest(param)) {
+		return passport.WalletAddr, fmt.Errorf("CheckPassport ParamDigest not match")
 	}
 
-	passportTmp := Passport{
-		BlockHeight: passport.BlockHeight,
-		BlockHash:   passport.BlockHash,
-		WalletAddr:  passport.WalletAddr,
-		PublicKey:   passport.PublicKey,
+	pubKey, err := keypair.DeserializePublicKey(passport.PublicKey)
+	if err != nil {
+		return passport.WalletAddr, fmt.Errorf("CheckPassport DeserializePublicKey error: %s", err.Error())
 	}
 
-	sink := common.NewZeroCopySink(nil)
-	passportTmp.Serialization(sink)
+	if len(passport.Delegate) == 0 {
+		addr := types.AddressFromPubKey(pubKey)
+		if addr != passport.WalletAddr {
+			return passport.WalletAddr, fmt.Errorf("CheckPassport Pubkey not match walletAddr ")
+		}
+	} else {
+		if !passportDelegateMethods[method] {
+			return passport.WalletAddr, fmt.Errorf("CheckPassport method %s can't be delegated", method)
+		}
+		if !isPassportDelegate(native, passport.WalletAddr, passport.Delegate) {
+			return passport.WalletAddr, fmt.Errorf("CheckPassport %s is not a delegate of walletAddr", passport.Delegate)
+		}
+		if err = ontid.VerifyIDKey(native, passport.Delegate, passport.PublicKey); err != nil {
+			return passport.WalletAddr, fmt.Errorf("CheckPassport VerifyIDKey error: %s", err.Error())
+		}
+	}
 
 	signValue, err := signature.Deserialize(passport.Signature)
 	if err != nil {
 		return passport.WalletAddr, fmt.Errorf("CheckPassport signature Deserialize error: %s", err.Error())
 	}
 
-	if signature.Verify(pubKey, sink.Bytes(), signValue) {
+	if signature.Verify(pubKey, passport.SignData(), signValue) {
 		return passport.WalletAddr, nil
 	} else {
-		return passport.WalletAddr, fmt.Errorf("CheckPassport Verify error: %s", err.Error())
+		return passport.WalletAddr, fmt.Errorf("CheckPassport Verify error")
 	}
 }
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/errors"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// PassportDelegates are the ONT IDs whose controllers issue passports for the read-only
+// listings of WalletAddr
+type PassportDelegates struct {
+	WalletAddr common.Address
+	OntIds     [][]byte
+}
+
+func (this *PassportDelegates) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
+	utils.EncodeAddress(sink, this.WalletAddr)
+	utils.EncodeVarUint(sink, uint64(len(this.OntIds)))
+	for _, ontId := range this.OntIds {
+		sink.WriteVarBytes(ontId)
+	}
+}
+
+func (this *PassportDelegates) Deserialization(source *common.ZeroCopySource) error {
+	_, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
+	if this.WalletAddr, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	count, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	if count > uint64(source.Len()) {
+		return fmt.Errorf("PassportDelegates Deserialization count error")
+	}
+	this.OntIds = nil
+	for i := uint64(0); i < count; i++ {
+		ontId, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		this.OntIds = append(this.OntIds, ontId)
+	}
+	return nil
+}
+
+// check returns an error when the delegates can't be set
+func (this *PassportDelegates) check() error {
+	if len(this.OntIds) > DefaultMaxPassportDelegates {
+		return fmt.Errorf("more than %d delegates", DefaultMaxPassportDelegates)
+	}
+	for i, ontId := range this.OntIds {
+		if len(ontId) == 0 || len(ontId) > 255 {
+			return fmt.Errorf("invalid ONT ID length")
+		}
+		for _, other := range this.OntIds[:i] {
+			if bytes.Equal(ontId, other) {
+				return fmt.Errorf("duplicated ONT ID %s", ontId)
+			}
+		}
+	}
+	return nil
+}
+
+func setPassportDelegates(native *native.NativeService, delegates *PassportDelegates) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	key := GenFsPassportDelegateKey(contract, delegates.WalletAddr)
+	if len(delegates.OntIds) == 0 {
+		native.CacheDB.Delete(key)
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	delegates.Serialization(sink)
+	utils.PutBytes(native, key, sink.Bytes())
+}
+
+func getPassportDelegates(native *native.NativeService, walletAddr common.Address) (*PassportDelegates, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	item, err := utils.GetStorageItem(native, GenFsPassportDelegateKey(contract, walletAddr))
+	if err != nil {
+		return nil, fmt.Errorf("getPassportDelegates GetStorageItem error: %s", err.Error())
+	}
+	delegates := &PassportDelegates{WalletAddr: walletAddr}
+	if item == nil || item.Value == nil {
+		return delegates, nil
+	}
+	if err = delegates.Deserialization(common.NewZeroCopySource(item.Value)); err != nil {
+		return nil, fmt.Errorf("getPassportDelegates Deserialization error: %s", err.Error())
+	}
+	return delegates, nil
+}
+
+func isPassportDelegate(native *native.NativeService, walletAddr common.Address, ontId []byte) bool {
+	delegates, err := getPassportDelegates(native, walletAddr)
+	if err != nil {
+		return false
+	}
+	for _, delegate := range delegates.OntIds {
+		if bytes.Equal(delegate, ontId) {
+			return true
+		}
+	}
+	return false
+}
+
+// FsSetPassportDelegates replaces the ONT IDs the wallet delegates its read-only listings to,
+// an empty list revokes every delegation
+func FsSetPassportDelegates(native *native.NativeService) ([]byte, error) {
+	var delegates PassportDelegates
+	source := common.NewZeroCopySource(native.Input)
+	if err := delegates.Deserialization(source); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetPassportDelegates Deserialization error!")
+	}
+	if !native.ContextRef.CheckWitness(delegates.WalletAddr) {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetPassportDelegates CheckWalletAddr failed!")
+	}
+	if err := delegates.check(); err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsSetPassportDelegates " + err.Error())
+	}
+	setPassportDelegates(native, &delegates)
+	return utils.BYTE_TRUE, nil
+}
+
+func FsGetPassportDelegates(native *native.NativeService) ([]byte, error) {
+	source := common.NewZeroCopySource(native.Input)
+	walletAddr, err := utils.DecodeAddress(source)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetPassportDelegates DecodeAddress error!")), nil
+	}
+	delegates, err := getPassportDelegates(native, walletAddr)
+	if err != nil {
+		return EncRet(false, []byte("[APP SDK] FsGetPassportDelegates getPassportDelegates error!")), nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	delegates.Serialization(sink)
+	return EncRet(true, sink.Bytes()), nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/service/native"
+)
+
+// BlockRandomness returns the vbft VRF value of the block, or the block hash when the block carries no VRF
+// value (solo consensus)
+func BlockRandomness(header *types.Header) []byte {
+	var blockInfo vconfig.VbftBlockInfo
+	if err := json.Unmarshal(header.ConsensusPayload, &blockInfo); err == nil && len(blockInfo.VrfValue) != 0 {
+		return blockInfo.VrfValue
+	}
+	blockHash := header.Hash()
+	return blockHash.ToArray()
+}
+
+// GenPdpChallengeSeed mixes the block randomness with the file and the node, so every node gets its own
+// challenge for every file. Storage nodes use it to build the prove of the challenge height.
+func GenPdpChallengeSeed(randomness []byte, fileHash []byte, nodeAddr common.Address) []byte {
+	sha := sha256.New()
+	sha.Write(randomness)
+	sha.Write(fileHash)
+	sha.Write(nodeAddr[:])
+	return sha.Sum(nil)
+}
+
+// nextChallengeHeight returns the base height of the next pdp challenge, set by the prove of the node
+func nextChallengeHeight(native *native.NativeService) uint64 {
+	return uint64(native.Height) + DefaultPdpHeightIV
+}
+
+// PdpChallengeHeight adds a random jitter to the base challenge height of the pdp record. The jitter comes
+// from the randomness of the block at the base height, which is unknown when the prove setting it is sent.
+// Storage nodes use it to find the block they are challenged at.
+func PdpChallengeHeight(randomness []byte, pdpRecord *PdpRecord) uint64 {
+	seed := GenPdpChallengeSeed(randomness, pdpRecord.FileHash, pdpRecord.NodeAddr)
+	sha := sha256.New()
+	sha.Write(seed)
+	binary.Write(sha, binary.LittleEndian, pdpRecord.PdpCount)
+	jitter := binary.LittleEndian.Uint64(sha.Sum(nil)) % DefaultPdpHeightJitter
+	return pdpRecord.NextHeight + jitter
+}
+
+// challengeJittered tells whether pdp challenges take the jitter and the mixed seed, from the ontfs pdp
+// challenge height on. Before it a node proves at the base height against the block hash.
+func challengeJittered(native *native.NativeService) bool {
+	return native.Height >= config.GetOntFsPdpChallengeHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+// challengeHeight returns the height the node has to prove the pdp record at
+func challengeHeight(native *native.NativeService, pdpRecord *PdpRecord) (uint64, error) {
+	if !challengeJittered(native) {
+		return pdpRecord.NextHeight, nil
+	}
+	header, err := native.Store.GetHeaderByHeight(uint32(pdpRecord.NextHeight))
+	if err != nil || header == nil {
+		return 0, fmt.Errorf("challenge base block %d not reached", pdpRecord.NextHeight)
+	}
+	return PdpChallengeHeight(BlockRandomness(header), pdpRecord), nil
+}
+
+// challengeSeed returns the seed the node is challenged with in the block of the challenge height. The vbft
+// payload is only decoded from the ontfs pdp challenge height on, before it the seed is the block hash.
+func challengeSeed(native *native.NativeService, header *types.Header, pdpData *PdpData) []byte {
+	if !challengeJittered(native) {
+		blockHash := header.Hash()
+		return blockHash.ToArray()
+	}
+	return GenPdpChallengeSeed(BlockRandomness(header), pdpData.FileHash, pdpData.NodeAddr)
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallengeHeight(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	chain, err := testsuite.NewChain(100, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pdpRecord *PdpRecord
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		pdpRecord = &PdpRecord{FileHash: []byte("file"), NodeAddr: common.Address{0x01}, PdpCount: 1,
+			NextHeight: nextChallengeHeight(native)}
+	})
+
+	//before the challenge height the node proves at the base height
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		challenge, err := challengeHeight(native, pdpRecord)
+		assert.Nil(t, err)
+		assert.Equal(t, pdpRecord.NextHeight, challenge)
+	})
+
+	//then the jitter is unknown until the base block
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		_, err := challengeHeight(native, pdpRecord)
+		assert.NotNil(t, err)
+	})
+	chain.AddBlocks(DefaultPdpHeightIV)
+	header, err := chain.GetHeaderByHeight(uint32(pdpRecord.NextHeight))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		challenge, err := challengeHeight(native, pdpRecord)
+		assert.Nil(t, err)
+		assert.Equal(t, PdpChallengeHeight(BlockRandomness(header), pdpRecord), challenge)
+		assert.True(t, challenge >= pdpRecord.NextHeight && challenge < pdpRecord.NextHeight+DefaultPdpHeightJitter)
+	})
+
+	//the node is challenged with the block hash before the challenge height, the mixed seed after it
+	pdpData := &PdpData{FileHash: pdpRecord.FileHash, NodeAddr: pdpRecord.NodeAddr}
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		assert.Equal(t, GenPdpChallengeSeed(BlockRandomness(header), pdpData.FileHash, pdpData.NodeAddr),
+			challengeSeed(native, header, pdpData))
+	})
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	blockHash := header.Hash()
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		assert.Equal(t, blockHash.ToArray(), challengeSeed(native, header, pdpData))
+	})
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// FsFeeConfig is the protocol fee governance takes from every storage and read payment to the nodes.
+// The insurance part of the fee goes to the insurance pool, the rest accrues to Treasury.
+type FsFeeConfig struct {
+	ProtocolFeeRate uint64         //per ten thousand of every node payment
+	InsuranceRate   uint64         //percent of the protocol fee put into the insurance pool
+	Treasury        common.Address //withdraws the treasury part of the protocol fee
+}
+
+// FsFeePool holds the protocol fees kept by the contract. The insurance pool also receives the
+// pledge slashed from nodes which lost a file, and compensates the owners of lost files.
+type FsFeePool struct {
+	TreasuryBalance  uint64
+	InsuranceBalance uint64
+}
+
+func (this *FsFeeConfig) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
+	utils.EncodeVarUint(sink, this.ProtocolFeeRate)
+	utils.EncodeVarUint(sink, this.InsuranceRate)
+	utils.EncodeAddress(sink, this.Treasury)
+}
+
+func (this *FsFeeConfig) Deserialization(source *common.ZeroCopySource) error {
+	_, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
+	this.ProtocolFeeRate, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.InsuranceRate, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.Treasury, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *FsFeePool) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
+	utils.EncodeVarUint(sink, this.TreasuryBalance)
+	utils.EncodeVarUint(sink, this.InsuranceBalance)
+}
+
+func (this *FsFeePool) Deserialization(source *common.ZeroCopySource) error {
+	_, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
+	this.TreasuryBalance, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.InsuranceBalance, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// check returns an error when the config can't be applied
+func (this *FsFeeConfig) check() error {
+	if this.ProtocolFeeRate > DefaultProtocolFeeRateBase {
+		return fmt.Errorf("ProtocolFeeRate > %d", DefaultProtocolFeeRateBase)
+	}
+	if this.InsuranceRate > 100 {
+		return fmt.Errorf("InsuranceRate > 100")
+	}
+	if this.ProtocolFeeRate != 0 && this.InsuranceRate != 100 && this.Treasury == common.ADDRESS_EMPTY {
+		return fmt.Errorf("Treasury is empty")
+	}
+	return nil
+}
+
+// split returns the treasury and the insurance parts of the protocol fee on amount
+func (this *FsFeeConfig) split(amount uint64) (uint64, uint64) {
+	//amount * rate / base without overflow, rate <= base
+	fee := amount/DefaultProtocolFeeRateBase*this.ProtocolFeeRate +
+		amount%DefaultProtocolFeeRateBase*this.ProtocolFeeRate/DefaultProtocolFeeRateBase
+	insurance := fee / 100 * this.InsuranceRate
+	insurance += fee % 100 * this.InsuranceRate / 100
+	return fee - insurance, insurance
+}
+
+func setFeeConfig(native *native.NativeService, feeConfig *FsFeeConfig) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	sink := common.NewZeroCopySink(nil)
+	feeConfig.Serialization(sink)
+	utils.PutBytes(native, GenFsFeeConfigKey(contract), sink.Bytes())
+}
+
+// getFeeConfig returns the fee config, no protocol fee is taken before governance sets one
+func getFeeConfig(native *native.NativeService) (*FsFeeConfig, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	item, err := utils.GetStorageItem(native, GenFsFeeConfigKey(contract))
+	if err != nil {
+		return nil, fmt.Errorf("getFeeConfig GetStorageItem error: %s", err.Error())
+	}
+	feeConfig := &FsFeeConfig{InsuranceRate: DefaultInsuranceRate}
+	if item == nil || item.Value == nil {
+		return feeConfig, nil
+	}
+	if err = feeConfig.Deserialization(common.NewZeroCopySource(item.Value)); err != nil {
+		return nil, fmt.Errorf("getFeeConfig Deserialization error: %s", err.Error())
+	}
+	return feeConfig, nil
+}
+
+func setFeePool(native *native.NativeService, feePool *FsFeePool) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	sink := common.NewZeroCopySink(nil)
+	feePool.Serialization(sink)
+	utils.PutBytes(native, GenFsFeePoolKey(contract), sink.Bytes())
+}
+
+func getFeePool(native *native.NativeService) (*FsFeePool, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	item, err := utils.GetStorageItem(native, GenFsFeePoolKey(contract))
+	if err != nil {
+		return nil, fmt.Errorf("getFeePool GetStorageItem error: %s", err.Error())
+	}
+	feePool := new(FsFeePool)
+	if item == nil || item.Value == nil {
+		return feePool, nil
+	}
+	if err = feePool.Deserialization(common.NewZeroCopySource(item.Value)); err != nil {
+		return nil, fmt.Errorf("getFeePool Deserialization error: %s", err.Error())
+	}
+	return feePool, nil
+}
+
+// takeProtocolFee moves the protocol fee on a node payment into the fee pool and returns what
+// is left for the node. The payment stays in the contract either way.
+func takeProtocolFee(native *native.NativeService, payment uint64) (uint64, error) {
+	feeConfig, err := getFeeConfig(native)
+	if err != nil {
+		return 0, err
+	}
+	treasury, insurance := feeConfig.split(payment)
+	if treasury+insurance == 0 {
+		return payment, nil
+	}
+
+	feePool, err := getFeePool(native)
+	if err != nil {
+		return 0, err
+	}
+	feePool.TreasuryBalance += treasury
+	feePool.InsuranceBalance += insurance
+	setFeePool(native, feePool)
+	return payment - treasury - insurance, nil
+}
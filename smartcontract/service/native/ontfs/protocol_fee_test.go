@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFsFeeConfig_Split(t *testing.T) {
+	feeConfig := FsFeeConfig{ProtocolFeeRate: 250, InsuranceRate: 40, Treasury: common.Address{0x01}}
+	treasury, insurance := feeConfig.split(10000)
+	assert.Equal(t, uint64(150), treasury)
+	assert.Equal(t, uint64(100), insurance)
+
+	//the fee is rounded down
+	treasury, insurance = feeConfig.split(39)
+	assert.Equal(t, uint64(0), treasury+insurance)
+
+	//no overflow on the largest payment
+	feeConfig.ProtocolFeeRate = DefaultProtocolFeeRateBase
+	feeConfig.InsuranceRate = 100
+	treasury, insurance = feeConfig.split(^uint64(0))
+	assert.Equal(t, uint64(0), treasury)
+	assert.Equal(t, ^uint64(0), insurance)
+
+	feeConfig = FsFeeConfig{InsuranceRate: DefaultInsuranceRate}
+	treasury, insurance = feeConfig.split(10000)
+	assert.Equal(t, uint64(0), treasury+insurance)
+}
+
+func TestFsFeeConfig_Check(t *testing.T) {
+	assert.Nil(t, (&FsFeeConfig{InsuranceRate: DefaultInsuranceRate}).check())
+	assert.Nil(t, (&FsFeeConfig{ProtocolFeeRate: 100, InsuranceRate: 100}).check())
+	assert.Nil(t, (&FsFeeConfig{ProtocolFeeRate: 100, InsuranceRate: 50, Treasury: common.Address{0x01}}).check())
+
+	assert.Error(t, (&FsFeeConfig{ProtocolFeeRate: 100, InsuranceRate: 50}).check())
+	assert.Error(t, (&FsFeeConfig{ProtocolFeeRate: DefaultProtocolFeeRateBase + 1, InsuranceRate: 100}).check())
+	assert.Error(t, (&FsFeeConfig{ProtocolFeeRate: 100, InsuranceRate: 101}).check())
+}
+
+func TestFsFeeConfig_Serialization(t *testing.T) {
+	feeConfig := FsFeeConfig{ProtocolFeeRate: 250, InsuranceRate: 40, Treasury: common.Address{0x01}}
+	sink := common.NewZeroCopySink(nil)
+	feeConfig.Serialization(sink)
+
+	var feeConfig2 FsFeeConfig
+	assert.Nil(t, feeConfig2.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, feeConfig, feeConfig2)
+
+	feePool := FsFeePool{TreasuryBalance: 150, InsuranceBalance: 100}
+	sink = common.NewZeroCopySink(nil)
+	feePool.Serialization(sink)
+
+	var feePool2 FsFeePool
+	assert.Nil(t, feePool2.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, feePool, feePool2)
+}
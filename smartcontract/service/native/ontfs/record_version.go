@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// Records stored before versioning start with a var bytes length, which is never 0xFF
+// for them, so the marker tells a versioned record from a legacy one. Every field added
+// to a record bumps the version, a versioned record carries the fields of its version.
+const (
+	RecordVersionMarker byte = 0xFF
+
+	RecordVersionLegacy  byte = 0 //positional encoding without prefix, trailing fields optional
+	RecordVersion1       byte = 1 //the fields of the records before the ones below were added
+	RecordVersion2       byte = 2 //FileInfo.BlockSize
+	RecordVersion3       byte = 3 //FsNodeInfo.Reputation
+	RecordVersion4       byte = 4 //FsNodeInfo.LockedProfit and FsNodeInfo.VestedProfit
+	RecordVersion5       byte = 5 //FsNodeInfo.Metadata
+	CurrentRecordVersion      = RecordVersion5
+)
+
+type versionedRecord interface {
+	Serialization(sink *common.ZeroCopySink)
+	Deserialization(source *common.ZeroCopySource) error
+}
+
+func encodeRecordVersion(sink *common.ZeroCopySink) {
+	sink.WriteByte(RecordVersionMarker)
+	sink.WriteByte(CurrentRecordVersion)
+}
+
+// decodeRecordVersion consumes the version prefix of a record, legacy records have none
+func decodeRecordVersion(source *common.ZeroCopySource) (byte, error) {
+	marker, eof := source.NextByte()
+	if eof {
+		return 0, fmt.Errorf("decodeRecordVersion error: eof")
+	}
+	if marker != RecordVersionMarker {
+		source.BackUp(1)
+		return RecordVersionLegacy, nil
+	}
+	version, eof := source.NextByte()
+	if eof {
+		return 0, fmt.Errorf("decodeRecordVersion error: eof")
+	}
+	if version == RecordVersionLegacy || version > CurrentRecordVersion {
+		return 0, fmt.Errorf("decodeRecordVersion error: unsupported version %d", version)
+	}
+	return version, nil
+}
+
+// hasRecordField tells whether a record of version carries the field added in since, a legacy
+// record carries the trailing fields the binary which stored it knew about
+func hasRecordField(source *common.ZeroCopySource, version byte, since byte) bool {
+	if version == RecordVersionLegacy {
+		return source.Len() > 0
+	}
+	return version >= since
+}
+
+// RecordVersion returns the version of a raw ontfs record
+func RecordVersion(data []byte) (byte, error) {
+	return decodeRecordVersion(common.NewZeroCopySource(data))
+}
+
+// migrateRecords rewrites the records under prefix that are older than the current version
+func migrateRecords(native *native.NativeService, prefix []byte, newRecord func() versionedRecord) (uint64, error) {
+	var keys, values [][]byte
+	auditRecords(native, prefix, func(key []byte, value []byte) {
+		keys = append(keys, key)
+		values = append(values, value)
+	})
+
+	var count uint64
+	for i, value := range values {
+		version, err := RecordVersion(value)
+		if err != nil {
+			return count, fmt.Errorf("migrateRecords key %x error: %s", keys[i], err.Error())
+		}
+		if version == CurrentRecordVersion {
+			continue
+		}
+		record := newRecord()
+		if err = record.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return count, fmt.Errorf("migrateRecords key %x error: %s", keys[i], err.Error())
+		}
+		sink := common.NewZeroCopySink(nil)
+		record.Serialization(sink)
+		utils.PutBytes(native, keys[i], sink.Bytes())
+		count++
+	}
+	return count, nil
+}
+
+// MigrateRecords rewrites every file, node, space and read pledge record in the current
+// version. It runs once at the configured ontfs record upgrade height.
+func MigrateRecords(native *native.NativeService) (uint64, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	migrations := []struct {
+		prefix    []byte
+		newRecord func() versionedRecord
+	}{
+		{append(contract[:], ONTFS_FILE_INFO...), func() versionedRecord { return new(FileInfo) }},
+		{GenFsNodeInfoPrefix(contract), func() versionedRecord { return new(FsNodeInfo) }},
+		{append(contract[:], ONTFS_FILE_SPACE...), func() versionedRecord { return new(SpaceInfo) }},
+		{append(contract[:], ONTFS_FILE_READ_PLEDGE...), func() versionedRecord { return new(ReadPledge) }},
+	}
+
+	var total uint64
+	for _, migration := range migrations {
+		count, err := migrateRecords(native, migration.prefix, migration.newRecord)
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// legacyBytes strips the version prefix, giving the encoding records had before versioning
+func legacyBytes(record versionedRecord) []byte {
+	sink := common.NewZeroCopySink(nil)
+	record.Serialization(sink)
+	return sink.Bytes()[2:]
+}
+
+func TestRecordVersion_RoundTrip(t *testing.T) {
+	records := []struct {
+		record    versionedRecord
+		newRecord func() versionedRecord
+	}{
+		{&FileInfo{FileHash: []byte("file"), FileOwner: common.Address{0x01}, CopyNumber: 2, ValidFlag: true,
+			BlockSize: 256}, func() versionedRecord { return new(FileInfo) }},
+		{&FsNodeInfo{Pledge: 10, Profit: 20, NodeAddr: common.Address{0x02}, NodeNetAddr: []byte("1.1.1.1:1"),
+			LockedProfit: 5, VestedProfit: 15}, func() versionedRecord { return new(FsNodeInfo) }},
+		{&SpaceInfo{SpaceOwner: common.Address{0x03}, Volume: 100, RestVol: 50, ValidFlag: true},
+			func() versionedRecord { return new(SpaceInfo) }},
+		{&ReadPledge{FileHash: []byte("file"), Downloader: common.Address{0x04}, RestMoney: 30,
+			ReadPlans: []ReadPlan{{NodeAddr: common.Address{0x05}, MaxReadBlockNum: 8}}},
+			func() versionedRecord { return new(ReadPledge) }},
+	}
+
+	for _, r := range records {
+		sink := common.NewZeroCopySink(nil)
+		r.record.Serialization(sink)
+		version, err := RecordVersion(sink.Bytes())
+		assert.Nil(t, err)
+		assert.Equal(t, CurrentRecordVersion, version)
+
+		current := r.newRecord()
+		assert.Nil(t, current.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+		assert.Equal(t, r.record, current)
+
+		legacy := legacyBytes(r.record)
+		version, err = RecordVersion(legacy)
+		assert.Nil(t, err)
+		assert.Equal(t, RecordVersionLegacy, version)
+
+		old := r.newRecord()
+		assert.Nil(t, old.Deserialization(common.NewZeroCopySource(legacy)))
+		assert.Equal(t, r.record, old)
+	}
+}
+
+func TestRecordVersion_LegacyTrailingFields(t *testing.T) {
+	nodeInfo := FsNodeInfo{Pledge: 10, Profit: 20, NodeAddr: common.Address{0x02}, NodeNetAddr: []byte("1.1.1.1:1")}
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, nodeInfo.Pledge)
+	utils.EncodeVarUint(sink, nodeInfo.Profit)
+	utils.EncodeVarUint(sink, nodeInfo.Volume)
+	utils.EncodeVarUint(sink, nodeInfo.RestVol)
+	utils.EncodeVarUint(sink, nodeInfo.ServiceTime)
+	utils.EncodeVarUint(sink, nodeInfo.MinPdpInterval)
+	utils.EncodeAddress(sink, nodeInfo.NodeAddr)
+	sink.WriteVarBytes(nodeInfo.NodeNetAddr)
+
+	var legacy FsNodeInfo
+	assert.Nil(t, legacy.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, nodeInfo.Profit, legacy.VestedProfit)
+
+	//a versioned record must carry every field
+	versioned := append([]byte{RecordVersionMarker, CurrentRecordVersion}, sink.Bytes()...)
+	var current FsNodeInfo
+	assert.NotNil(t, current.Deserialization(common.NewZeroCopySource(versioned)))
+}
+
+func TestRecordVersion_FieldsOfVersion(t *testing.T) {
+	nodeInfo := FsNodeInfo{Pledge: 10, Profit: 20, NodeAddr: common.Address{0x02}, NodeNetAddr: []byte("1.1.1.1:1")}
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, nodeInfo.Pledge)
+	utils.EncodeVarUint(sink, nodeInfo.Profit)
+	utils.EncodeVarUint(sink, nodeInfo.Volume)
+	utils.EncodeVarUint(sink, nodeInfo.RestVol)
+	utils.EncodeVarUint(sink, nodeInfo.ServiceTime)
+	utils.EncodeVarUint(sink, nodeInfo.MinPdpInterval)
+	utils.EncodeAddress(sink, nodeInfo.NodeAddr)
+	sink.WriteVarBytes(nodeInfo.NodeNetAddr)
+
+	//a record of an older version carries only the fields of that version
+	var v1 FsNodeInfo
+	data := append([]byte{RecordVersionMarker, RecordVersion1}, sink.Bytes()...)
+	assert.Nil(t, v1.Deserialization(common.NewZeroCopySource(data)))
+	assert.Equal(t, nodeInfo.Profit, v1.VestedProfit)
+
+	nodeInfo.Reputation.Serialization(sink)
+	var v3 FsNodeInfo
+	data = append([]byte{RecordVersionMarker, RecordVersion3}, sink.Bytes()...)
+	assert.Nil(t, v3.Deserialization(common.NewZeroCopySource(data)))
+	assert.Equal(t, nodeInfo.Profit, v3.VestedProfit)
+
+	//the fields of its version are not optional
+	var v4 FsNodeInfo
+	data = append([]byte{RecordVersionMarker, RecordVersion4}, sink.Bytes()...)
+	assert.NotNil(t, v4.Deserialization(common.NewZeroCopySource(data)))
+}
+
+func TestWhiteList_Stored(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	whiteList := &WhiteList{UsersAddr: []common.Address{{0x01}, {0x02}}}
+	sink := common.NewZeroCopySink(nil)
+	whiteList.Serialization(sink)
+
+	chain, err := testsuite.NewChain(100, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		//white lists keep their encoding across the record upgrade height
+		for _, networkId := range []uint32{config.NETWORK_ID_MAIN_NET, config.NETWORK_ID_SOLO_NET} {
+			config.DefConfig.P2PNode.NetworkId = networkId
+			setWhiteList(native, common.Address{0x03}, []byte("file"), whiteList)
+			assert.Equal(t, sink.Bytes(), getRawWhiteList(native, common.Address{0x03}, []byte("file")))
+			assert.Equal(t, whiteList, getWhiteList(native, common.Address{0x03}, []byte("file")))
+			assert.True(t, inWhiteList(getWhiteList(native, common.Address{0x03}, []byte("file")), common.Address{0x02}))
+			assert.False(t, inWhiteList(getWhiteList(native, common.Address{0x03}, []byte("file")), common.Address{0x04}))
+		}
+	})
+}
+
+func TestRecordVersion_Unsupported(t *testing.T) {
+	_, err := RecordVersion([]byte{RecordVersionMarker, CurrentRecordVersion + 1})
+	assert.NotNil(t, err)
+	_, err = RecordVersion([]byte{RecordVersionMarker, RecordVersionLegacy})
+	assert.NotNil(t, err)
+}
+
+func TestMigrateRecords(t *testing.T) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+	native := &native.NativeService{CacheDB: storage.NewCacheDB(overlaydb.NewOverlayDB(store))}
+
+	contract := utils.OntFSContractAddress
+	prefix := append(contract[:], ONTFS_FILE_SPACE...)
+	oldSpace := &SpaceInfo{SpaceOwner: common.Address{0x01}, Volume: 100, ValidFlag: true}
+	newSpace := &SpaceInfo{SpaceOwner: common.Address{0x02}, Volume: 200}
+	utils.PutBytes(native, GenFsSpaceKey(contract, oldSpace.SpaceOwner), legacyBytes(oldSpace))
+	sink := common.NewZeroCopySink(nil)
+	newSpace.Serialization(sink)
+	utils.PutBytes(native, GenFsSpaceKey(contract, newSpace.SpaceOwner), sink.Bytes())
+
+	newRecord := func() versionedRecord { return new(SpaceInfo) }
+	count, err := migrateRecords(native, prefix, newRecord)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	item, err := utils.GetStorageItem(native, GenFsSpaceKey(contract, oldSpace.SpaceOwner))
+	assert.Nil(t, err)
+	version, err := RecordVersion(item.Value)
+	assert.Nil(t, err)
+	assert.Equal(t, CurrentRecordVersion, version)
+	var migrated SpaceInfo
+	assert.Nil(t, migrated.Deserialization(common.NewZeroCopySource(item.Value)))
+	assert.Equal(t, *oldSpace, migrated)
+
+	count, err = migrateRecords(native, prefix, newRecord)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), count)
+}
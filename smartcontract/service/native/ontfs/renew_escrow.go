@@ -0,0 +1,304 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// RenewEscrow is an owner funded escrow used to renew a file or a space before it expires.
+// An empty FileHash means the escrow renews the owner's space.
+type RenewEscrow struct {
+	Owner       common.Address
+	FileHash    []byte
+	RenewPeriod uint64 //second. time added to TimeExpired by every renewal
+	MaxPrice    uint64 //max fee accepted for a single renewal
+	Balance     uint64
+}
+
+type RenewTarget struct {
+	Owner    common.Address
+	FileHash []byte
+}
+
+type RenewTargetList struct {
+	Caller  common.Address
+	Targets []RenewTarget
+}
+
+func (this *RenewEscrow) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Owner)
+	sink.WriteVarBytes(this.FileHash)
+	utils.EncodeVarUint(sink, this.RenewPeriod)
+	utils.EncodeVarUint(sink, this.MaxPrice)
+	utils.EncodeVarUint(sink, this.Balance)
+}
+
+func (this *RenewEscrow) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.Owner, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	this.FileHash, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	this.RenewPeriod, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.MaxPrice, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.Balance, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *RenewTarget) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Owner)
+	sink.WriteVarBytes(this.FileHash)
+}
+
+func (this *RenewTarget) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.Owner, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	this.FileHash, err = DecodeVarBytes(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *RenewTargetList) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Caller)
+	targetCount := uint64(len(this.Targets))
+	utils.EncodeVarUint(sink, targetCount)
+
+	for _, target := range this.Targets {
+		sinkTmp := common.NewZeroCopySink(nil)
+		target.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+}
+
+func (this *RenewTargetList) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.Caller, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	targetCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < targetCount; i++ {
+		targetTmp, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+
+		var target RenewTarget
+		src := common.NewZeroCopySource(targetTmp)
+		if err = target.Deserialization(src); err != nil {
+			return err
+		}
+		this.Targets = append(this.Targets, target)
+	}
+	return nil
+}
+
+func addRenewEscrow(native *native.NativeService, escrow *RenewEscrow) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	escrowKey := GenFsRenewEscrowKey(contract, escrow.Owner, escrow.FileHash)
+
+	sink := common.NewZeroCopySink(nil)
+	escrow.Serialization(sink)
+	utils.PutBytes(native, escrowKey, sink.Bytes())
+}
+
+func delRenewEscrow(native *native.NativeService, owner common.Address, fileHash []byte) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	escrowKey := GenFsRenewEscrowKey(contract, owner, fileHash)
+	native.CacheDB.Delete(escrowKey)
+}
+
+func getRawRenewEscrow(native *native.NativeService, owner common.Address, fileHash []byte) []byte {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	escrowKey := GenFsRenewEscrowKey(contract, owner, fileHash)
+
+	item, err := utils.GetStorageItem(native, escrowKey)
+	if err != nil || item == nil || item.Value == nil {
+		return nil
+	}
+	return item.Value
+}
+
+func getRenewEscrow(native *native.NativeService, owner common.Address, fileHash []byte) *RenewEscrow {
+	rawEscrow := getRawRenewEscrow(native, owner, fileHash)
+	if rawEscrow == nil {
+		return nil
+	}
+
+	var escrow RenewEscrow
+	source := common.NewZeroCopySource(rawEscrow)
+	if err := escrow.Deserialization(source); err != nil {
+		return nil
+	}
+	return &escrow
+}
+
+// renewWindow is the time before expiry in which a file or a space of pdpInterval may be renewed
+func renewWindow(pdpInterval uint64) uint64 {
+	if pdpInterval > DefaultRenewWindow {
+		return pdpInterval
+	}
+	return DefaultRenewWindow
+}
+
+func inRenewWindow(currTime uint64, timeExpired uint64, pdpInterval uint64) bool {
+	return timeExpired <= currTime+renewWindow(pdpInterval)
+}
+
+// low reports whether the escrow can't pay DefaultRenewLowCount renewals any more. An escrow which
+// can't pay the next renewal is always low, so the low event sent on crossing covers it as well.
+func (this *RenewEscrow) low() bool {
+	return this.Balance < DefaultRenewLowCount*(this.MaxPrice+DefaultRenewCallerReward)
+}
+
+// autoRenew extends the escrow target by one RenewPeriod and charges the fee plus callerReward to the escrow.
+// The target has to leave the renew window, so it is renewed once a window whoever calls.
+func autoRenew(native *native.NativeService, owner common.Address, fileHash []byte, callerReward uint64) error {
+	escrow := getRenewEscrow(native, owner, fileHash)
+	if escrow == nil {
+		return fmt.Errorf("renew escrow not found")
+	}
+
+	globalParam, err := getGlobalParam(native)
+	if err != nil {
+		return fmt.Errorf("getGlobalParam error")
+	}
+
+	var renewFee uint64
+	var fileInfo *FileInfo
+	var space *SpaceInfo
+	var funding *SpaceFunding
+	if len(fileHash) != 0 {
+		fileInfo = getAndUpdateFileInfo(native, owner, fileHash)
+		if fileInfo == nil {
+			return fmt.Errorf("getAndUpdateFileInfo error")
+		}
+		if !fileInfo.ValidFlag {
+			return fmt.Errorf("file is expired")
+		}
+		if fileInfo.StorageType != FileStorageTypeUseFile {
+			return fmt.Errorf("StorageType is not FileStorageTypeUseFile")
+		}
+		if !inRenewWindow(uint64(native.Time), fileInfo.TimeExpired, fileInfo.PdpInterval) {
+			return fmt.Errorf("file is not near expiry")
+		}
+		renewFee, err = renewFileInfo(fileInfo, fileInfo.TimeExpired+escrow.RenewPeriod,
+			globalParam.GasPerKbForSaveWithFile)
+		if err != nil {
+			return err
+		}
+		if inRenewWindow(uint64(native.Time), fileInfo.TimeExpired, fileInfo.PdpInterval) {
+			return fmt.Errorf("RenewPeriod is shorter than the renew window")
+		}
+	} else {
+		space = getAndUpdateSpaceInfo(native, owner)
+		if space == nil {
+			return fmt.Errorf("getAndUpdateSpaceInfo error")
+		}
+		if !space.ValidFlag {
+			return fmt.Errorf("space is expired")
+		}
+		if !inRenewWindow(uint64(native.Time), space.TimeExpired, space.PdpInterval) {
+			return fmt.Errorf("space is not near expiry")
+		}
+		funding, err = getSpaceFunding(native, space)
+		if err != nil {
+			return fmt.Errorf("getSpaceFunding error")
+		}
+		var refund bool
+		renewFee, refund, err = updateSpaceInfo(space, space.Volume, space.TimeExpired+escrow.RenewPeriod,
+			globalParam.GasPerKbForSaveWithSpace)
+		if err != nil {
+			return err
+		}
+		if refund {
+			return fmt.Errorf("space renew fee error")
+		}
+		if inRenewWindow(uint64(native.Time), space.TimeExpired, space.PdpInterval) {
+			return fmt.Errorf("RenewPeriod is shorter than the renew window")
+		}
+	}
+
+	if renewFee > escrow.MaxPrice {
+		return fmt.Errorf("renew fee %d exceeds MaxPrice %d", renewFee, escrow.MaxPrice)
+	}
+	if escrow.Balance < renewFee+callerReward {
+		return fmt.Errorf("renew escrow balance not enough")
+	}
+	wasLow := escrow.low()
+	escrow.Balance -= renewFee + callerReward
+
+	if fileInfo != nil {
+		addFileInfo(native, fileInfo)
+	} else {
+		addSpaceInfo(native, space)
+		funding.fund(owner, renewFee)
+		addSpaceFunding(native, funding)
+	}
+	if !wasLow && escrow.low() {
+		addRenewEscrowLowEvent(native, escrow)
+	}
+	addRenewEscrow(native, escrow)
+	return nil
+}
+
+// refundRenewEscrow returns the rest balance of the escrow to its owner and deletes it
+func refundRenewEscrow(native *native.NativeService, owner common.Address, fileHash []byte) error {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	escrow := getRenewEscrow(native, owner, fileHash)
+	if escrow == nil {
+		return nil
+	}
+	if escrow.Balance > 0 {
+		err := appCallTransfer(native, utils.OngContractAddress, contract, escrow.Owner, escrow.Balance)
+		if err != nil {
+			return err
+		}
+	}
+	delRenewEscrow(native, owner, fileHash)
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenewEscrow(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	owner, reader := sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+	expireTime := uint64(simStartTime + simExpireAfter)
+
+	sim.registerNode()
+	fileProfit := uint64(simFileBlocks * simBlockSize * DefaultGasPerKbForSaveWithFile)
+	sim.storeFiles(FileInfoList{FilesI: []FileInfo{{
+		FileHash:       simPaidFile,
+		FileOwner:      owner,
+		FileBlockCount: simFileBlocks,
+		RealFileSize:   simFileBlocks * simBlockSize,
+		CopyNumber:     1,
+		PdpInterval:    simPdpInterval,
+		TimeExpired:    expireTime,
+		StorageType:    FileStorageTypeUseFile,
+		PdpParam:       []byte("pdp param"),
+		BlockSize:      simBlockSize,
+	}}}, (simExpireAfter/simPdpInterval+1)*fileProfit)
+	sim.prove(simPaidFile, uint64(chain.Height))
+
+	//every renewal extends the file by a renew window
+	renewFee := DefaultRenewWindow / simPdpInterval * fileProfit
+	newEscrow := func(renewPeriod uint64, balance uint64) []byte {
+		escrow := RenewEscrow{Owner: owner, FileHash: simPaidFile, RenewPeriod: renewPeriod,
+			MaxPrice: renewFee, Balance: balance}
+		sink := common.NewZeroCopySink(nil)
+		escrow.Serialization(sink)
+		return wrapVarBytes(sink.Bytes())
+	}
+	setEscrow := func(balance uint64) {
+		sim.transfer(owner, contract, balance)
+		sim.invoke(FS_SET_RENEW_ESCROW, newEscrow(DefaultRenewWindow, balance), owner)
+	}
+	triggerRenew := func(count int) []byte {
+		targetList := RenewTargetList{Caller: reader}
+		for i := 0; i < count; i++ {
+			targetList.Targets = append(targetList.Targets, RenewTarget{Owner: owner, FileHash: simPaidFile})
+		}
+		sink := common.NewZeroCopySink(nil)
+		targetList.Serialization(sink)
+		return wrapVarBytes(sink.Bytes())
+	}
+	escrow := func() *RenewEscrow {
+		var escrow *RenewEscrow
+		chain.Read(contract, func(native *native.NativeService) {
+			escrow = getRenewEscrow(native, owner, simPaidFile)
+		})
+		return escrow
+	}
+	timeExpired := func() uint64 {
+		var timeExpired uint64
+		chain.Read(contract, func(native *native.NativeService) {
+			timeExpired = getFileInfoByHash(native, simPaidFile).TimeExpired
+		})
+		return timeExpired
+	}
+	lowEvents := func() int {
+		var count int
+		for _, notify := range chain.Notifications {
+			if states, ok := notify.States.([]interface{}); ok && states[0] == EVENT_RENEW_ESCROW_LOW {
+				count++
+			}
+		}
+		return count
+	}
+
+	//a renewal has to take the file out of the renew window
+	sim.invokeFail(FS_SET_RENEW_ESCROW, newEscrow(DefaultRenewWindow-1, 0), owner)
+
+	//an escrow paying more than DefaultRenewLowCount renewals is not low
+	lowCount := DefaultRenewLowCount * (renewFee + DefaultRenewCallerReward)
+	chain.ClearNotifications()
+	setEscrow(lowCount + renewFee)
+	assert.Equal(t, 0, lowEvents())
+
+	//a triggered renewal pays the caller once, however often the target is listed, the escrow is reported
+	//once it goes low
+	sim.transfer(contract, reader, DefaultRenewCallerReward)
+	sim.invoke(FS_TRIGGER_RENEW, triggerRenew(3), reader)
+	assert.Equal(t, expireTime+DefaultRenewWindow, timeExpired())
+	assert.Equal(t, lowCount+renewFee-renewFee-DefaultRenewCallerReward, escrow().Balance)
+	assert.Equal(t, 1, lowEvents())
+
+	//the renewed file is out of the renew window, triggering again pays nothing
+	sim.invoke(FS_TRIGGER_RENEW, triggerRenew(1), reader)
+	assert.Equal(t, expireTime+DefaultRenewWindow, timeExpired())
+
+	//a prove in the renew window renews the file without a caller reward, the escrow is not reported again
+	chain.AddTime(simExpireAfter)
+	sim.proveRound(simPaidFile)
+	assert.Equal(t, expireTime+2*DefaultRenewWindow, timeExpired())
+	assert.Equal(t, renewFee+DefaultRenewCallerReward, escrow().Balance)
+	assert.Equal(t, 1, lowEvents())
+
+	chain.AddTime(DefaultRenewWindow)
+	sim.transfer(contract, reader, DefaultRenewCallerReward)
+	sim.invoke(FS_TRIGGER_RENEW, triggerRenew(1), reader)
+	assert.Equal(t, expireTime+3*DefaultRenewWindow, timeExpired())
+	assert.Equal(t, uint64(0), escrow().Balance)
+
+	//an empty escrow renews nothing, neither the trigger nor the proves report it again
+	chain.AddTime(DefaultRenewWindow)
+	sim.invoke(FS_TRIGGER_RENEW, triggerRenew(1), reader)
+	sim.proveRound(simPaidFile)
+	sim.proveRound(simPaidFile)
+	assert.Equal(t, expireTime+3*DefaultRenewWindow, timeExpired())
+	assert.Equal(t, 1, lowEvents())
+
+	//a top up which leaves the escrow low is not reported either
+	setEscrow(renewFee)
+	assert.Equal(t, 1, lowEvents())
+
+	//the cancel refunds the rest of the escrow to the owner
+	cancel := RenewTarget{Owner: owner, FileHash: simPaidFile}
+	sink := common.NewZeroCopySink(nil)
+	cancel.Serialization(sink)
+	sim.invokeFail(FS_CANCEL_RENEW_ESCROW, sink.Bytes(), reader)
+	sim.transfer(contract, owner, renewFee)
+	sim.invoke(FS_CANCEL_RENEW_ESCROW, sink.Bytes(), owner)
+	assert.Nil(t, escrow())
+	sim.invokeFail(FS_CANCEL_RENEW_ESCROW, sink.Bytes(), owner)
+
+	//a new escrow which is low already is reported when it is set
+	setEscrow(renewFee)
+	assert.Equal(t, 2, lowEvents())
+}
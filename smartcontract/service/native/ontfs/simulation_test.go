@@ -0,0 +1,1097 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/global_params"
+	"github.com/ontio/ontology/smartcontract/service/native/ong"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	simStartTime    = 1000000
+	simPdpInterval  = 600
+	simExpireAfter  = 3 * simPdpInterval
+	simInitBalance  = 10000000000
+	simStubProve    = "stub prove"
+	simNodeVolume   = DefaultNodeMinVolume
+	simSpaceVolume  = 8192
+	simBlockSize    = DefaultPerBlockSize
+	simSpaceBlocks  = 16
+	simFileBlocks   = 8
+	simReadBlocks   = 8
+	simSettledBlock = 4
+)
+
+var (
+	simSpaceFile = testFileCid("space file")
+	simPaidFile  = testFileCid("paid file")
+)
+
+// fsSimulation drives the ontfs contract on a test chain and checks the ong accounts after every step
+type fsSimulation struct {
+	t        *testing.T
+	chain    *testsuite.Chain
+	node     *account.Account
+	owner    *account.Account
+	reader   *account.Account
+	balances map[common.Address]uint64
+}
+
+func newFsSimulation(t *testing.T) *fsSimulation {
+	InitFs()
+	ong.InitOng()
+
+	chain, err := testsuite.NewChain(100, simStartTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPdpProve = stubPdpProve
+	t.Cleanup(func() { checkPdpProve = CheckPdpProve })
+	sim := &fsSimulation{
+		t:        t,
+		chain:    chain,
+		node:     account.NewAccount(""),
+		owner:    account.NewAccount(""),
+		reader:   account.NewAccount(""),
+		balances: make(map[common.Address]uint64),
+	}
+	for _, acc := range []*account.Account{sim.node, sim.owner, sim.reader} {
+		chain.SetBalance(utils.OngContractAddress, acc.Address, simInitBalance)
+		sim.balances[acc.Address] = simInitBalance
+	}
+	sim.balances[utils.OntFSContractAddress] = 0
+	return sim
+}
+
+func (this *fsSimulation) invoke(method string, input []byte, signer common.Address) {
+	ret, err := this.chain.Invoke(utils.OntFSContractAddress, method, input, signer)
+	if err != nil {
+		this.t.Fatalf("%s failed: %s", method, err)
+	}
+	if !bytes.Equal(ret, utils.BYTE_TRUE) {
+		this.t.Fatalf("%s returns %x", method, ret)
+	}
+	this.checkInvariants()
+}
+
+func (this *fsSimulation) invokeFail(method string, input []byte, signer common.Address) {
+	_, err := this.chain.Invoke(utils.OntFSContractAddress, method, input, signer)
+	assert.Error(this.t, err, method)
+	this.checkInvariants()
+}
+
+// transfer records an expected ong movement, checked by the next checkInvariants
+func (this *fsSimulation) transfer(from common.Address, to common.Address, amount uint64) {
+	this.balances[from] -= amount
+	this.balances[to] += amount
+}
+
+func (this *fsSimulation) checkInvariants() {
+	for addr, expected := range this.balances {
+		assert.Equal(this.t, expected, this.chain.Balance(utils.OngContractAddress, addr), addr.ToBase58())
+	}
+
+	this.chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		report, err := auditFs(native)
+		if err != nil {
+			this.t.Fatal(err)
+		}
+		assert.Empty(this.t, report.Mismatches)
+		assert.Equal(this.t, report.Liabilities, report.ContractBalance)
+	})
+}
+
+func (this *fsSimulation) nodeInfo() *FsNodeInfo {
+	var nodeInfo *FsNodeInfo
+	this.chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		nodeInfo = getNodeInfo(native, this.node.Address)
+	})
+	return nodeInfo
+}
+
+func (this *fsSimulation) pdpRecord(fileHash []byte) *PdpRecord {
+	var pdpRecord *PdpRecord
+	this.chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		pdpRecord = getPdpRecord(native, fileHash, this.owner.Address, this.node.Address)
+	})
+	return pdpRecord
+}
+
+func (this *fsSimulation) prove(fileHash []byte, challengeHeight uint64) {
+	pdpData := PdpData{
+		NodeAddr:        this.node.Address,
+		FileHash:        fileHash,
+		ProveData:       []byte(simStubProve),
+		ChallengeHeight: challengeHeight,
+	}
+	sink := common.NewZeroCopySink(nil)
+	pdpData.Serialization(sink)
+	this.invoke(FS_FILE_PROVE, sink.Bytes(), this.node.Address)
+}
+
+// proveRound waits for the challenge of the file and proves it in the next pdp window
+func (this *fsSimulation) proveRound(fileHash []byte) {
+	pdpRecord := this.pdpRecord(fileHash)
+	if uint64(this.chain.Height) < pdpRecord.NextHeight {
+		this.chain.AddBlocks(uint32(pdpRecord.NextHeight) - this.chain.Height)
+	}
+	var challenge uint64
+	this.chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		var err error
+		if challenge, err = challengeHeight(native, pdpRecord); err != nil {
+			this.t.Fatal(err)
+		}
+	})
+	if uint64(this.chain.Height) < challenge {
+		this.chain.AddBlocks(uint32(challenge) - this.chain.Height)
+	}
+	if uint64(this.chain.Time) <= pdpRecord.LastPdpTime {
+		this.chain.AddTime(uint32(pdpRecord.LastPdpTime) - this.chain.Time + 1)
+	}
+	this.prove(fileHash, challenge)
+}
+
+func (this *fsSimulation) registerNode() {
+	nodeInfo := FsNodeInfo{
+		Volume:         simNodeVolume,
+		ServiceTime:    simStartTime + 2*simExpireAfter,
+		MinPdpInterval: simPdpInterval,
+		NodeAddr:       this.node.Address,
+		NodeNetAddr:    []byte("tcp://127.0.0.1:20338"),
+	}
+	sink := common.NewZeroCopySink(nil)
+	nodeInfo.Serialization(sink)
+	this.transfer(this.node.Address, utils.OntFSContractAddress, simNodeVolume*DefaultNodePerKbPledge)
+	this.invoke(FS_NODE_REGISTER, sink.Bytes(), this.node.Address)
+}
+
+func (this *fsSimulation) storeFiles(fileInfoList FileInfoList, payAmount uint64) {
+	sink := common.NewZeroCopySink(nil)
+	fileInfoList.Serialization(sink)
+	this.transfer(this.owner.Address, utils.OntFSContractAddress, payAmount)
+	this.invoke(FS_STORE_FILES, wrapVarBytes(sink.Bytes()), this.owner.Address)
+}
+
+func (this *fsSimulation) sign(data []byte) ([]byte, []byte) {
+	sig, err := signature.Sign(this.reader, data)
+	if err != nil {
+		this.t.Fatal(err)
+	}
+	return sig, keypair.SerializePublicKey(this.reader.PublicKey)
+}
+
+func (this *fsSimulation) settleSlice(sliceId uint64, pledgeHeight uint64) []byte {
+	slice := FileReadSettleSlice{
+		FileHash:     simPaidFile,
+		PayFrom:      this.reader.Address,
+		PayTo:        this.node.Address,
+		SliceId:      sliceId,
+		PledgeHeight: pledgeHeight,
+	}
+	sink := common.NewZeroCopySink(nil)
+	slice.Serialization(sink)
+	slice.Sig, slice.PubKey = this.sign(sink.Bytes())
+
+	sink = common.NewZeroCopySink(nil)
+	slice.Serialization(sink)
+	return sink.Bytes()
+}
+
+func (this *fsSimulation) sessionSettleSlice(sessionId []byte, fileHash []byte, sliceId uint64,
+	pledgeHeight uint64) []byte {
+	slice := ReadSessionSettleSlice{
+		SessionId:    sessionId,
+		FileHash:     fileHash,
+		PayFrom:      this.reader.Address,
+		PayTo:        this.node.Address,
+		SliceId:      sliceId,
+		PledgeHeight: pledgeHeight,
+	}
+	sink := common.NewZeroCopySink(nil)
+	slice.Serialization(sink)
+	slice.Sig, slice.PubKey = this.sign(sink.Bytes())
+
+	sink = common.NewZeroCopySink(nil)
+	slice.Serialization(sink)
+	return sink.Bytes()
+}
+
+func stubPdpProve(nodeAddr common.Address, challengeSeed []byte, fileBlockCount uint64, pdpParamData []byte,
+	proveData []byte) error {
+	if string(proveData) != simStubProve {
+		return errors.New("stub prove mismatch")
+	}
+	return nil
+}
+
+func wrapVarBytes(data []byte) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(data)
+	return sink.Bytes()
+}
+
+func encodeAddress(addr common.Address) []byte {
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeAddress(sink, addr)
+	return sink.Bytes()
+}
+
+func TestSimulation_FileLifecycle(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	node, owner, reader := sim.node.Address, sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+	expireTime := uint64(simStartTime + simExpireAfter)
+
+	sim.registerNode()
+
+	//create space, one pdp of every interval is paid, including the first
+	spaceInfo := SpaceInfo{
+		SpaceOwner:  owner,
+		Volume:      simSpaceVolume,
+		CopyNumber:  1,
+		PdpInterval: simPdpInterval,
+		TimeExpired: expireTime,
+	}
+	sink := common.NewZeroCopySink(nil)
+	spaceInfo.Serialization(sink)
+	spacePayAmount := uint64(simExpireAfter/simPdpInterval+1) * simSpaceVolume * DefaultGasPerKbForSaveWithSpace
+	sim.transfer(owner, contract, spacePayAmount)
+	sim.invoke(FS_CREATE_SPACE, wrapVarBytes(sink.Bytes()), owner)
+
+	//store a file in the space and a file paid by itself
+	fileInfoList := FileInfoList{FilesI: []FileInfo{
+		{
+			FileHash:       simSpaceFile,
+			FileOwner:      owner,
+			FileBlockCount: simSpaceBlocks,
+			RealFileSize:   simSpaceBlocks * simBlockSize,
+			CopyNumber:     1,
+			PdpInterval:    simPdpInterval,
+			TimeExpired:    expireTime,
+			StorageType:    FileStorageTypeUseSpace,
+			PdpParam:       []byte("pdp param"),
+			BlockSize:      simBlockSize,
+		},
+		{
+			FileHash:       simPaidFile,
+			FileOwner:      owner,
+			FileBlockCount: simFileBlocks,
+			RealFileSize:   simFileBlocks * simBlockSize,
+			CopyNumber:     1,
+			FirstPdp:       true,
+			PdpInterval:    simPdpInterval,
+			TimeExpired:    expireTime,
+			StorageType:    FileStorageTypeUseFile,
+			PdpParam:       []byte("pdp param"),
+			BlockSize:      simBlockSize,
+		},
+	}}
+	filePayAmount := uint64(simExpireAfter/simPdpInterval+1) * simFileBlocks * simBlockSize *
+		DefaultGasPerKbForSaveWithFile
+	sim.storeFiles(fileInfoList, filePayAmount)
+
+	//the FileHash of a stored file can't be taken by another owner with other pdp parameters
+	taken := fileInfoList.FilesI[1]
+	taken.FileOwner = reader
+	taken.PdpParam = []byte("other pdp param")
+	sink = common.NewZeroCopySink(nil)
+	(&FileInfoList{FilesI: []FileInfo{taken}}).Serialization(sink)
+	sim.invoke(FS_STORE_FILES, wrapVarBytes(sink.Bytes()), reader)
+	chain.Read(contract, func(native *native.NativeService) {
+		fileOwner, _ := getFileOwner(native, simPaidFile)
+		assert.Equal(t, owner, fileOwner)
+	})
+	sink = common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(simPaidFile)
+	ret, err := chain.Invoke(contract, FS_GET_FILE_CID, sink.Bytes())
+	assert.Nil(t, err)
+	retInfo := DecRet(ret)
+	assert.True(t, retInfo.Ret)
+	var cidInfo FileCidInfo
+	assert.Nil(t, cidInfo.Deserialization(common.NewZeroCopySource(retInfo.Info)))
+	assert.Equal(t, uint64(CidHashSha2_256), cidInfo.Cid.HashAlg)
+
+	//the first prove takes the files and earns nothing, the stub prove of the paid file is checked
+	sim.invokeFail(FS_FILE_PROVE, func() []byte {
+		pdpData := PdpData{NodeAddr: node, FileHash: simPaidFile, ProveData: []byte("bad prove"),
+			ChallengeHeight: uint64(chain.Height)}
+		sink := common.NewZeroCopySink(nil)
+		pdpData.Serialization(sink)
+		return sink.Bytes()
+	}(), node)
+	sim.prove(simSpaceFile, uint64(chain.Height))
+	sim.prove(simPaidFile, uint64(chain.Height))
+	assert.Equal(t, uint64(simNodeVolume-(simSpaceBlocks+simFileBlocks)*simBlockSize), sim.nodeInfo().RestVol)
+	assert.Equal(t, uint64(0), sim.nodeInfo().Profit)
+
+	//a prove is rejected before the next challenge
+	sim.invokeFail(FS_FILE_PROVE, func() []byte {
+		pdpData := PdpData{NodeAddr: node, FileHash: simSpaceFile, ProveData: []byte(simStubProve),
+			ChallengeHeight: sim.pdpRecord(simSpaceFile).NextHeight}
+		sink := common.NewZeroCopySink(nil)
+		pdpData.Serialization(sink)
+		return sink.Bytes()
+	}(), node)
+
+	spaceProfit := uint64(simSpaceBlocks * simBlockSize * DefaultGasPerKbForSaveWithSpace)
+	fileProfit := filePayAmount / (simExpireAfter/simPdpInterval + 1)
+	var profit uint64
+	for round := 0; round < 2; round++ {
+		sim.proveRound(simSpaceFile)
+		sim.proveRound(simPaidFile)
+		profit += spaceProfit + fileProfit
+		assert.Equal(t, profit, sim.nodeInfo().Profit)
+		assert.Equal(t, profit/2, sim.nodeInfo().LockedProfit)
+	}
+
+	//read pledge and settle a slice signed by the reader
+	readPledge := ReadPledge{
+		FileHash:   simPaidFile,
+		Downloader: reader,
+		ReadPlans:  []ReadPlan{{NodeAddr: node, MaxReadBlockNum: simReadBlocks}},
+	}
+	sink = common.NewZeroCopySink(nil)
+	readPledge.Serialization(sink)
+	readPledgeFee := uint64(simReadBlocks * simBlockSize * DefaultGasPerKbForRead)
+	sim.transfer(reader, contract, readPledgeFee)
+	sim.invoke(FS_READ_FILE_PLEDGE, wrapVarBytes(sink.Bytes()), reader)
+	pledgeHeight := uint64(chain.Height)
+	pledgeExpireHeight := pledgeHeight + simFileBlocks + 30
+
+	sim.invoke(FS_READ_FILE_SETTLE, sim.settleSlice(simSettledBlock, pledgeHeight), node)
+	sim.invokeFail(FS_READ_FILE_SETTLE, sim.settleSlice(simSettledBlock, pledgeHeight), node)
+	readFee := uint64(simSettledBlock * simBlockSize * DefaultGasPerKbForRead)
+	profit += readFee
+	assert.Equal(t, profit, sim.nodeInfo().Profit)
+
+	//the reader gets the rest of the pledge back once the pledge expires
+	getPledge := GetReadPledge{FileHash: simPaidFile, Downloader: reader}
+	sink = common.NewZeroCopySink(nil)
+	getPledge.Serialization(sink)
+	sim.invokeFail(FS_CANCEL_FILE_READ, sink.Bytes(), reader)
+
+	//the files expire, the last prove is paid and settles the pdp records
+	chain.AddTime(simExpireAfter)
+	sim.proveRound(simSpaceFile)
+	sim.proveRound(simPaidFile)
+	profit += spaceProfit + fileProfit
+	assert.Equal(t, profit, sim.nodeInfo().Profit)
+	assert.True(t, sim.pdpRecord(simSpaceFile).SettleFlag)
+	assert.True(t, sim.pdpRecord(simPaidFile).SettleFlag)
+	assert.Equal(t, uint64(simNodeVolume), sim.nodeInfo().RestVol)
+
+	if uint64(chain.Height) < pledgeExpireHeight {
+		chain.AddBlocks(uint32(pledgeExpireHeight) - chain.Height)
+	}
+	//a cancel by the reader leaves the reputation of the node alone
+	reputation := sim.nodeInfo().Reputation
+	sim.transfer(contract, reader, readPledgeFee-readFee)
+	sim.invoke(FS_CANCEL_FILE_READ, sink.Bytes(), reader)
+	assert.Equal(t, reputation, sim.nodeInfo().Reputation)
+
+	//delete the files and the space, the unused payment goes back to the owner
+	fileDelList := FileDelList{FilesDel: []FileDel{{FileHash: simSpaceFile}, {FileHash: simPaidFile}}}
+	sink = common.NewZeroCopySink(nil)
+	fileDelList.Serialization(sink)
+	sim.invokeFail(FS_DELETE_SPACE, encodeAddress(owner), owner)
+	sim.transfer(contract, owner, filePayAmount-3*fileProfit)
+	sim.invoke(FS_DELETE_FILES, wrapVarBytes(sink.Bytes()), owner)
+	sim.transfer(contract, owner, spacePayAmount-3*spaceProfit)
+	sim.invoke(FS_DELETE_SPACE, encodeAddress(owner), owner)
+
+	//the node withdraws the vested profit now and the locked profit after the lock period
+	sim.transfer(contract, node, profit/2)
+	sim.invoke(FS_NODE_WITH_DRAW_PROFIT, encodeAddress(node), node)
+	sim.invokeFail(FS_NODE_CANCEL, encodeAddress(node), node)
+
+	chain.AddTime(DefaultProfitLockPeriod + DefaultProfitLockBucket)
+	sim.transfer(contract, node, profit-profit/2)
+	sim.invoke(FS_NODE_WITH_DRAW_PROFIT, encodeAddress(node), node)
+	sim.transfer(contract, node, simNodeVolume*DefaultNodePerKbPledge)
+	sim.invoke(FS_NODE_CANCEL, encodeAddress(node), node)
+
+	assert.Equal(t, uint64(0), sim.balances[contract])
+	assert.Equal(t, uint64(simInitBalance+profit), sim.balances[node])
+	assert.Equal(t, uint64(simInitBalance-readFee), sim.balances[reader])
+}
+
+func TestSimulation_ReadSession(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	node, owner, reader := sim.node.Address, sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+	sessionId := []byte("dataset-session")
+	expireTime := uint64(simStartTime + simExpireAfter)
+
+	sim.registerNode()
+	var fileInfoList FileInfoList
+	for _, fileHash := range [][]byte{simSpaceFile, simPaidFile} {
+		fileInfoList.FilesI = append(fileInfoList.FilesI, FileInfo{
+			FileHash:       fileHash,
+			FileOwner:      owner,
+			FileBlockCount: simFileBlocks,
+			RealFileSize:   simFileBlocks * simBlockSize,
+			CopyNumber:     1,
+			PdpInterval:    simPdpInterval,
+			TimeExpired:    expireTime,
+			StorageType:    FileStorageTypeUseFile,
+			PdpParam:       []byte("pdp param"),
+			BlockSize:      simBlockSize,
+		})
+	}
+	filePayAmount := uint64(simExpireAfter/simPdpInterval+1) * simFileBlocks * simBlockSize *
+		DefaultGasPerKbForSaveWithFile
+	sim.storeFiles(fileInfoList, 2*filePayAmount)
+
+	pledge := func(blocks uint64, fileHashes ...[]byte) []byte {
+		session := ReadSession{SessionId: sessionId, Downloader: reader}
+		for _, fileHash := range fileHashes {
+			session.Files = append(session.Files, ReadSessionFile{
+				FileHash:  fileHash,
+				ReadPlans: []ReadPlan{{NodeAddr: node, MaxReadBlockNum: blocks}},
+			})
+		}
+		sink := common.NewZeroCopySink(nil)
+		session.Serialization(sink)
+		return wrapVarBytes(sink.Bytes())
+	}
+	blockFee := uint64(simBlockSize * DefaultGasPerKbForRead)
+
+	//one pledge covers both files with a shared budget
+	sim.invokeFail(FS_READ_SESSION_PLEDGE, pledge(simReadBlocks, simPaidFile, simPaidFile), reader)
+	sim.invokeFail(FS_READ_SESSION_PLEDGE, pledge(simReadBlocks, []byte("QmUnknownFile")), reader)
+	sim.transfer(reader, contract, 2*simReadBlocks*blockFee)
+	sim.invoke(FS_READ_SESSION_PLEDGE, pledge(simReadBlocks, simSpaceFile, simPaidFile), reader)
+	pledgeHeight := uint64(chain.Height)
+
+	//slices name the file, every file keeps the read plans of its nodes
+	sim.invoke(FS_READ_SESSION_SETTLE, sim.sessionSettleSlice(sessionId, simSpaceFile, simReadBlocks, pledgeHeight), node)
+	sim.invoke(FS_READ_SESSION_SETTLE, sim.sessionSettleSlice(sessionId, simPaidFile, 2, pledgeHeight), node)
+	sim.invokeFail(FS_READ_SESSION_SETTLE, sim.sessionSettleSlice(sessionId, simPaidFile, 2, pledgeHeight), node)
+	sim.invokeFail(FS_READ_SESSION_SETTLE, sim.sessionSettleSlice(sessionId, simPaidFile, 3, pledgeHeight+1), node)
+	sim.invokeFail(FS_READ_SESSION_SETTLE,
+		sim.sessionSettleSlice([]byte("other-session"), simPaidFile, 3, pledgeHeight), node)
+	assert.Equal(t, (simReadBlocks+2)*blockFee, sim.nodeInfo().Profit)
+
+	//a top up adds blocks to the plan and keeps the blocks already read
+	sim.transfer(reader, contract, simReadBlocks*blockFee)
+	sim.invoke(FS_READ_SESSION_PLEDGE, pledge(simReadBlocks, simPaidFile), reader)
+	sim.invoke(FS_READ_SESSION_SETTLE,
+		sim.sessionSettleSlice(sessionId, simPaidFile, 2*simReadBlocks-2, pledgeHeight), node)
+	assert.Equal(t, (3*simReadBlocks-2)*blockFee, sim.nodeInfo().Profit)
+
+	var session *ReadSession
+	chain.Read(contract, func(native *native.NativeService) {
+		session, _ = getReadSession(native, reader, sessionId)
+	})
+	assert.Equal(t, 2, len(session.Files))
+	assert.Equal(t, uint64(2*simReadBlocks-2), session.getFile(simPaidFile).ReadPlans[0].HaveReadBlockNum)
+	assert.Equal(t, 2*blockFee, session.RestMoney)
+
+	//a single cancel releases the session once it expires
+	getSession := GetReadSession{SessionId: sessionId, Downloader: reader}
+	sink := common.NewZeroCopySink(nil)
+	getSession.Serialization(sink)
+	sim.invokeFail(FS_CANCEL_READ_SESSION, sink.Bytes(), reader)
+	chain.AddBlocks(uint32(session.ExpireHeight) - chain.Height)
+	sim.transfer(contract, reader, 2*blockFee)
+	sim.invoke(FS_CANCEL_READ_SESSION, sink.Bytes(), reader)
+	sim.invokeFail(FS_CANCEL_READ_SESSION, sink.Bytes(), reader)
+
+	assert.Equal(t, uint64(simInitBalance-(3*simReadBlocks-2)*blockFee), sim.balances[reader])
+}
+
+func TestSimulation_SpaceFunding(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	owner, reader := sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+	expireTime := uint64(simStartTime + simExpireAfter)
+
+	spaceInfo := SpaceInfo{
+		SpaceOwner:  owner,
+		Volume:      simSpaceVolume,
+		CopyNumber:  1,
+		PdpInterval: simPdpInterval,
+		TimeExpired: expireTime,
+	}
+	sink := common.NewZeroCopySink(nil)
+	spaceInfo.Serialization(sink)
+	spacePayAmount := uint64(simExpireAfter/simPdpInterval+1) * simSpaceVolume * DefaultGasPerKbForSaveWithSpace
+	sim.transfer(owner, contract, spacePayAmount)
+	sim.invoke(FS_CREATE_SPACE, wrapVarBytes(sink.Bytes()), owner)
+
+	spaceUpdate := func(payer common.Address, newVolume uint64) []byte {
+		sink := common.NewZeroCopySink(nil)
+		update := SpaceUpdate{SpaceOwner: owner, Payer: payer, NewVolume: newVolume}
+		update.Serialization(sink)
+		return wrapVarBytes(sink.Bytes())
+	}
+	checkFunding := func(expected ...SpaceFunder) {
+		chain.Read(contract, func(native *native.NativeService) {
+			funding, err := getSpaceFunding(native, getSpaceInfoFromDb(native, owner))
+			assert.Nil(t, err)
+			assert.Equal(t, expected, funding.Funders)
+		})
+	}
+
+	//anyone may top up the space, the payer joins its funding ledger
+	sim.transfer(reader, contract, spacePayAmount)
+	sim.invoke(FS_TOP_UP_SPACE, spaceUpdate(reader, 2*simSpaceVolume), reader)
+	checkFunding(SpaceFunder{owner, spacePayAmount}, SpaceFunder{reader, spacePayAmount})
+
+	//only the owner may reduce the space
+	sim.invokeFail(FS_TOP_UP_SPACE, spaceUpdate(reader, simSpaceVolume), reader)
+	sim.invokeFail(FS_UPDATE_SPACE, spaceUpdate(reader, simSpaceVolume), reader)
+
+	//a reduction is refunded to the funders in proportion, whoever the payer is
+	sim.transfer(contract, owner, spacePayAmount/2)
+	sim.transfer(contract, reader, spacePayAmount/2)
+	sim.invoke(FS_UPDATE_SPACE, spaceUpdate(reader, simSpaceVolume), owner)
+	checkFunding(SpaceFunder{owner, spacePayAmount / 2}, SpaceFunder{reader, spacePayAmount / 2})
+
+	sim.transfer(contract, owner, spacePayAmount/2)
+	sim.transfer(contract, reader, spacePayAmount/2)
+	sim.invoke(FS_DELETE_SPACE, encodeAddress(owner), owner)
+	assert.Equal(t, uint64(simInitBalance), sim.balances[owner])
+	assert.Equal(t, uint64(simInitBalance), sim.balances[reader])
+}
+
+func TestSimulation_ProtocolFee(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	owner, reader := sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+	admin := account.NewAccount("").Address
+	treasury := account.NewAccount("").Address
+	sim.balances[treasury] = 0
+	chain.SetStorage(global_params.GenerateOperatorKey(utils.ParamContractAddress), encodeAddress(admin))
+
+	//only the governance admin sets the fee config
+	feeConfig := FsFeeConfig{ProtocolFeeRate: 1000, InsuranceRate: 50, Treasury: treasury}
+	sink := common.NewZeroCopySink(nil)
+	feeConfig.Serialization(sink)
+	sim.invokeFail(FS_SET_FEE_CONFIG, sink.Bytes(), owner)
+	sim.invoke(FS_SET_FEE_CONFIG, sink.Bytes(), admin)
+
+	sim.registerNode()
+	lifeWindows := uint64(10)
+	fileInfoList := FileInfoList{FilesI: []FileInfo{{
+		FileHash:       simPaidFile,
+		FileOwner:      owner,
+		FileBlockCount: simFileBlocks,
+		RealFileSize:   simFileBlocks * simBlockSize,
+		CopyNumber:     1,
+		PdpInterval:    simPdpInterval,
+		TimeExpired:    simStartTime + lifeWindows*simPdpInterval,
+		StorageType:    FileStorageTypeUseFile,
+		PdpParam:       []byte("pdp param"),
+		BlockSize:      simBlockSize,
+	}}}
+	filePayAmount := (lifeWindows + 1) * simFileBlocks * simBlockSize * DefaultGasPerKbForSaveWithFile
+	sim.storeFiles(fileInfoList, filePayAmount)
+
+	//the protocol fee is taken from every pdp payment
+	sim.prove(simPaidFile, uint64(chain.Height))
+	sim.proveRound(simPaidFile)
+	fileProfit := filePayAmount / (lifeWindows + 1)
+	fee := fileProfit * feeConfig.ProtocolFeeRate / DefaultProtocolFeeRateBase
+	insurance := fee * feeConfig.InsuranceRate / 100
+	assert.Equal(t, fileProfit-fee, sim.nodeInfo().Profit)
+
+	feePool := func() *FsFeePool {
+		ret, err := chain.Invoke(contract, FS_GET_FEE_POOL, nil)
+		assert.Nil(t, err)
+		retInfo := DecRet(ret)
+		assert.True(t, retInfo.Ret)
+		var pool FsFeePool
+		assert.Nil(t, pool.Deserialization(common.NewZeroCopySource(retInfo.Info)))
+		return &pool
+	}
+	assert.Equal(t, FsFeePool{TreasuryBalance: fee - insurance, InsuranceBalance: insurance}, *feePool())
+
+	//only the treasury withdraws its part
+	sim.invokeFail(FS_WITHDRAW_TREASURY, nil, admin)
+	sim.transfer(contract, treasury, fee-insurance)
+	sim.invoke(FS_WITHDRAW_TREASURY, nil, treasury)
+	sim.invokeFail(FS_WITHDRAW_TREASURY, nil, treasury)
+
+	//the file is not lost while the node proves it
+	claim := wrapVarBytes(simPaidFile)
+	sim.invokeFail(FS_CLAIM_FILE_LOSS, claim, owner)
+
+	//the node stops proving, the owner is paid back the file cost and the rest of the payment
+	chain.AddTime(uint32((DefaultFileLostWindows + 1) * simPdpInterval))
+	sim.invokeFail(FS_CLAIM_FILE_LOSS, claim, reader)
+	slash := uint64(simFileBlocks * simBlockSize * DefaultNodePerKbPledge)
+	nodeInfo := sim.nodeInfo()
+	//the locked profit is slashed first, the pledge pays the rest
+	slashLocked := nodeInfo.LockedProfit
+	assert.True(t, slashLocked > 0 && slashLocked < slash)
+	//the compensation of the file cost and the refund of the rest make up the whole payment
+	sim.transfer(contract, owner, filePayAmount)
+	sim.invoke(FS_CLAIM_FILE_LOSS, claim, owner)
+	assert.Equal(t, nodeInfo.Pledge-(slash-slashLocked), sim.nodeInfo().Pledge)
+	assert.Equal(t, nodeInfo.Profit-slashLocked, sim.nodeInfo().Profit)
+	assert.Equal(t, uint64(simNodeVolume), sim.nodeInfo().RestVol)
+	assert.Equal(t, FsFeePool{InsuranceBalance: insurance + slash - fileProfit}, *feePool())
+	assert.Nil(t, sim.pdpRecord(simPaidFile))
+	sim.invokeFail(FS_CLAIM_FILE_LOSS, claim, owner)
+
+	//the slashed node is offered no more, until it pledges again for its volume
+	nodeList := func() []FsNodeInfo {
+		sink := common.NewZeroCopySink(nil)
+		utils.EncodeVarUint(sink, 10)
+		ret, err := chain.Invoke(contract, FS_GET_NODE_LIST, sink.Bytes())
+		assert.Nil(t, err)
+		retInfo := DecRet(ret)
+		assert.True(t, retInfo.Ret)
+		var nodesInfoList FsNodeInfoList
+		assert.Nil(t, nodesInfoList.Deserialization(common.NewZeroCopySource(retInfo.Info)))
+		return nodesInfoList.NodesInfo
+	}
+	assert.Empty(t, nodeList())
+	nodeInfo = sim.nodeInfo()
+	sink = common.NewZeroCopySink(nil)
+	nodeInfo.Serialization(sink)
+	sim.transfer(sim.node.Address, contract, slash-slashLocked)
+	sim.invoke(FS_NODE_UPDATE, sink.Bytes(), sim.node.Address)
+	assert.Equal(t, 1, len(nodeList()))
+}
+
+func TestSimulation_NodeAttestation(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	node, owner, reader := sim.node.Address, sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+
+	nodeInfo := FsNodeInfo{
+		Volume:         simNodeVolume,
+		ServiceTime:    simStartTime + simExpireAfter,
+		MinPdpInterval: simPdpInterval,
+		NodeAddr:       node,
+		NodeNetAddr:    []byte("declared by the endpoints"),
+		Metadata: NodeMetadata{
+			Endpoints: []NodeEndpoint{{Protocol: []byte("tcp"), Addr: []byte("127.0.0.1:20338")}},
+			Region:    []byte("SG"),
+			Bandwidth: 1024,
+			Version:   []byte("v1.0.0"),
+		},
+	}
+	register := func(nodeInfo FsNodeInfo) []byte {
+		sink := common.NewZeroCopySink(nil)
+		nodeInfo.Serialization(sink)
+		return sink.Bytes()
+	}
+	bad := nodeInfo
+	bad.Metadata.Region = []byte("Singapore")
+	sim.invokeFail(FS_NODE_REGISTER, register(bad), node)
+	sim.transfer(node, contract, simNodeVolume*DefaultNodePerKbPledge)
+	sim.invoke(FS_NODE_REGISTER, register(nodeInfo), node)
+	assert.Equal(t, []byte("tcp://127.0.0.1:20338"), sim.nodeInfo().NodeNetAddr)
+
+	nodeList := func(filter *NodeFilter) []FsNodeInfo {
+		sink := common.NewZeroCopySink(nil)
+		utils.EncodeVarUint(sink, 10)
+		if filter != nil {
+			filter.Serialization(sink)
+		}
+		ret, err := chain.Invoke(contract, FS_GET_NODE_LIST, sink.Bytes())
+		assert.Nil(t, err)
+		retInfo := DecRet(ret)
+		assert.True(t, retInfo.Ret)
+		var nodesInfoList FsNodeInfoList
+		assert.Nil(t, nodesInfoList.Deserialization(common.NewZeroCopySource(retInfo.Info)))
+		return nodesInfoList.NodesInfo
+	}
+	assert.Equal(t, 1, len(nodeList(nil)))
+	assert.Equal(t, 1, len(nodeList(&NodeFilter{Protocol: []byte("tcp"), Region: []byte("SG"), LiveOnly: true})))
+	assert.Equal(t, 0, len(nodeList(&NodeFilter{Protocol: []byte("udp")})))
+
+	//only a client which reads a file from the node may attest it
+	attest := func(reachable bool) []byte {
+		attestation := NodeAttestation{
+			NodeAddr:  node,
+			Protocol:  []byte("tcp"),
+			Addr:      []byte("127.0.0.1:20338"),
+			Client:    reader,
+			FileHash:  simPaidFile,
+			Reachable: reachable,
+			Time:      uint64(chain.Time),
+		}
+		sink := common.NewZeroCopySink(nil)
+		attestation.Serialization(sink)
+		attestation.Sig, attestation.PubKey = sim.sign(sink.Bytes())
+
+		sink = common.NewZeroCopySink(nil)
+		attestation.Serialization(sink)
+		return sink.Bytes()
+	}
+	chain.AddTime(10)
+	sim.invokeFail(FS_NODE_ATTEST, attest(false), owner)
+
+	fileInfoList := FileInfoList{FilesI: []FileInfo{{
+		FileHash:       simPaidFile,
+		FileOwner:      owner,
+		FileBlockCount: simFileBlocks,
+		CopyNumber:     1,
+		FirstPdp:       true,
+		PdpInterval:    simPdpInterval,
+		TimeExpired:    uint64(chain.Time) + simExpireAfter,
+		StorageType:    FileStorageTypeUseFile,
+		PdpParam:       []byte("pdp param"),
+		BlockSize:      simBlockSize,
+	}}}
+	sim.storeFiles(fileInfoList, uint64(simExpireAfter/simPdpInterval+1)*simFileBlocks*simBlockSize*
+		DefaultGasPerKbForSaveWithFile)
+	readPledge := ReadPledge{
+		FileHash:   simPaidFile,
+		Downloader: reader,
+		ReadPlans:  []ReadPlan{{NodeAddr: node, MaxReadBlockNum: simReadBlocks}},
+	}
+	sink := common.NewZeroCopySink(nil)
+	readPledge.Serialization(sink)
+	sim.transfer(reader, contract, simReadBlocks*simBlockSize*DefaultGasPerKbForRead)
+	sim.invoke(FS_READ_FILE_PLEDGE, wrapVarBytes(sink.Bytes()), reader)
+
+	//the reader attests the endpoint unreachable, anyone may submit the attestation
+	sim.invoke(FS_NODE_ATTEST, attest(false), owner)
+	assert.True(t, sim.nodeInfo().Metadata.Endpoints[0].Stale)
+	assert.Equal(t, 0, len(nodeList(&NodeFilter{LiveOnly: true})))
+
+	//a tampered attestation is rejected
+	tampered := attest(true)
+	tampered[0] ^= 0x01
+	sim.invokeFail(FS_NODE_ATTEST, tampered, owner)
+
+	chain.AddTime(10)
+	sim.invoke(FS_NODE_ATTEST, attest(true), owner)
+	assert.False(t, sim.nodeInfo().Metadata.Endpoints[0].Stale)
+
+	//an update keeps the status of the endpoints declared before
+	lastSeen := sim.nodeInfo().Metadata.Endpoints[0].LastSeen
+	nodeInfo.Metadata.Endpoints = append(nodeInfo.Metadata.Endpoints,
+		NodeEndpoint{Protocol: []byte("udp"), Addr: []byte("127.0.0.1:20338")})
+	chain.AddTime(10)
+	sim.invoke(FS_NODE_UPDATE, register(nodeInfo), node)
+	assert.Equal(t, lastSeen, sim.nodeInfo().Metadata.Endpoints[0].LastSeen)
+	assert.Equal(t, uint64(chain.Time), sim.nodeInfo().Metadata.Endpoints[1].LastSeen)
+
+	//the endpoints go stale when no client sees them
+	chain.AddTime(DefaultEndpointStaleTime + 1)
+	assert.Equal(t, 0, len(nodeList(&NodeFilter{LiveOnly: true})))
+
+	//the owner of a file the node proves, or a reader of a read session, uses the node too
+	chain.Read(contract, func(native *native.NativeService) {
+		attestation := &NodeAttestation{NodeAddr: node, Client: owner, FileHash: simPaidFile}
+		assert.False(t, attestationRelated(native, attestation))
+		addPdpRecord(native, &PdpRecord{NodeAddr: node, FileHash: simPaidFile, FileOwner: owner})
+		assert.True(t, attestationRelated(native, attestation))
+
+		sessionReader := common.Address{0x09}
+		attestation = &NodeAttestation{NodeAddr: node, Client: sessionReader, FileHash: simPaidFile,
+			SessionId: []byte("session")}
+		assert.False(t, attestationRelated(native, attestation))
+		addReadSession(native, &ReadSession{SessionId: []byte("session"), Downloader: sessionReader,
+			ExpireHeight: uint64(native.Height) + 1, Files: []ReadSessionFile{{FileHash: simPaidFile,
+				ReadPlans: []ReadPlan{{NodeAddr: node, MaxReadBlockNum: simReadBlocks}}}}})
+		assert.True(t, attestationRelated(native, attestation))
+		attestation.NodeAddr = reader
+		assert.False(t, attestationRelated(native, attestation))
+	})
+}
+
+func TestSimulation_Passport(t *testing.T) {
+	ontid.Init()
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	owner, reader := sim.owner, sim.reader
+	contract := utils.OntFSContractAddress
+	fileHash := testFileCid("enveloped file")
+
+	passport := func(signer *account.Account, wallet common.Address, method string, param []byte,
+		delegate []byte, height uint32) []byte {
+		header, err := chain.GetHeaderByHeight(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockHash := header.Hash()
+		passport := Passport{
+			BlockHeight: uint64(height),
+			BlockHash:   blockHash[:],
+			WalletAddr:  wallet,
+			Method:      []byte(method),
+			ParamDigest: PassportParamDigest(param),
+			Delegate:    delegate,
+			PublicKey:   keypair.SerializePublicKey(signer.PublicKey),
+		}
+		passport.Signature, err = signature.Sign(signer, passport.SignData())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sink := common.NewZeroCopySink(nil)
+		passport.Serialization(sink)
+		return sink.Bytes()
+	}
+	check := func(method string, param []byte, passportData []byte) error {
+		var err error
+		chain.Read(contract, func(native *native.NativeService) {
+			var walletAddr common.Address
+			walletAddr, err = CheckPassport(native, method, param, passportData)
+			if err == nil {
+				assert.Equal(t, owner.Address, walletAddr)
+			}
+		})
+		return err
+	}
+
+	//the passport is bound to the method and the parameters it authorizes
+	listing := passport(owner, owner.Address, FS_GET_FILE_LIST, nil, nil, chain.Height)
+	assert.Nil(t, check(FS_GET_FILE_LIST, nil, listing))
+	assert.Error(t, check(FS_GET_KEY_ENVELOPE, nil, listing))
+	envelope := passport(owner, owner.Address, FS_GET_KEY_ENVELOPE, fileHash, nil, chain.Height)
+	assert.Nil(t, check(FS_GET_KEY_ENVELOPE, fileHash, envelope))
+	assert.Error(t, check(FS_GET_KEY_ENVELOPE, simPaidFile, envelope))
+	ret, err := chain.Invoke(contract, FS_GET_FILE_LIST, wrapVarBytes(listing))
+	assert.Nil(t, err)
+	assert.True(t, DecRet(ret).Ret)
+
+	//the block hash is checked against the ledger
+	forged := Passport{}
+	assert.Nil(t, forged.Deserialization(common.NewZeroCopySource(listing)))
+	forged.BlockHeight--
+	sink := common.NewZeroCopySink(nil)
+	forged.Serialization(sink)
+	assert.Error(t, check(FS_GET_FILE_LIST, nil, sink.Bytes()))
+
+	//the validity window is a governance parameter
+	chain.AddBlocks(DefaultPassportExpire + 1)
+	assert.Error(t, check(FS_GET_FILE_LIST, nil, listing))
+	admin := account.NewAccount("").Address
+	chain.SetStorage(global_params.GenerateOperatorKey(utils.ParamContractAddress), encodeAddress(admin))
+	sink = common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, 2*DefaultPassportExpire)
+	sim.invokeFail(FS_SET_PASSPORT_EXPIRE, sink.Bytes(), owner.Address)
+	sim.invoke(FS_SET_PASSPORT_EXPIRE, sink.Bytes(), admin)
+	assert.Nil(t, check(FS_GET_FILE_LIST, nil, listing))
+
+	//a controller of a delegated ONT ID issues passports for the read-only listings only
+	ontId, err := account.GenerateID()
+	assert.Nil(t, err)
+	sink = common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(ontId))
+	sink.WriteVarBytes(keypair.SerializePublicKey(reader.PublicKey))
+	_, err = chain.Invoke(utils.OntIDContractAddress, "regIDWithPublicKey", sink.Bytes(), reader.Address)
+	assert.Nil(t, err)
+
+	delegated := passport(reader, owner.Address, FS_GET_FILE_LIST, nil, []byte(ontId), chain.Height)
+	assert.Error(t, check(FS_GET_FILE_LIST, nil, delegated))
+	delegates := PassportDelegates{WalletAddr: owner.Address, OntIds: [][]byte{[]byte(ontId)}}
+	sink = common.NewZeroCopySink(nil)
+	delegates.Serialization(sink)
+	sim.invokeFail(FS_SET_PASSPORT_DELEGATES, sink.Bytes(), reader.Address)
+	sim.invoke(FS_SET_PASSPORT_DELEGATES, sink.Bytes(), owner.Address)
+	assert.Nil(t, check(FS_GET_FILE_LIST, nil, delegated))
+	delegatedEnvelope := passport(reader, owner.Address, FS_GET_KEY_ENVELOPE, fileHash, []byte(ontId), chain.Height)
+	assert.Error(t, check(FS_GET_KEY_ENVELOPE, fileHash, delegatedEnvelope))
+	//only keys of the ONT ID sign for it
+	assert.Error(t, check(FS_GET_FILE_LIST, nil,
+		passport(sim.node, owner.Address, FS_GET_FILE_LIST, nil, []byte(ontId), chain.Height)))
+
+	//an empty list revokes the delegation
+	delegates.OntIds = nil
+	sink = common.NewZeroCopySink(nil)
+	delegates.Serialization(sink)
+	sim.invoke(FS_SET_PASSPORT_DELEGATES, sink.Bytes(), owner.Address)
+	assert.Error(t, check(FS_GET_FILE_LIST, nil, delegated))
+}
+
+func TestSimulation_UpdateFiles(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	owner, reader := sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+
+	sim.registerNode()
+	lifeWindows := uint64(10)
+	expireTime := uint64(simStartTime + lifeWindows*simPdpInterval)
+	fileProfit := uint64(simFileBlocks * simBlockSize * DefaultGasPerKbForSaveWithFile)
+	sim.storeFiles(FileInfoList{FilesI: []FileInfo{{
+		FileHash:       simPaidFile,
+		FileOwner:      owner,
+		FileBlockCount: simFileBlocks,
+		RealFileSize:   simFileBlocks * simBlockSize,
+		CopyNumber:     2,
+		PdpInterval:    simPdpInterval,
+		TimeExpired:    expireTime,
+		StorageType:    FileStorageTypeUseFile,
+		PdpParam:       []byte("pdp param"),
+		BlockSize:      simBlockSize,
+	}}}, (lifeWindows+1)*2*fileProfit)
+	sim.prove(simPaidFile, uint64(chain.Height))
+	sim.proveRound(simPaidFile)
+	assert.Equal(t, fileProfit, sim.nodeInfo().Profit)
+
+	update := func(newCopyNumber uint64, newPdpInterval uint64) []byte {
+		updateList := FileUpdateList{FilesUpdate: []FileUpdate{{FileHash: simPaidFile, FileOwner: owner,
+			NewCopyNumber: newCopyNumber, NewPdpInterval: newPdpInterval}}}
+		sink := common.NewZeroCopySink(nil)
+		updateList.Serialization(sink)
+		return wrapVarBytes(sink.Bytes())
+	}
+	fileInfo := func() *FileInfo {
+		var fileInfo *FileInfo
+		chain.Read(contract, func(native *native.NativeService) {
+			fileInfo = getFileInfoByHash(native, simPaidFile)
+		})
+		return fileInfo
+	}
+	restWindows := func(pdpInterval uint64) uint64 {
+		return (expireTime-uint64(chain.Time))/pdpInterval + 1
+	}
+
+	//only the owner updates the file, a pdp interval below the MinPdpInterval of the node is refused
+	sim.invoke(FS_UPDATE_FILES, update(1, 0), reader)
+	sim.invoke(FS_UPDATE_FILES, update(0, simPdpInterval/2), owner)
+	assert.Equal(t, uint64(2), fileInfo().CopyNumber)
+	assert.Equal(t, uint64(simPdpInterval), fileInfo().PdpInterval)
+
+	//dropping a copy refunds its rest windows, the node proving the file keeps it
+	sim.transfer(contract, owner, restWindows(simPdpInterval)*fileProfit)
+	sim.invoke(FS_UPDATE_FILES, update(1, 0), owner)
+	assert.Equal(t, uint64(1), fileInfo().CopyNumber)
+	assert.False(t, sim.pdpRecord(simPaidFile).SettleFlag)
+
+	//a longer pdp interval refunds the windows left out
+	sim.transfer(contract, owner, (restWindows(simPdpInterval)-restWindows(2*simPdpInterval))*fileProfit)
+	sim.invoke(FS_UPDATE_FILES, update(0, 2*simPdpInterval), owner)
+	assert.Equal(t, uint64(2*simPdpInterval), fileInfo().PdpInterval)
+
+	//a new copy pays the rest windows, the node is still paid the same profit for every pdp
+	sim.transfer(owner, contract, restWindows(2*simPdpInterval)*fileProfit)
+	sim.invoke(FS_UPDATE_FILES, update(2, 0), owner)
+	assert.Equal(t, uint64(2), fileInfo().CopyNumber)
+	sim.proveRound(simPaidFile)
+	assert.Equal(t, 2*fileProfit, sim.nodeInfo().Profit)
+}
+
+func TestSimulation_RenewEscrow(t *testing.T) {
+	sim := newFsSimulation(t)
+	chain := sim.chain
+	owner, reader := sim.owner.Address, sim.reader.Address
+	contract := utils.OntFSContractAddress
+	expireTime := uint64(simStartTime + simExpireAfter)
+
+	sim.registerNode()
+	spaceInfo := SpaceInfo{
+		SpaceOwner:  owner,
+		Volume:      simSpaceVolume,
+		CopyNumber:  1,
+		PdpInterval: simPdpInterval,
+		TimeExpired: expireTime,
+	}
+	sink := common.NewZeroCopySink(nil)
+	spaceInfo.Serialization(sink)
+	spacePayAmount := uint64(simExpireAfter/simPdpInterval+1) * simSpaceVolume * DefaultGasPerKbForSaveWithSpace
+	sim.transfer(owner, contract, spacePayAmount)
+	sim.invoke(FS_CREATE_SPACE, wrapVarBytes(sink.Bytes()), owner)
+	sim.storeFiles(FileInfoList{FilesI: []FileInfo{{
+		FileHash:       simSpaceFile,
+		FileOwner:      owner,
+		FileBlockCount: simSpaceBlocks,
+		RealFileSize:   simSpaceBlocks * simBlockSize,
+		CopyNumber:     1,
+		PdpInterval:    simPdpInterval,
+		TimeExpired:    expireTime,
+		StorageType:    FileStorageTypeUseSpace,
+		PdpParam:       []byte("pdp param"),
+		BlockSize:      simBlockSize,
+	}}}, 0)
+
+	//an escrow with no FileHash renews the space, for two renewals and a caller reward
+	renewFee := uint64(DefaultRenewWindow/simPdpInterval) * simSpaceVolume * DefaultGasPerKbForSaveWithSpace
+	escrow := RenewEscrow{Owner: owner, RenewPeriod: DefaultRenewWindow, MaxPrice: renewFee,
+		Balance: 2*renewFee + DefaultRenewCallerReward}
+	sink = common.NewZeroCopySink(nil)
+	escrow.Serialization(sink)
+	sim.invokeFail(FS_SET_RENEW_ESCROW, wrapVarBytes(sink.Bytes()), reader)
+	sim.transfer(owner, contract, escrow.Balance)
+	sim.invoke(FS_SET_RENEW_ESCROW, wrapVarBytes(sink.Bytes()), owner)
+
+	space := func() *SpaceInfo {
+		var space *SpaceInfo
+		chain.Read(contract, func(native *native.NativeService) {
+			space = getSpaceInfoFromDb(native, owner)
+		})
+		return space
+	}
+	escrowBalance := func() uint64 {
+		var balance uint64
+		chain.Read(contract, func(native *native.NativeService) {
+			balance = getRenewEscrow(native, owner, nil).Balance
+		})
+		return balance
+	}
+
+	//a prove of a file in the space renews the space
+	sim.prove(simSpaceFile, uint64(chain.Height))
+	assert.Equal(t, expireTime+DefaultRenewWindow, space().TimeExpired)
+	assert.Equal(t, renewFee+DefaultRenewCallerReward, escrowBalance())
+
+	//the caller is rewarded for the targets renewed only: a space out of the renew window, a repeated
+	//target and a file without an escrow are skipped
+	targetList := RenewTargetList{Caller: reader, Targets: []RenewTarget{{Owner: owner}, {Owner: owner},
+		{Owner: owner, FileHash: simSpaceFile}}}
+	sink = common.NewZeroCopySink(nil)
+	targetList.Serialization(sink)
+	sim.invoke(FS_TRIGGER_RENEW, wrapVarBytes(sink.Bytes()), reader)
+	assert.Equal(t, expireTime+DefaultRenewWindow, space().TimeExpired)
+
+	chain.AddTime(simExpireAfter)
+	sim.transfer(contract, reader, DefaultRenewCallerReward)
+	sim.invoke(FS_TRIGGER_RENEW, wrapVarBytes(sink.Bytes()), reader)
+	assert.Equal(t, expireTime+2*DefaultRenewWindow, space().TimeExpired)
+	assert.Equal(t, uint64(0), escrowBalance())
+
+	//the renewals are funded by the owner, the empty escrow is cancelled with nothing to refund
+	chain.Read(contract, func(native *native.NativeService) {
+		funding, err := getSpaceFunding(native, getSpaceInfoFromDb(native, owner))
+		assert.Nil(t, err)
+		assert.Equal(t, []SpaceFunder{{owner, spacePayAmount + 2*renewFee}}, funding.Funders)
+	})
+	cancel := RenewTarget{Owner: owner}
+	sink = common.NewZeroCopySink(nil)
+	cancel.Serialization(sink)
+	sim.invoke(FS_CANCEL_RENEW_ESCROW, sink.Bytes(), owner)
+	sim.invokeFail(FS_CANCEL_RENEW_ESCROW, sink.Bytes(), owner)
+}
@@ -20,6 +20,7 @@ type SpaceInfo struct {
 }
 
 func (this *SpaceInfo) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
 	utils.EncodeAddress(sink, this.SpaceOwner)
 	utils.EncodeVarUint(sink, this.Volume)
 	utils.EncodeVarUint(sink, this.RestVol)
@@ -33,7 +34,10 @@ func (this *SpaceInfo) Serialization(sink *common.ZeroCopySink) {
 }
 
 func (this *SpaceInfo) Deserialization(source *common.ZeroCopySource) error {
-	var err error
+	_, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
 	this.SpaceOwner, err = utils.DecodeAddress(source)
 	if err != nil {
 		return err
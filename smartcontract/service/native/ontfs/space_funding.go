@@ -0,0 +1,224 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// SpaceFunder is an address which paid for a space, Amount is what it put in less what was refunded to it
+type SpaceFunder struct {
+	Funder common.Address
+	Amount uint64
+}
+
+// SpaceFunding is the funding ledger of a space. Refunds of the space go back to its funders
+// in proportion to their Amount.
+type SpaceFunding struct {
+	SpaceOwner common.Address
+	Funders    []SpaceFunder
+}
+
+func (this *SpaceFunder) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Funder)
+	utils.EncodeVarUint(sink, this.Amount)
+}
+
+func (this *SpaceFunder) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.Funder, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	this.Amount, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *SpaceFunding) Serialization(sink *common.ZeroCopySink) {
+	encodeRecordVersion(sink)
+	utils.EncodeAddress(sink, this.SpaceOwner)
+	utils.EncodeVarUint(sink, uint64(len(this.Funders)))
+	for _, funder := range this.Funders {
+		sinkTmp := common.NewZeroCopySink(nil)
+		funder.Serialization(sinkTmp)
+		sink.WriteVarBytes(sinkTmp.Bytes())
+	}
+}
+
+func (this *SpaceFunding) Deserialization(source *common.ZeroCopySource) error {
+	_, err := decodeRecordVersion(source)
+	if err != nil {
+		return err
+	}
+	this.SpaceOwner, err = utils.DecodeAddress(source)
+	if err != nil {
+		return err
+	}
+	funderCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < funderCount; i++ {
+		funderTmp, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		var funder SpaceFunder
+		if err = funder.Deserialization(common.NewZeroCopySource(funderTmp)); err != nil {
+			return err
+		}
+		this.Funders = append(this.Funders, funder)
+	}
+	return nil
+}
+
+// Total returns the sum of the funders' Amount
+func (this *SpaceFunding) Total() uint64 {
+	var total uint64
+	for _, funder := range this.Funders {
+		total += funder.Amount
+	}
+	return total
+}
+
+// fund records amount paid for the space by funder
+func (this *SpaceFunding) fund(funder common.Address, amount uint64) {
+	if amount == 0 {
+		return
+	}
+	for i := range this.Funders {
+		if this.Funders[i].Funder == funder {
+			this.Funders[i].Amount += amount
+			return
+		}
+	}
+	this.Funders = append(this.Funders, SpaceFunder{Funder: funder, Amount: amount})
+}
+
+// refundShares splits amount between the funders in proportion to their Amount and takes the
+// shares off the ledger. The rounding remainder goes to the earliest funders.
+func (this *SpaceFunding) refundShares(amount uint64) ([]SpaceFunder, error) {
+	total := this.Total()
+	if amount > total {
+		return nil, fmt.Errorf("refund %d exceeds space funding %d", amount, total)
+	}
+	if amount == 0 {
+		return nil, nil
+	}
+
+	shares := make([]uint64, len(this.Funders))
+	var shared uint64
+	for i, funder := range this.Funders {
+		share := new(big.Int).SetUint64(amount)
+		share.Mul(share, new(big.Int).SetUint64(funder.Amount))
+		share.Div(share, new(big.Int).SetUint64(total))
+		shares[i] = share.Uint64()
+		shared += shares[i]
+	}
+	for i := 0; shared < amount && i < len(this.Funders); i++ {
+		extra := amount - shared
+		if room := this.Funders[i].Amount - shares[i]; extra > room {
+			extra = room
+		}
+		shares[i] += extra
+		shared += extra
+	}
+
+	var refunds []SpaceFunder
+	funders := this.Funders[:0]
+	for i, funder := range this.Funders {
+		if shares[i] != 0 {
+			refunds = append(refunds, SpaceFunder{Funder: funder.Funder, Amount: shares[i]})
+		}
+		funder.Amount -= shares[i]
+		if funder.Amount != 0 {
+			funders = append(funders, funder)
+		}
+	}
+	this.Funders = funders
+	return refunds, nil
+}
+
+func addSpaceFunding(native *native.NativeService, funding *SpaceFunding) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	fundingKey := GenFsSpaceFundingKey(contract, funding.SpaceOwner)
+
+	sink := common.NewZeroCopySink(nil)
+	funding.Serialization(sink)
+	utils.PutBytes(native, fundingKey, sink.Bytes())
+}
+
+func delSpaceFunding(native *native.NativeService, spaceOwner common.Address) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	fundingKey := GenFsSpaceFundingKey(contract, spaceOwner)
+	native.CacheDB.Delete(fundingKey)
+}
+
+func getRawSpaceFunding(native *native.NativeService, spaceOwner common.Address) []byte {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	fundingKey := GenFsSpaceFundingKey(contract, spaceOwner)
+
+	item, err := utils.GetStorageItem(native, fundingKey)
+	if err != nil || item == nil || item.Value == nil {
+		return nil
+	}
+	return item.Value
+}
+
+// getSpaceFunding returns the funding ledger of space. A space created before the ledger
+// existed is funded by its owner alone.
+func getSpaceFunding(native *native.NativeService, space *SpaceInfo) (*SpaceFunding, error) {
+	rawFunding := getRawSpaceFunding(native, space.SpaceOwner)
+	if rawFunding == nil {
+		funding := &SpaceFunding{SpaceOwner: space.SpaceOwner}
+		funding.fund(space.SpaceOwner, space.RestAmount)
+		return funding, nil
+	}
+
+	var funding SpaceFunding
+	if err := funding.Deserialization(common.NewZeroCopySource(rawFunding)); err != nil {
+		return nil, err
+	}
+	return &funding, nil
+}
+
+// refundSpaceFunding pays amount of the space back to its funders and updates the ledger
+func refundSpaceFunding(native *native.NativeService, funding *SpaceFunding, amount uint64) error {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	refunds, err := funding.refundShares(amount)
+	if err != nil {
+		return err
+	}
+	for _, refund := range refunds {
+		err = appCallTransfer(native, utils.OngContractAddress, contract, refund.Funder, refund.Amount)
+		if err != nil {
+			return fmt.Errorf("refund %s transfer error", refund.Funder.ToBase58())
+		}
+	}
+	return nil
+}
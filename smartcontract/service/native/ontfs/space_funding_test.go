@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpaceFunding_RefundShares(t *testing.T) {
+	a, b, c := common.Address{0x01}, common.Address{0x02}, common.Address{0x03}
+	funding := SpaceFunding{SpaceOwner: a}
+	funding.fund(a, 100)
+	funding.fund(b, 200)
+	funding.fund(a, 100)
+	funding.fund(c, 0)
+	assert.Equal(t, []SpaceFunder{{a, 200}, {b, 200}}, funding.Funders)
+
+	refunds, err := funding.refundShares(100)
+	assert.Nil(t, err)
+	assert.Equal(t, []SpaceFunder{{a, 50}, {b, 50}}, refunds)
+
+	//the rounding remainder goes to the earliest funders
+	funding.fund(c, 150)
+	refunds, err = funding.refundShares(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []SpaceFunder{{a, 2}}, refunds)
+	assert.Equal(t, uint64(448), funding.Total())
+
+	_, err = funding.refundShares(449)
+	assert.NotNil(t, err)
+
+	//a funder paid back in full leaves the ledger
+	refunds, err = funding.refundShares(448)
+	assert.Nil(t, err)
+	assert.Equal(t, []SpaceFunder{{a, 148}, {b, 150}, {c, 150}}, refunds)
+	assert.Empty(t, funding.Funders)
+}
+
+func TestSpaceFunding_Serialization(t *testing.T) {
+	funding := SpaceFunding{SpaceOwner: common.Address{0x01}}
+	funding.fund(common.Address{0x01}, 10)
+	funding.fund(common.Address{0x02}, 20)
+
+	sink := common.NewZeroCopySink(nil)
+	funding.Serialization(sink)
+	var decoded SpaceFunding
+	assert.Nil(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, funding, decoded)
+}
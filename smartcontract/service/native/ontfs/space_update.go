@@ -19,6 +19,8 @@
 package ontfs
 
 import (
+	"fmt"
+
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 )
@@ -57,3 +59,34 @@ func (this *SpaceUpdate) Deserialization(source *common.ZeroCopySource) error {
 	}
 	return nil
 }
+
+// updateSpaceInfo applies newVolume and newTimeExpired to space. It returns the fee difference and
+// whether that difference has to be refunded from the contract instead of being paid to it
+func updateSpaceInfo(space *SpaceInfo, newVolume uint64, newTimeExpired uint64,
+	gasPerKbForSaveWithSpace uint64) (uint64, bool, error) {
+	if space.Volume-space.RestVol >= newVolume {
+		return 0, false, fmt.Errorf("NewVolume is not enough!")
+	}
+
+	newSpacePdpNeedCount := (newTimeExpired-space.TimeStart)/space.PdpInterval + 1
+	newPayAmount := newSpacePdpNeedCount * newVolume * space.CopyNumber * gasPerKbForSaveWithSpace
+
+	var newFee uint64
+	var refund bool
+	if newPayAmount > space.PayAmount {
+		newFee = newPayAmount - space.PayAmount
+		space.RestAmount += newFee
+	} else if newPayAmount < space.PayAmount {
+		newFee = space.PayAmount - newPayAmount
+		refund = true
+		if space.RestAmount < newFee {
+			return 0, false, fmt.Errorf("space RestAmount < newFee error!")
+		}
+		space.RestAmount -= newFee
+	}
+	space.PayAmount = newPayAmount
+	space.RestVol = newVolume - (space.Volume - space.RestVol)
+	space.Volume = newVolume
+	space.TimeExpired = newTimeExpired
+	return newFee, refund, nil
+}
@@ -29,49 +29,95 @@ import (
 )
 
 const (
-	FS_SET_GLOBAL_PARAM      = "FsSetGlobalParam"
-	FS_GET_GLOBAL_PARAM      = "FsGetGlobalParam"
-	FS_NODE_REGISTER         = "FsNodeRegister"
-	FS_NODE_QUERY            = "FsNodeQuery"
-	FS_NODE_UPDATE           = "FsNodeUpdate"
-	FS_NODE_CANCEL           = "FsNodeCancel"
-	FS_FILE_PROVE            = "FsFileProve"
-	FS_NODE_WITH_DRAW_PROFIT = "FsNodeWithDrawProfit"
-	FS_GET_NODE_LIST         = "FsGetNodeList"
-	FS_GET_PDP_INFO_LIST     = "FsGetPdpInfoList"
-	FS_STORE_FILES           = "FsStoreFiles"
-	FS_RENEW_FILES           = "FsRenewFiles"
-	FS_DELETE_FILES          = "FsDeleteFiles"
-	FS_TRANSFER_FILES        = "FsTransferFiles"
-	FS_GET_FILE_INFO         = "FsGetFileInfo"
-	FS_GET_FILE_LIST         = "FsGetFileList"
-	FS_READ_FILE_PLEDGE      = "FsReadFilePledge"
-	FS_READ_FILE_SETTLE      = "FsReadFileSettle"
-	FS_GET_READ_PLEDGE       = "FsGetReadPledge"
-	FS_CANCEL_FILE_READ      = "FsCancelFileRead"
-	FS_SET_WHITE_LIST        = "FsSetWhiteList"
-	FS_GET_WHITE_LIST        = "FsGetWhiteList"
-	FS_CREATE_SPACE          = "FsCreateSpace"
-	FS_DELETE_SPACE          = "FsDeleteSpace"
-	FS_UPDATE_SPACE          = "FsUpdateSpace"
-	FS_GET_SPACE_INFO        = "FsGetSpaceInfo"
+	FS_SET_GLOBAL_PARAM       = "FsSetGlobalParam"
+	FS_GET_GLOBAL_PARAM       = "FsGetGlobalParam"
+	FS_SET_PASSPORT_EXPIRE    = "FsSetPassportExpire"
+	FS_NODE_REGISTER          = "FsNodeRegister"
+	FS_NODE_QUERY             = "FsNodeQuery"
+	FS_NODE_UPDATE            = "FsNodeUpdate"
+	FS_NODE_CANCEL            = "FsNodeCancel"
+	FS_FILE_PROVE             = "FsFileProve"
+	FS_NODE_WITH_DRAW_PROFIT  = "FsNodeWithDrawProfit"
+	FS_NODE_ATTEST            = "FsNodeAttest"
+	FS_GET_NODE_LIST          = "FsGetNodeList"
+	FS_GET_PDP_INFO_LIST      = "FsGetPdpInfoList"
+	FS_STORE_FILES            = "FsStoreFiles"
+	FS_RENEW_FILES            = "FsRenewFiles"
+	FS_DELETE_FILES           = "FsDeleteFiles"
+	FS_UPDATE_FILES           = "FsUpdateFiles"
+	FS_TRANSFER_FILES         = "FsTransferFiles"
+	FS_GET_FILE_INFO          = "FsGetFileInfo"
+	FS_GET_FILE_LIST          = "FsGetFileList"
+	FS_GET_FILE_CID           = "FsGetFileCid"
+	FS_READ_FILE_PLEDGE       = "FsReadFilePledge"
+	FS_READ_FILE_SETTLE       = "FsReadFileSettle"
+	FS_GET_READ_PLEDGE        = "FsGetReadPledge"
+	FS_CANCEL_FILE_READ       = "FsCancelFileRead"
+	FS_READ_SESSION_PLEDGE    = "FsReadSessionPledge"
+	FS_READ_SESSION_SETTLE    = "FsReadSessionSettle"
+	FS_GET_READ_SESSION       = "FsGetReadSession"
+	FS_CANCEL_READ_SESSION    = "FsCancelReadSession"
+	FS_SET_WHITE_LIST         = "FsSetWhiteList"
+	FS_GET_WHITE_LIST         = "FsGetWhiteList"
+	FS_CREATE_SPACE           = "FsCreateSpace"
+	FS_DELETE_SPACE           = "FsDeleteSpace"
+	FS_UPDATE_SPACE           = "FsUpdateSpace"
+	FS_TOP_UP_SPACE           = "FsTopUpSpace"
+	FS_GET_SPACE_FUNDING      = "FsGetSpaceFunding"
+	FS_GET_SPACE_INFO         = "FsGetSpaceInfo"
+	FS_SET_RENEW_ESCROW       = "FsSetRenewEscrow"
+	FS_CANCEL_RENEW_ESCROW    = "FsCancelRenewEscrow"
+	FS_GET_RENEW_ESCROW       = "FsGetRenewEscrow"
+	FS_TRIGGER_RENEW          = "FsTriggerRenew"
+	FS_AUDIT                  = "FsAudit"
+	FS_SET_KEY_ENVELOPES      = "FsSetKeyEnvelopes"
+	FS_REVOKE_KEY_ENVELOPES   = "FsRevokeKeyEnvelopes"
+	FS_GET_KEY_ENVELOPE       = "FsGetKeyEnvelope"
+	FS_SET_FEE_CONFIG         = "FsSetFeeConfig"
+	FS_GET_FEE_CONFIG         = "FsGetFeeConfig"
+	FS_GET_FEE_POOL           = "FsGetFeePool"
+	FS_WITHDRAW_TREASURY      = "FsWithdrawTreasury"
+	FS_CLAIM_FILE_LOSS        = "FsClaimFileLoss"
+	FS_SET_PASSPORT_DELEGATES = "FsSetPassportDelegates"
+	FS_GET_PASSPORT_DELEGATES = "FsGetPassportDelegates"
 )
 
 const (
-	ONTFS_GLOBAL_PARAM     = "ontFsGlobalParam"
-	ONTFS_NODE_INFO        = "ontFsNodeInfo"
-	ONTFS_FILE_INFO        = "ontFsFileInfo"
-	ONTFS_FILE_PDP         = "ontFsFilePdp"
-	ONTFS_FILE_OWNER       = "ontFsFileOwner"
-	ONTFS_FILE_WHITE_LIST  = "ontFsFileWhiteList"
-	ONTFS_FILE_READ_PLEDGE = "ontFsFileReadPledge"
-	ONTFS_FILE_SPACE       = "ontFsFileSpace"
+	ONTFS_GLOBAL_PARAM      = "ontFsGlobalParam"
+	ONTFS_NODE_INFO         = "ontFsNodeInfo"
+	ONTFS_FILE_INFO         = "ontFsFileInfo"
+	ONTFS_FILE_PDP          = "ontFsFilePdp"
+	ONTFS_FILE_OWNER        = "ontFsFileOwner"
+	ONTFS_FILE_WHITE_LIST   = "ontFsFileWhiteList"
+	ONTFS_FILE_READ_PLEDGE  = "ontFsFileReadPledge"
+	ONTFS_FILE_SPACE        = "ontFsFileSpace"
+	ONTFS_RENEW_ESCROW      = "ontFsRenewEscrow"
+	ONTFS_KEY_ENVELOPE      = "ontFsKeyEnvelope"
+	ONTFS_PROFIT_VESTING    = "ontFsProfitVesting"
+	ONTFS_READ_SESSION      = "ontFsReadSession"
+	ONTFS_SPACE_FUNDING     = "ontFsSpaceFunding"
+	ONTFS_FEE_CONFIG        = "ontFsFeeConfig"
+	ONTFS_FEE_POOL          = "ontFsFeePool"
+	ONTFS_PASSPORT_DELEGATE = "ontFsPassportDelegate"
 )
 
 func GenGlobalParamKey(contract common.Address) []byte {
 	return append(contract[:], ONTFS_GLOBAL_PARAM...)
 }
 
+func GenFsFeeConfigKey(contract common.Address) []byte {
+	return append(contract[:], ONTFS_FEE_CONFIG...)
+}
+
+func GenFsFeePoolKey(contract common.Address) []byte {
+	return append(contract[:], ONTFS_FEE_POOL...)
+}
+
+func GenFsPassportDelegateKey(contract common.Address, walletAddr common.Address) []byte {
+	key := append(contract[:], ONTFS_PASSPORT_DELEGATE...)
+	return append(key, walletAddr[:]...)
+}
+
 func GenFsNodeInfoPrefix(contract common.Address) []byte {
 	prefix := append(contract[:], ONTFS_NODE_INFO...)
 	return prefix
@@ -122,11 +168,44 @@ func GenFsReadPledgeKey(contract common.Address, downloader common.Address, file
 	return append(key, fileHash[:]...)
 }
 
+func GenFsReadSessionKey(contract common.Address, downloader common.Address, sessionId []byte) []byte {
+	key := append(contract[:], ONTFS_READ_SESSION...)
+	key = append(key, downloader[:]...)
+	return append(key, sessionId...)
+}
+
 func GenFsSpaceKey(contract common.Address, spaceOwner common.Address) []byte {
 	key := append(contract[:], ONTFS_FILE_SPACE...)
 	return append(key, spaceOwner[:]...)
 }
 
+func GenFsSpaceFundingKey(contract common.Address, spaceOwner common.Address) []byte {
+	key := append(contract[:], ONTFS_SPACE_FUNDING...)
+	return append(key, spaceOwner[:]...)
+}
+
+func GenFsRenewEscrowKey(contract common.Address, owner common.Address, fileHash []byte) []byte {
+	key := append(contract[:], ONTFS_RENEW_ESCROW...)
+	key = append(key, owner[:]...)
+	return append(key, fileHash...)
+}
+
+func GenFsKeyEnvelopePrefix(contract common.Address, fileOwner common.Address, fileHash []byte) []byte {
+	prefix := append(contract[:], ONTFS_KEY_ENVELOPE...)
+	prefix = append(prefix, fileOwner[:]...)
+	return append(prefix, fileHash...)
+}
+
+func GenFsKeyEnvelopeKey(contract common.Address, fileOwner common.Address, fileHash []byte, reader common.Address) []byte {
+	prefix := GenFsKeyEnvelopePrefix(contract, fileOwner, fileHash)
+	return append(prefix, reader[:]...)
+}
+
+func GenFsProfitVestingKey(contract common.Address, nodeAddr common.Address) []byte {
+	key := append(contract[:], ONTFS_PROFIT_VESTING...)
+	return append(key, nodeAddr[:]...)
+}
+
 func appCallTransfer(native *native.NativeService, contract common.Address, from common.Address, to common.Address, amount uint64) error {
 	var sts []ont.State
 	sts = append(sts, ont.State{
@@ -147,6 +226,17 @@ func appCallTransfer(native *native.NativeService, contract common.Address, from
 	return nil
 }
 
+func getOngBalance(native *native.NativeService, address common.Address) (uint64, error) {
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeAddress(sink, address)
+
+	value, err := native.NativeCall(utils.OngContractAddress, "balanceOf", sink.Bytes())
+	if err != nil {
+		return 0, fmt.Errorf("getOngBalance, appCall error: %v", err)
+	}
+	return common.BigIntFromNeoBytes(value.([]byte)).Uint64(), nil
+}
+
 func DecodeVarBytes(source *common.ZeroCopySource) ([]byte, error) {
 	var err error
 	buf, _, irregular, eof := source.NextVarBytes()
@@ -129,6 +129,24 @@ func batchInsertAttr(srvc *native.NativeService, encID []byte, attr []attribute)
 }
 
 func getAllAttr(srvc *native.NativeService, encID []byte) ([]byte, error) {
+	list, err := getAttrList(srvc, encID)
+	if err != nil {
+		return nil, err
+	} else if list == nil {
+		return nil, nil
+	}
+
+	var res bytes.Buffer
+	for _, attr := range list {
+		err = attr.Serialize(&res)
+		if err != nil {
+			return nil, fmt.Errorf("serialize error, %s", err)
+		}
+	}
+	return res.Bytes(), nil
+}
+
+func getAttrList(srvc *native.NativeService, encID []byte) ([]*attribute, error) {
 	key := append(encID, FIELD_ATTR)
 	item, err := utils.LinkedlistGetHead(srvc, key)
 	if err != nil {
@@ -138,8 +156,7 @@ func getAllAttr(srvc *native.NativeService, encID []byte) ([]byte, error) {
 		return nil, nil
 	}
 
-	var res bytes.Buffer
-	var i uint16 = 0
+	list := make([]*attribute, 0)
 	for len(item) > 0 {
 		node, err := utils.LinkedlistGetItem(srvc, key, item)
 		if err != nil {
@@ -148,19 +165,14 @@ func getAllAttr(srvc *native.NativeService, encID []byte) ([]byte, error) {
 			return nil, fmt.Errorf("storage item not exists, %v", item)
 		}
 
-		var attr attribute
+		attr := new(attribute)
 		err = attr.SetValue(node.GetPayload())
 		if err != nil {
 			return nil, fmt.Errorf("parse attribute failed, %s", err)
 		}
 		attr.key = item
-		err = attr.Serialize(&res)
-		if err != nil {
-			return nil, fmt.Errorf("serialize error, %s", err)
-		}
-
-		i += 1
+		list = append(list, attr)
 		item = node.GetNext()
 	}
-	return res.Bytes(), nil
+	return list, nil
 }
@@ -0,0 +1,324 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ontio/ontology/common/serialization"
+	"github.com/ontio/ontology/core/states"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+const (
+	// an ID is never empty, so no encoded ID has this length byte
+	PREFIX_CREDENTIAL byte = 0x00
+
+	MAX_CREDENTIAL_HASH_LEN = 64
+
+	CREDENTIAL_NOT_EXIST          = "not exist"
+	CREDENTIAL_VALID              = "valid"
+	CREDENTIAL_EXPIRED            = "expired"
+	CREDENTIAL_REVOKED            = "revoked"
+	CREDENTIAL_ISSUER_KEY_REVOKED = "issuer key revoked"
+)
+
+// credential is the on-chain anchor of a claim an issuer ONT ID makes about a subject,
+// the claim itself stays off-chain and is identified by its hash
+type credential struct {
+	issuer  []byte
+	subject []byte
+	// unix time after which the claim is no longer valid, 0 if it never expires
+	expiry uint64
+	// index of the issuer key that committed the credential
+	keyID   uint32
+	revoked bool
+}
+
+func (this *credential) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.issuer); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.subject); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint64(w, this.expiry); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint32(w, this.keyID); err != nil {
+		return err
+	}
+	if err := serialization.WriteBool(w, this.revoked); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *credential) Deserialize(r io.Reader) error {
+	issuer, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	subject, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	expiry, err := serialization.ReadUint64(r)
+	if err != nil {
+		return err
+	}
+	keyID, err := serialization.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	revoked, err := serialization.ReadBool(r)
+	if err != nil {
+		return err
+	}
+	this.issuer = issuer
+	this.subject = subject
+	this.expiry = expiry
+	this.keyID = keyID
+	this.revoked = revoked
+	return nil
+}
+
+// genCredentialKey keys the credential by the encoded issuer ID and the hash, so the same
+// hash committed by another issuer is another credential
+func genCredentialKey(issuer []byte, hash []byte) []byte {
+	key := append(utils.OntIDContractAddress[:], PREFIX_CREDENTIAL)
+	key = append(key, issuer[len(utils.OntIDContractAddress):]...)
+	return append(key, hash...)
+}
+
+func getCredential(srvc *native.NativeService, issuer []byte, hash []byte) (*credential, error) {
+	item, err := utils.GetStorageItem(srvc, genCredentialKey(issuer, hash))
+	if err != nil {
+		return nil, fmt.Errorf("get storage error, %s", err)
+	} else if item == nil {
+		return nil, nil
+	}
+	cred := new(credential)
+	if err := cred.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, fmt.Errorf("deserialize credential error, %s", err)
+	}
+	return cred, nil
+}
+
+func putCredential(srvc *native.NativeService, issuer []byte, hash []byte, cred *credential) error {
+	var buf bytes.Buffer
+	if err := cred.Serialize(&buf); err != nil {
+		return err
+	}
+	srvc.CacheDB.Put(genCredentialKey(issuer, hash), states.GenRawStorageItem(buf.Bytes()))
+	return nil
+}
+
+func readCredentialHash(args io.Reader) ([]byte, error) {
+	hash, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) == 0 || len(hash) > MAX_CREDENTIAL_HASH_LEN {
+		return nil, errors.New("invalid hash length")
+	}
+	return hash, nil
+}
+
+func commitCredential(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	// arg0: credential hash
+	arg0, err := readCredentialHash(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: argument 0 error, %s", err)
+	}
+	// arg1: issuer ID
+	arg1, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: argument 1 error, %s", err)
+	}
+	// arg2: subject ID
+	arg2, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: argument 2 error, %s", err)
+	} else if len(arg2) == 0 {
+		return utils.BYTE_FALSE, errors.New("commit credential failed: argument 2 error, invalid subject")
+	}
+	// arg3: expiry
+	arg3, err := utils.ReadVarUint(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: argument 3 error, %s", err)
+	}
+	// arg4: issuer's public key
+	arg4, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: argument 4 error, %s", err)
+	}
+
+	if arg3 != 0 && arg3 <= uint64(srvc.Time) {
+		return utils.BYTE_FALSE, errors.New("commit credential failed: already expired")
+	}
+	key, err := encodeID(arg1)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: %s", err)
+	}
+	if !checkIDExistence(srvc, key) {
+		return utils.BYTE_FALSE, errors.New("commit credential failed: issuer not registered")
+	}
+	keyID, revoked, err := findPk(srvc, key, arg4)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: %s", err)
+	} else if keyID == 0 || revoked {
+		return utils.BYTE_FALSE, errors.New("commit credential failed: not a valid key of the issuer")
+	}
+	if err = checkWitness(srvc, arg4); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: check witness failed, %s", err)
+	}
+
+	cred, err := getCredential(srvc, key, arg0)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: %s", err)
+	} else if cred != nil {
+		return utils.BYTE_FALSE, errors.New("commit credential failed: already committed")
+	}
+	cred = &credential{issuer: arg1, subject: arg2, expiry: arg3, keyID: keyID}
+	if err = putCredential(srvc, key, arg0, cred); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("commit credential failed: %s", err)
+	}
+
+	triggerCredentialEvent(srvc, "commit", arg0, cred)
+	return utils.BYTE_TRUE, nil
+}
+
+func revokeCredential(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	// arg0: credential hash
+	arg0, err := readCredentialHash(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("revoke credential failed: argument 0 error, %s", err)
+	}
+	// arg1: issuer ID
+	arg1, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("revoke credential failed: argument 1 error, %s", err)
+	}
+	// arg2: issuer's public key
+	arg2, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("revoke credential failed: argument 2 error, %s", err)
+	}
+
+	key, err := encodeID(arg1)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("revoke credential failed: %s", err)
+	}
+	cred, err := getCredential(srvc, key, arg0)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("revoke credential failed: %s", err)
+	} else if cred == nil {
+		return utils.BYTE_FALSE, errors.New("revoke credential failed: not committed")
+	} else if cred.revoked {
+		return utils.BYTE_FALSE, errors.New("revoke credential failed: already revoked")
+	}
+	// any key of the issuer in use may revoke, not only the one that committed
+	if !isOwner(srvc, key, arg2) {
+		return utils.BYTE_FALSE, errors.New("revoke credential failed: operator has no authorization")
+	}
+	if err = checkWitness(srvc, arg2); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("revoke credential failed: check witness failed, %s", err)
+	}
+
+	cred.revoked = true
+	if err = putCredential(srvc, key, arg0, cred); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("revoke credential failed: %s", err)
+	}
+
+	triggerCredentialEvent(srvc, "revoke", arg0, cred)
+	return utils.BYTE_TRUE, nil
+}
+
+// GetCredential returns the serialized record of the credential the issuer committed, nil if
+// it is not committed
+func GetCredential(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	hash, err := readCredentialHash(args)
+	if err != nil {
+		return nil, fmt.Errorf("get credential failed: argument 0 error, %s", err)
+	}
+	issuer, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return nil, fmt.Errorf("get credential failed: argument 1 error, %s", err)
+	}
+	key, err := encodeID(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("get credential failed: %s", err)
+	}
+	cred, err := getCredential(srvc, key, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get credential failed: %s", err)
+	} else if cred == nil {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := cred.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("get credential failed: %s", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// GetCredentialStatus returns whether the credential the issuer committed can be trusted at the current block.
+// The issuer key that committed it is checked too, a credential signed by a key revoked
+// since then is reported apart, it's up to the verifier to accept it or not.
+func GetCredentialStatus(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	hash, err := readCredentialHash(args)
+	if err != nil {
+		return nil, fmt.Errorf("get credential status failed: argument 0 error, %s", err)
+	}
+	issuer, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return nil, fmt.Errorf("get credential status failed: argument 1 error, %s", err)
+	}
+	key, err := encodeID(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("get credential status failed: %s", err)
+	}
+	cred, err := getCredential(srvc, key, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get credential status failed: %s", err)
+	} else if cred == nil {
+		return []byte(CREDENTIAL_NOT_EXIST), nil
+	} else if cred.revoked {
+		return []byte(CREDENTIAL_REVOKED), nil
+	} else if cred.expiry != 0 && cred.expiry <= uint64(srvc.Time) {
+		return []byte(CREDENTIAL_EXPIRED), nil
+	}
+
+	pk, err := getPk(srvc, key, cred.keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get credential status failed: %s", err)
+	} else if pk == nil || pk.revoked {
+		return []byte(CREDENTIAL_ISSUER_KEY_REVOKED), nil
+	}
+	return []byte(CREDENTIAL_VALID), nil
+}
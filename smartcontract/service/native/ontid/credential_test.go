@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredential(t *testing.T) {
+	Init()
+	chain, err := testsuite.NewChain(1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, second, subject := account.NewAccount(""), account.NewAccount(""), account.NewAccount("")
+	issuerKey := keypair.SerializePublicKey(issuer.PublicKey)
+	secondKey := keypair.SerializePublicKey(second.PublicKey)
+	subjectKey := keypair.SerializePublicKey(subject.PublicKey)
+	issuerId, _ := account.GenerateID()
+	subjectId, _ := account.GenerateID()
+	contract := utils.OntIDContractAddress
+
+	register := func(id string, pub []byte, signer common.Address) {
+		sink := common.NewZeroCopySink(nil)
+		sink.WriteVarBytes([]byte(id))
+		sink.WriteVarBytes(pub)
+		_, err := chain.Invoke(contract, "regIDWithPublicKey", sink.Bytes(), signer)
+		assert.Nil(t, err)
+	}
+	register(issuerId, issuerKey, issuer.Address)
+	register(subjectId, subjectKey, subject.Address)
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(issuerId))
+	sink.WriteVarBytes(secondKey)
+	sink.WriteVarBytes(issuerKey)
+	_, err = chain.Invoke(contract, "addKey", sink.Bytes(), issuer.Address)
+	assert.Nil(t, err)
+
+	commitBy := func(issuerId string, hash []byte, expiry uint64, pub []byte, signer common.Address) error {
+		sink := common.NewZeroCopySink(nil)
+		sink.WriteVarBytes(hash)
+		sink.WriteVarBytes([]byte(issuerId))
+		sink.WriteVarBytes([]byte(subjectId))
+		utils.EncodeVarUint(sink, expiry)
+		sink.WriteVarBytes(pub)
+		_, err := chain.Invoke(contract, "commitCredential", sink.Bytes(), signer)
+		return err
+	}
+	commit := func(hash []byte, expiry uint64, pub []byte, signer common.Address) error {
+		return commitBy(issuerId, hash, expiry, pub, signer)
+	}
+	revoke := func(hash []byte, pub []byte, signer common.Address) error {
+		sink := common.NewZeroCopySink(nil)
+		sink.WriteVarBytes(hash)
+		sink.WriteVarBytes([]byte(issuerId))
+		sink.WriteVarBytes(pub)
+		_, err := chain.Invoke(contract, "revokeCredential", sink.Bytes(), signer)
+		return err
+	}
+	statusBy := func(issuerId string, hash []byte) string {
+		sink := common.NewZeroCopySink(nil)
+		sink.WriteVarBytes(hash)
+		sink.WriteVarBytes([]byte(issuerId))
+		ret, err := chain.PreExecute(contract, "getCredentialStatus", sink.Bytes())
+		assert.Nil(t, err)
+		return string(ret)
+	}
+	status := func(hash []byte) string {
+		return statusBy(issuerId, hash)
+	}
+
+	claim := bytes.Repeat([]byte{1}, 32)
+	assert.Equal(t, CREDENTIAL_NOT_EXIST, status(claim))
+	//another ID committing the hash first doesn't take it from the issuer
+	assert.Nil(t, commitBy(subjectId, claim, 0, subjectKey, subject.Address))
+	assert.Equal(t, CREDENTIAL_VALID, statusBy(subjectId, claim))
+	assert.Equal(t, CREDENTIAL_NOT_EXIST, status(claim))
+	//only a key of the issuer in use commits, signed by that key
+	assert.Error(t, commit(claim, 0, subjectKey, subject.Address))
+	assert.Error(t, commit(claim, 0, issuerKey, subject.Address))
+	assert.Error(t, commit(claim, 999, issuerKey, issuer.Address))
+	chain.ClearNotifications()
+	assert.Nil(t, commit(claim, 0, secondKey, second.Address))
+	assert.Equal(t, CREDENTIAL_VALID, status(claim))
+	assert.Error(t, commit(claim, 0, issuerKey, issuer.Address))
+	assert.Equal(t, []interface{}{"Credential", "commit", common.ToHexString(claim), issuerId, subjectId},
+		chain.Notifications[0].States)
+
+	sink = common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(claim)
+	sink.WriteVarBytes([]byte(issuerId))
+	ret, err := chain.PreExecute(contract, "getCredential", sink.Bytes())
+	assert.Nil(t, err)
+	_, err = chain.Invoke(contract, "getCredential", sink.Bytes())
+	assert.Error(t, err)
+	cred := new(credential)
+	assert.Nil(t, cred.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, &credential{issuer: []byte(issuerId), subject: []byte(subjectId), keyID: 2}, cred)
+
+	//revoking the issuer key that committed the credential is reported
+	sink = common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(issuerId))
+	sink.WriteVarBytes(secondKey)
+	sink.WriteVarBytes(issuerKey)
+	_, err = chain.Invoke(contract, "removeKey", sink.Bytes(), issuer.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, CREDENTIAL_ISSUER_KEY_REVOKED, status(claim))
+
+	//any key of the issuer in use revokes
+	assert.Error(t, revoke(claim, secondKey, second.Address))
+	assert.Error(t, revoke(claim, subjectKey, subject.Address))
+	chain.ClearNotifications()
+	assert.Nil(t, revoke(claim, issuerKey, issuer.Address))
+	assert.Equal(t, CREDENTIAL_REVOKED, status(claim))
+	assert.Equal(t, []interface{}{"Credential", "revoke", common.ToHexString(claim), issuerId, subjectId},
+		chain.Notifications[0].States)
+	assert.Error(t, revoke(claim, issuerKey, issuer.Address))
+
+	//a credential with an expiry goes expired with the block time
+	expiring := bytes.Repeat([]byte{2}, 32)
+	assert.Nil(t, commit(expiring, uint64(chain.Time)+10, issuerKey, issuer.Address))
+	assert.Equal(t, CREDENTIAL_VALID, status(expiring))
+	chain.AddTime(10)
+	assert.Equal(t, CREDENTIAL_EXPIRED, status(expiring))
+}
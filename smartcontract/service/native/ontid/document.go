@@ -0,0 +1,205 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/serialization"
+	"github.com/ontio/ontology/smartcontract/service/native"
+)
+
+const (
+	DID_CONTEXT = "https://www.w3.org/ns/did/v1"
+
+	// attributes of this type are listed as the service entries of the DID document
+	ATTR_TYPE_SERVICE = "service"
+
+	KEY_TYPE_SECP256R1 = "EcdsaSecp256r1VerificationKey2019"
+	KEY_TYPE_SECP256K1 = "EcdsaSecp256k1VerificationKey2019"
+	KEY_TYPE_SM2       = "SM2VerificationKey2019"
+	KEY_TYPE_ED25519   = "Ed25519VerificationKey2018"
+)
+
+// DIDDocument is the W3C DID document of an ONT ID
+type DIDDocument struct {
+	Context            []string              `json:"@context"`
+	Id                 string                `json:"id"`
+	Controller         string                `json:"controller"`
+	VerificationMethod []*VerificationMethod `json:"verificationMethod"`
+	Authentication     []string              `json:"authentication"`
+	Service            []*DIDService         `json:"service,omitempty"`
+	Attribute          []*DIDAttribute       `json:"attribute,omitempty"`
+	// keys removed by removeKey, they keep their id so that old signatures can be attributed
+	Revoked  []*VerificationMethod `json:"revokedVerificationMethod,omitempty"`
+	Recovery string                `json:"recovery,omitempty"`
+}
+
+type VerificationMethod struct {
+	Id           string `json:"id"`
+	Type         string `json:"type"`
+	Controller   string `json:"controller"`
+	PublicKeyHex string `json:"publicKeyHex"`
+}
+
+// DIDService is built from an attribute of type "service", the value is either the endpoint
+// or a json object with the type and the endpoint of the service
+type DIDService struct {
+	Id              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+type DIDAttribute struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// ResolveDocument builds the DID document of id from the contract storage, nil if id is not registered
+func ResolveDocument(srvc *native.NativeService, id []byte) (*DIDDocument, error) {
+	encID, err := encodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !checkIDExistence(srvc, encID) {
+		return nil, nil
+	}
+
+	did := string(id)
+	doc := &DIDDocument{
+		Context:            []string{DID_CONTEXT},
+		Id:                 did,
+		Controller:         did,
+		VerificationMethod: make([]*VerificationMethod, 0),
+		Authentication:     make([]string, 0),
+	}
+
+	keys, err := getAllPk(srvc, append(encID, FIELD_PK))
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range keys {
+		method := &VerificationMethod{
+			Id:           fmt.Sprintf("%s#keys-%d", did, i+1),
+			Type:         verificationKeyType(v.key),
+			Controller:   did,
+			PublicKeyHex: hex.EncodeToString(v.key),
+		}
+		if v.revoked {
+			doc.Revoked = append(doc.Revoked, method)
+			continue
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, method)
+		doc.Authentication = append(doc.Authentication, method.Id)
+	}
+
+	attrs, err := getAttrList(srvc, encID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range attrs {
+		if strings.EqualFold(string(v.valueType), ATTR_TYPE_SERVICE) {
+			doc.Service = append(doc.Service, newDIDService(did, v))
+			continue
+		}
+		doc.Attribute = append(doc.Attribute, &DIDAttribute{
+			Key:   string(v.key),
+			Type:  string(v.valueType),
+			Value: string(v.value),
+		})
+	}
+
+	recovery, err := getRecovery(srvc, encID)
+	if err != nil {
+		return nil, err
+	}
+	if len(recovery) > 0 {
+		addr, err := common.AddressParseFromBytes(recovery)
+		if err != nil {
+			return nil, fmt.Errorf("parse recovery error, %s", err)
+		}
+		doc.Recovery = addr.ToBase58()
+	}
+	return doc, nil
+}
+
+// GetDocument returns the json encoded DID document of the ONT ID, nil if the ID is not registered
+func GetDocument(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	did, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return nil, fmt.Errorf("get document error: invalid argument, %s", err)
+	}
+	if len(did) == 0 {
+		return nil, errors.New("get document error: invalid ID")
+	}
+	doc, err := ResolveDocument(srvc, did)
+	if err != nil {
+		return nil, fmt.Errorf("get document error: %s", err)
+	} else if doc == nil {
+		return nil, nil
+	}
+	res, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("get document error: %s", err)
+	}
+	return res, nil
+}
+
+func newDIDService(did string, attr *attribute) *DIDService {
+	service := &DIDService{}
+	if err := json.Unmarshal(attr.value, service); err != nil || service.ServiceEndpoint == "" {
+		service = &DIDService{ServiceEndpoint: string(attr.value)}
+	}
+	if service.Type == "" {
+		service.Type = "Service"
+	}
+	service.Id = did + "#" + string(attr.key)
+	return service
+}
+
+func verificationKeyType(pubKey []byte) string {
+	if len(pubKey) < 2 {
+		return ""
+	}
+	switch keypair.KeyType(pubKey[0]) {
+	case keypair.PK_P256_E, keypair.PK_P256_O, keypair.PK_P256_NC:
+		return KEY_TYPE_SECP256R1
+	case keypair.PK_EDDSA:
+		return KEY_TYPE_ED25519
+	case keypair.PK_SM2:
+		return KEY_TYPE_SM2
+	case keypair.PK_ECDSA:
+		switch pubKey[1] {
+		case keypair.P256:
+			return KEY_TYPE_SECP256R1
+		case keypair.SECP256K1:
+			return KEY_TYPE_SECP256K1
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontid
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDocument(t *testing.T) {
+	Init()
+	chain, err := testsuite.NewChain(1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, second, recovery := account.NewAccount(""), account.NewAccount(""), account.NewAccount("")
+	ownerKey := keypair.SerializePublicKey(owner.PublicKey)
+	secondKey := keypair.SerializePublicKey(second.PublicKey)
+	id, err := account.GenerateID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoke := func(method string, sink *common.ZeroCopySink) {
+		_, err := chain.Invoke(utils.OntIDContractAddress, method, sink.Bytes(), owner.Address)
+		assert.Nil(t, err)
+	}
+	resolve := func() *DIDDocument {
+		sink := common.NewZeroCopySink(nil)
+		sink.WriteVarBytes([]byte(id))
+		ret, err := chain.PreExecute(utils.OntIDContractAddress, "getDocument", sink.Bytes())
+		assert.Nil(t, err)
+		if len(ret) == 0 {
+			return nil
+		}
+		doc := new(DIDDocument)
+		assert.Nil(t, json.Unmarshal(ret, doc))
+		return doc
+	}
+
+	assert.Nil(t, resolve())
+	//a transaction can't resolve the document
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	_, err = chain.Invoke(utils.OntIDContractAddress, "getDocument", sink.Bytes())
+	assert.Error(t, err)
+
+	sink = common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	sink.WriteVarBytes(ownerKey)
+	utils.EncodeVarUint(sink, 2)
+	for _, attr := range []attribute{
+		{key: []byte("hub"), valueType: []byte("service"),
+			value: []byte(`{"type":"CredentialHub","serviceEndpoint":"https://hub.example.com"}`)},
+		{key: []byte("name"), valueType: []byte("string"), value: []byte("alice")},
+	} {
+		sink.WriteVarBytes(attr.key)
+		sink.WriteVarBytes(attr.valueType)
+		sink.WriteVarBytes(attr.value)
+	}
+	invoke("regIDWithAttributes", sink)
+
+	for _, method := range []string{"addKey", "removeKey"} {
+		sink = common.NewZeroCopySink(nil)
+		sink.WriteVarBytes([]byte(id))
+		sink.WriteVarBytes(secondKey)
+		sink.WriteVarBytes(ownerKey)
+		invoke(method, sink)
+	}
+	sink = common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	sink.WriteVarBytes(recovery.Address[:])
+	sink.WriteVarBytes(ownerKey)
+	invoke("addRecovery", sink)
+
+	doc := resolve()
+	if !assert.NotNil(t, doc) {
+		return
+	}
+	assert.Equal(t, []string{DID_CONTEXT}, doc.Context)
+	assert.Equal(t, id, doc.Id)
+	assert.Equal(t, id, doc.Controller)
+	assert.Equal(t, []*VerificationMethod{{
+		Id:           id + "#keys-1",
+		Type:         KEY_TYPE_SECP256R1,
+		Controller:   id,
+		PublicKeyHex: hex.EncodeToString(ownerKey),
+	}}, doc.VerificationMethod)
+	assert.Equal(t, []string{id + "#keys-1"}, doc.Authentication)
+	assert.Equal(t, 1, len(doc.Revoked))
+	assert.Equal(t, id+"#keys-2", doc.Revoked[0].Id)
+	assert.Equal(t, []*DIDService{{
+		Id:              id + "#hub",
+		Type:            "CredentialHub",
+		ServiceEndpoint: "https://hub.example.com",
+	}}, doc.Service)
+	assert.Equal(t, []*DIDAttribute{{Key: "name", Type: "string", Value: "alice"}}, doc.Attribute)
+	assert.Equal(t, recovery.Address.ToBase58(), doc.Recovery)
+}
+
+func TestNewDIDService(t *testing.T) {
+	service := newDIDService("did:ont:x", &attribute{key: []byte("web"), value: []byte("https://example.com")})
+	assert.Equal(t, &DIDService{Id: "did:ont:x#web", Type: "Service", ServiceEndpoint: "https://example.com"}, service)
+}
@@ -61,3 +61,8 @@ func triggerRecoveryEvent(srvc *native.NativeService, op string, id []byte, addr
 	st := []string{"Recovery", op, string(id), addr.ToHexString()}
 	newEvent(srvc, st)
 }
+
+func triggerCredentialEvent(srvc *native.NativeService, op string, hash []byte, cred *credential) {
+	st := []interface{}{"Credential", op, hex.EncodeToString(hash), string(cred.issuer), string(cred.subject)}
+	newEvent(srvc, st)
+}
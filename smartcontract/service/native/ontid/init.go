@@ -40,5 +40,13 @@ func RegisterIDContract(srvc *native.NativeService) {
 	srvc.Register("getKeyState", GetKeyState)
 	srvc.Register("getAttributes", GetAttributes)
 	srvc.Register("getDDO", GetDDO)
+	srvc.Register("commitCredential", commitCredential)
+	srvc.Register("revokeCredential", revokeCredential)
+	//the document and the credentials are only read by pre-executed invokes, transactions can't reach them
+	if srvc.PreExec {
+		srvc.Register("getDocument", GetDocument)
+		srvc.Register("getCredential", GetCredential)
+		srvc.Register("getCredentialStatus", GetCredentialStatus)
+	}
 	return
 }
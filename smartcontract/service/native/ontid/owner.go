@@ -174,3 +174,19 @@ func isOwner(srvc *native.NativeService, encID, pub []byte) bool {
 	}
 	return kID != 0 && !revoked
 }
+
+// VerifyIDKey checks pub is an unrevoked public key of the ONT ID, for other native
+// contracts accepting data signed by an ONT ID
+func VerifyIDKey(srvc *native.NativeService, id, pub []byte) error {
+	encID, err := encodeID(id)
+	if err != nil {
+		return err
+	}
+	if !checkIDExistence(srvc, encID) {
+		return errors.New("ONT ID not registered")
+	}
+	if !isOwner(srvc, encID, pub) {
+		return errors.New("public key is not a valid key of the ONT ID")
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package testsuite runs native contracts on an in memory chain, so tests can drive whole
+// contract scenarios without a ledger.
+package testsuite
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	cstates "github.com/ontio/ontology/core/states"
+	"github.com/ontio/ontology/core/store"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/context"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ont"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	sstates "github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/storage"
+)
+
+// Chain keeps contract storage in an overlay on a memory db, with a fake block height and clock.
+// It is the context ref and the ledger store of the native services it runs; ledger methods
+// other than the header ones below are not available.
+type Chain struct {
+	store.LedgerStore
+
+	Height        uint32
+	Time          uint32
+	BlockInterval uint32
+	Notifications []*event.NotifyEventInfo
+
+	overlay  *overlaydb.OverlayDB
+	contexts []*context.Context
+	signers  []common.Address
+	pending  []*event.NotifyEventInfo
+}
+
+// NewChain returns an empty chain at height and time
+func NewChain(height uint32, time uint32) (*Chain, error) {
+	db, err := leveldbstore.NewMemLevelDBStore()
+	if err != nil {
+		return nil, err
+	}
+	return &Chain{
+		Height:        height,
+		Time:          time,
+		BlockInterval: 1,
+		overlay:       overlaydb.NewOverlayDB(db),
+	}, nil
+}
+
+// AddBlocks moves the chain count blocks forward, the clock moves BlockInterval seconds per block
+func (this *Chain) AddBlocks(count uint32) {
+	this.Height += count
+	this.Time += count * this.BlockInterval
+}
+
+// AddTime moves the clock forward without new blocks
+func (this *Chain) AddTime(seconds uint32) {
+	this.Time += seconds
+}
+
+// Invoke runs method of a native contract as a transaction signed by signers. The storage
+// changes and notifications are kept only if the invoke succeeds.
+func (this *Chain) Invoke(contract common.Address, method string, args []byte,
+	signers ...common.Address) ([]byte, error) {
+	cache := storage.NewCacheDB(this.overlay)
+	service := this.newNativeService(cache)
+	service.InvokeParam = sstates.ContractInvokeParam{Address: contract, Method: method, Args: args}
+
+	this.contexts = nil
+	this.signers = signers
+	this.pending = nil
+	defer func() {
+		this.contexts = nil
+		this.signers = nil
+		this.pending = nil
+	}()
+
+	result, err := service.Invoke()
+	if err != nil {
+		return nil, err
+	}
+	ret, ok := result.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("[Invoke] %s returns %T", method, result)
+	}
+	cache.Commit()
+	this.Notifications = append(this.Notifications, this.pending...)
+	return ret, nil
+}
+
+// PreExecute runs method of a native contract as a pre-executed query, which serves the
+// query methods too. The storage changes and notifications are dropped.
+func (this *Chain) PreExecute(contract common.Address, method string, args []byte) ([]byte, error) {
+	service := this.newNativeService(storage.NewCacheDB(this.overlay))
+	service.PreExec = true
+	service.InvokeParam = sstates.ContractInvokeParam{Address: contract, Method: method, Args: args}
+
+	this.contexts = nil
+	this.pending = nil
+	defer func() {
+		this.contexts = nil
+		this.pending = nil
+	}()
+
+	result, err := service.Invoke()
+	if err != nil {
+		return nil, err
+	}
+	ret, ok := result.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("[PreExecute] %s returns %T", method, result)
+	}
+	return ret, nil
+}
+
+// Read runs fn in the context of contract, the storage changes fn makes are dropped
+func (this *Chain) Read(contract common.Address, fn func(native *native.NativeService)) {
+	service := this.newNativeService(storage.NewCacheDB(this.overlay))
+	this.contexts = []*context.Context{{ContractAddress: contract}}
+	defer func() { this.contexts = nil }()
+	fn(service)
+}
+
+// SetBalance sets the balance of addr in the ont or ong contract
+func (this *Chain) SetBalance(token common.Address, addr common.Address, amount uint64) {
+	cache := storage.NewCacheDB(this.overlay)
+	cache.Put(ont.GenBalanceKey(token, addr), utils.GenUInt64StorageItem(amount).ToArray())
+	cache.Commit()
+}
+
+// SetStorage puts a raw storage item, to set up the state of contracts a test doesn't drive
+func (this *Chain) SetStorage(key []byte, value []byte) {
+	cache := storage.NewCacheDB(this.overlay)
+	cache.Put(key, (&cstates.StorageItem{Value: value}).ToArray())
+	cache.Commit()
+}
+
+// Balance returns the balance of addr in the ont or ong contract
+func (this *Chain) Balance(token common.Address, addr common.Address) uint64 {
+	var balance uint64
+	this.Read(token, func(native *native.NativeService) {
+		balance, _ = utils.GetStorageUInt64(native, ont.GenBalanceKey(token, addr))
+	})
+	return balance
+}
+
+// ClearNotifications drops the notifications kept so far
+func (this *Chain) ClearNotifications() {
+	this.Notifications = nil
+}
+
+func (this *Chain) newNativeService(cache *storage.CacheDB) *native.NativeService {
+	return &native.NativeService{
+		Store:      this,
+		CacheDB:    cache,
+		Tx:         &types.Transaction{},
+		Height:     this.Height,
+		Time:       this.Time,
+		ContextRef: this,
+		ServiceMap: make(map[string]native.Handler),
+	}
+}
+
+func (this *Chain) PushContext(context *context.Context) {
+	this.contexts = append(this.contexts, context)
+}
+
+func (this *Chain) CurrentContext() *context.Context {
+	if len(this.contexts) < 1 {
+		return nil
+	}
+	return this.contexts[len(this.contexts)-1]
+}
+
+func (this *Chain) CallingContext() *context.Context {
+	if len(this.contexts) < 2 {
+		return nil
+	}
+	return this.contexts[len(this.contexts)-2]
+}
+
+func (this *Chain) EntryContext() *context.Context {
+	if len(this.contexts) < 1 {
+		return nil
+	}
+	return this.contexts[0]
+}
+
+func (this *Chain) PopContext() {
+	if len(this.contexts) > 0 {
+		this.contexts = this.contexts[:len(this.contexts)-1]
+	}
+}
+
+// CheckWitness passes for the signers of the invoke and for the calling contract
+func (this *Chain) CheckWitness(address common.Address) bool {
+	for _, signer := range this.signers {
+		if signer == address {
+			return true
+		}
+	}
+	if this.CallingContext() != nil && this.CallingContext().ContractAddress == address {
+		return true
+	}
+	return false
+}
+
+func (this *Chain) PushNotifications(notifications []*event.NotifyEventInfo) {
+	this.pending = append(this.pending, notifications...)
+}
+
+func (this *Chain) NewExecuteEngine(code []byte) (context.Engine, error) {
+	return nil, fmt.Errorf("[NewExecuteEngine] vm contracts are not supported")
+}
+
+func (this *Chain) CheckUseGas(gas uint64) bool {
+	return true
+}
+
+func (this *Chain) CheckExecStep() bool {
+	return true
+}
+
+func (this *Chain) GetCurrentBlockHeight() uint32 {
+	return this.Height
+}
+
+// GetHeaderByHeight returns a header whose hash differs by height, it has no consensus payload
+func (this *Chain) GetHeaderByHeight(height uint32) (*types.Header, error) {
+	if height > this.Height {
+		return nil, fmt.Errorf("[GetHeaderByHeight] height %d above current height %d", height, this.Height)
+	}
+	return &types.Header{Height: height}, nil
+}
@@ -39,6 +39,12 @@ var (
 	RUNTIME_VERIFYMUTISIG_GAS     uint64 = 400
 	RUNTIME_ADDRESSTOBASE58_GAS   uint64 = 40
 	RUNTIME_BASE58TOADDRESS_GAS   uint64 = 30
+	FILESTORE_GETFILEINFO_GAS     uint64 = 300
+	FILESTORE_ISFILEVALID_GAS     uint64 = 500
+	FILESTORE_GETOWNER_GAS        uint64 = 200
+	FILESTORE_GETPDPSTATUS_GAS    uint64 = 500
+	FILESTORE_ISWHITELISTED_GAS   uint64 = 300
+	FILESTORE_PDPRECORD_GAS       uint64 = 100 // Per pdp record of the file.
 	APPCALL_GAS                   uint64 = 10
 	TAILCALL_GAS                  uint64 = 10
 	SHA1_GAS                      uint64 = 10
@@ -107,6 +113,12 @@ var (
 
 	NATIVE_INVOKE_NAME = "Ontology.Native.Invoke"
 
+	FILESTORE_GETFILEINFO_NAME   = "Ontology.FileStore.GetFileInfo"
+	FILESTORE_ISFILEVALID_NAME   = "Ontology.FileStore.IsFileValid"
+	FILESTORE_GETOWNER_NAME      = "Ontology.FileStore.GetOwner"
+	FILESTORE_GETPDPSTATUS_NAME  = "Ontology.FileStore.GetPdpStatus"
+	FILESTORE_ISWHITELISTED_NAME = "Ontology.FileStore.IsWhitelisted"
+
 	GETSCRIPTCONTAINER_NAME     = "System.ExecutionEngine.GetScriptContainer"
 	GETEXECUTINGSCRIPTHASH_NAME = "System.ExecutionEngine.GetExecutingScriptHash"
 	GETCALLINGSCRIPTHASH_NAME   = "System.ExecutionEngine.GetCallingScriptHash"
@@ -195,5 +207,11 @@ func initGAS_TABLE() *sync.Map {
 
 	m.Store(RUNTIME_VERIFYMUTISIG_NAME, RUNTIME_VERIFYMUTISIG_GAS)
 
+	m.Store(FILESTORE_GETFILEINFO_NAME, FILESTORE_GETFILEINFO_GAS)
+	m.Store(FILESTORE_ISFILEVALID_NAME, FILESTORE_ISFILEVALID_GAS)
+	m.Store(FILESTORE_GETOWNER_NAME, FILESTORE_GETOWNER_GAS)
+	m.Store(FILESTORE_GETPDPSTATUS_NAME, FILESTORE_GETPDPSTATUS_GAS)
+	m.Store(FILESTORE_ISWHITELISTED_NAME, FILESTORE_ISWHITELISTED_GAS)
+
 	return &m
 }
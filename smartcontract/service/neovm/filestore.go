@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package neovm
+
+import (
+	"math/big"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+	vm "github.com/ontio/ontology/vm/neovm"
+	vmtypes "github.com/ontio/ontology/vm/neovm/types"
+)
+
+// FileStoreGetFileInfo put the ontfs file info struct of the file hash to vm stack,
+// an empty byte array if the file is not stored
+func FileStoreGetFileInfo(service *NeoVmService, engine *vm.ExecutionEngine) error {
+	fileHash, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	var fileInfo *ontfs.FileInfo
+	ontfs.InteropCall(newFileStoreNative(service), func(native *native.NativeService) {
+		fileInfo = ontfs.InteropGetFileInfo(native, fileHash)
+	})
+	if fileInfo == nil {
+		vm.PushData(engine, []byte{})
+		return nil
+	}
+	vm.PushData(engine, vmtypes.NewStruct([]vmtypes.StackItems{
+		vmtypes.NewByteArray(fileInfo.FileHash),
+		vmtypes.NewByteArray(fileInfo.FileOwner[:]),
+		vmtypes.NewByteArray(fileInfo.FileDesc),
+		uintStackItem(fileInfo.FileBlockCount),
+		uintStackItem(fileInfo.RealFileSize),
+		uintStackItem(fileInfo.CopyNumber),
+		uintStackItem(fileInfo.PayAmount),
+		uintStackItem(fileInfo.RestAmount),
+		uintStackItem(fileInfo.FileCost),
+		vmtypes.NewBoolean(fileInfo.FirstPdp),
+		uintStackItem(fileInfo.PdpInterval),
+		uintStackItem(fileInfo.TimeStart),
+		uintStackItem(fileInfo.TimeExpired),
+		vmtypes.NewByteArray(fileInfo.PdpParam),
+		vmtypes.NewBoolean(fileInfo.ValidFlag),
+		uintStackItem(fileInfo.StorageType),
+		uintStackItem(fileInfo.BlockSize),
+	}))
+	return nil
+}
+
+// FileStoreIsFileValid put true to vm stack if the file is unexpired and proved by a storage node
+func FileStoreIsFileValid(service *NeoVmService, engine *vm.ExecutionEngine) error {
+	fileHash, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	var status *ontfs.PdpStatus
+	ontfs.InteropCall(newFileStoreNative(service), func(native *native.NativeService) {
+		status = ontfs.InteropGetPdpStatus(native, fileHash)
+	})
+	if err := usePdpRecordGas(service, status); err != nil {
+		return err
+	}
+	var valid bool
+	ontfs.InteropCall(newFileStoreNative(service), func(native *native.NativeService) {
+		valid = ontfs.InteropIsFileValid(native, fileHash)
+	})
+	vm.PushData(engine, valid)
+	return nil
+}
+
+// FileStoreGetOwner put the owner address of the file to vm stack,
+// an empty byte array if the file is not stored
+func FileStoreGetOwner(service *NeoVmService, engine *vm.ExecutionEngine) error {
+	fileHash, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	var fileOwner common.Address
+	var ok bool
+	ontfs.InteropCall(newFileStoreNative(service), func(native *native.NativeService) {
+		fileOwner, ok = ontfs.InteropGetFileOwner(native, fileHash)
+	})
+	if !ok {
+		vm.PushData(engine, []byte{})
+		return nil
+	}
+	vm.PushData(engine, fileOwner[:])
+	return nil
+}
+
+// FileStoreGetPdpStatus put the pdp status struct of the file to vm stack,
+// an empty byte array if the file is not stored
+func FileStoreGetPdpStatus(service *NeoVmService, engine *vm.ExecutionEngine) error {
+	fileHash, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	var status *ontfs.PdpStatus
+	ontfs.InteropCall(newFileStoreNative(service), func(native *native.NativeService) {
+		status = ontfs.InteropGetPdpStatus(native, fileHash)
+	})
+	if status == nil {
+		vm.PushData(engine, []byte{})
+		return nil
+	}
+	if err := usePdpRecordGas(service, status); err != nil {
+		return err
+	}
+	vm.PushData(engine, vmtypes.NewStruct([]vmtypes.StackItems{
+		uintStackItem(status.CopyNumber),
+		uintStackItem(status.NodeCount),
+		uintStackItem(status.ProvedCount),
+		uintStackItem(status.SettledCount),
+		uintStackItem(status.LastPdpTime),
+		uintStackItem(status.NextHeight),
+	}))
+	return nil
+}
+
+// FileStoreIsWhitelisted put true to vm stack if the address may read the file
+func FileStoreIsWhitelisted(service *NeoVmService, engine *vm.ExecutionEngine) error {
+	fileHash, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	data, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	addr, err := common.AddressParseFromBytes(data)
+	if err != nil {
+		return err
+	}
+	var allowed bool
+	ontfs.InteropCall(newFileStoreNative(service), func(native *native.NativeService) {
+		allowed = ontfs.InteropIsWhitelisted(native, fileHash, addr)
+	})
+	vm.PushData(engine, allowed)
+	return nil
+}
+
+func newFileStoreNative(service *NeoVmService) *native.NativeService {
+	return &native.NativeService{
+		Store:      service.Store,
+		CacheDB:    service.CacheDB,
+		Tx:         service.Tx,
+		Height:     service.Height,
+		Time:       service.Time,
+		BlockHash:  service.BlockHash,
+		ContextRef: service.ContextRef,
+		ServiceMap: make(map[string]native.Handler),
+	}
+}
+
+func uintStackItem(value uint64) vmtypes.StackItems {
+	return vmtypes.NewInteger(new(big.Int).SetUint64(value))
+}
+
+// usePdpRecordGas charges the pdp records of the file the service iterated
+func usePdpRecordGas(service *NeoVmService, status *ontfs.PdpStatus) error {
+	if status == nil {
+		return nil
+	}
+	if !service.ContextRef.CheckUseGas(status.NodeCount * FILESTORE_PDPRECORD_GAS) {
+		return ERR_GAS_INSUFFICIENT
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package neovm
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/context"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ong"
+	"github.com/ontio/ontology/smartcontract/service/native/ontfs"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	vm "github.com/ontio/ontology/vm/neovm"
+	"github.com/ontio/ontology/vm/neovm/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStoreInterop(t *testing.T) {
+	ontfs.InitFs()
+	ong.InitOng()
+	chain, err := testsuite.NewChain(100, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, reader, stranger := common.Address{0x01}, common.Address{0x02}, common.Address{0x03}
+	chain.SetBalance(utils.OngContractAddress, owner, 1000000000)
+
+	fileHash := []byte("QmevhnWdtmz89BMXuuX5pSY2uZtqKLz7frJsrCojT5kmb6")
+	fileInfoList := ontfs.FileInfoList{FilesI: []ontfs.FileInfo{{
+		FileHash:       fileHash,
+		FileOwner:      owner,
+		FileBlockCount: 4,
+		RealFileSize:   1000,
+		CopyNumber:     2,
+		PdpInterval:    600,
+		TimeExpired:    1000000 + 6000,
+		StorageType:    ontfs.FileStorageTypeUseFile,
+		PdpParam:       []byte("pdp param"),
+	}}}
+	sink := common.NewZeroCopySink(nil)
+	fileInfoList.Serialization(sink)
+	input := common.NewZeroCopySink(nil)
+	input.WriteVarBytes(sink.Bytes())
+	_, err = chain.Invoke(utils.OntFSContractAddress, ontfs.FS_STORE_FILES, input.Bytes(), owner)
+	assert.Nil(t, err)
+
+	sink = common.NewZeroCopySink(nil)
+	utils.EncodeAddress(sink, owner)
+	sink.WriteVarBytes(fileHash)
+	utils.EncodeVarUint(sink, 1)
+	utils.EncodeAddress(sink, reader)
+	_, err = chain.Invoke(utils.OntFSContractAddress, ontfs.FS_SET_WHITE_LIST, sink.Bytes(), owner)
+	assert.Nil(t, err)
+
+	for _, nodeAddr := range []common.Address{{0x04}, {0x05}} {
+		pdpRecord := ontfs.PdpRecord{NodeAddr: nodeAddr, FileHash: fileHash, FileOwner: owner}
+		sink = common.NewZeroCopySink(nil)
+		pdpRecord.Serialization(sink)
+		chain.SetStorage(ontfs.GenFsPdpRecordKey(utils.OntFSContractAddress, fileHash, owner, nodeAddr), sink.Bytes())
+	}
+
+	chain.Read(utils.OntFSContractAddress, func(native *native.NativeService) {
+		gas := &gasCounter{ContextRef: native.ContextRef}
+		service := &NeoVmService{Store: native.Store, CacheDB: native.CacheDB, ContextRef: gas,
+			Height: native.Height, Time: native.Time}
+
+		engine := vm.NewExecutionEngine(0)
+		engine.EvaluationStack.Push(types.NewByteArray(fileHash))
+		assert.Nil(t, FileStoreGetPdpStatus(service, engine))
+		status, err := engine.EvaluationStack.Pop().GetStruct()
+		assert.Nil(t, err)
+		assert.Equal(t, 6, len(status))
+		copyNumber, err := status[0].GetBigInteger()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), copyNumber.Int64())
+		nodeCount, err := status[1].GetBigInteger()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), nodeCount.Int64())
+		//the pdp records are charged one by one
+		assert.Equal(t, 2*FILESTORE_PDPRECORD_GAS, gas.used)
+
+		engine.EvaluationStack.Push(types.NewByteArray([]byte("unknown file")))
+		assert.Nil(t, FileStoreGetPdpStatus(service, engine))
+		empty, err := engine.EvaluationStack.Pop().GetByteArray()
+		assert.Nil(t, err)
+		assert.Empty(t, empty)
+
+		isWhitelisted := func(addr common.Address) bool {
+			engine.EvaluationStack.Push(types.NewByteArray(addr[:]))
+			engine.EvaluationStack.Push(types.NewByteArray(fileHash))
+			assert.Nil(t, FileStoreIsWhitelisted(service, engine))
+			allowed, err := engine.EvaluationStack.Pop().GetBoolean()
+			assert.Nil(t, err)
+			return allowed
+		}
+		assert.True(t, isWhitelisted(owner))
+		assert.True(t, isWhitelisted(reader))
+		assert.False(t, isWhitelisted(stranger))
+	})
+}
+
+// gasCounter sums the gas a service uses
+type gasCounter struct {
+	context.ContextRef
+	used uint64
+}
+
+func (this *gasCounter) CheckUseGas(gas uint64) bool {
+	this.used += gas
+	return true
+}
@@ -89,6 +89,12 @@ var (
 		RUNTIME_BASE58TOADDRESS_NAME:     {Execute: RuntimeBase58ToAddress},
 		RUNTIME_ADDRESSTOBASE58_NAME:     {Execute: RuntimeAddressToBase58},
 		RUNTIME_GETCURRENTBLOCKHASH_NAME: {Execute: RuntimeGetCurrentBlockHash},
+
+		FILESTORE_GETFILEINFO_NAME:   {Execute: FileStoreGetFileInfo},
+		FILESTORE_ISFILEVALID_NAME:   {Execute: FileStoreIsFileValid},
+		FILESTORE_GETOWNER_NAME:      {Execute: FileStoreGetOwner},
+		FILESTORE_GETPDPSTATUS_NAME:  {Execute: FileStoreGetPdpStatus},
+		FILESTORE_ISWHITELISTED_NAME: {Execute: FileStoreIsWhitelisted},
 	}
 )
 